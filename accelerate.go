@@ -0,0 +1,223 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAccelerationInterval period of re-probing acceleration endpoints
+	DefaultAccelerationInterval = 5 * time.Minute
+	// DefaultAccelerationProbeSize bytes sent by the throughput probe
+	DefaultAccelerationProbeSize = 256 * 1024
+)
+
+// ErrNoAccelerationEndpoint no candidate acceleration endpoint is reachable
+var ErrNoAccelerationEndpoint = errors.New("lingstorage: no reachable acceleration endpoint")
+
+// AccelerationOptions acceleration endpoint selection options
+type AccelerationOptions struct {
+	Endpoints []string      // candidate endpoints, default fetched from the server
+	Interval  time.Duration // re-probe period, default 5m, negative probes once
+	ProbeSize int           // bytes of the throughput probe, default 256KB, negative measures latency only
+}
+
+// AccelerationEndpoint probe result of one candidate endpoint
+type AccelerationEndpoint struct {
+	URL        string
+	Latency    time.Duration
+	Throughput float64 // upload bytes per second, 0 when the endpoint has no probe API
+	Err        error
+}
+
+// accelerator pinned acceleration endpoint of uploads, shared by client copies
+type accelerator struct {
+	mu      sync.Mutex
+	current string
+	results []AccelerationEndpoint
+	cancel  context.CancelFunc
+}
+
+// pinned endpoint of req, only uploads are accelerated
+func (a *accelerator) pinned(req *http.Request) string {
+	if a == nil {
+		return ""
+	}
+	op := operationFromContext(req.Context())
+	if op == nil || op.name != "Upload" {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current
+}
+
+// unpin drop the endpoint after a connection failure
+func (a *accelerator) unpin(endpoint string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == endpoint {
+		a.current = ""
+	}
+}
+
+// EnableAcceleration probe the acceleration endpoints and pin uploads to the
+// fastest one, then keep re-probing in the background until DisableAcceleration
+func (c *Client) EnableAcceleration(options ...func(o *AccelerationOptions)) error {
+	opts := AccelerationOptions{Interval: DefaultAccelerationInterval, ProbeSize: DefaultAccelerationProbeSize}
+	for _, option := range options {
+		option(&opts)
+	}
+	candidates := opts.Endpoints
+	if len(candidates) == 0 {
+		var err error
+		if candidates, err = c.GetAccelerationEndpoints(); err != nil {
+			return err
+		}
+	}
+	if len(candidates) == 0 {
+		return ErrNoAccelerationEndpoint
+	}
+
+	c.DisableAcceleration()
+	if err := c.selectAcceleration(candidates, opts.ProbeSize); err != nil {
+		return err
+	}
+	if opts.Interval < 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.accel.mu.Lock()
+	c.accel.cancel = cancel
+	c.accel.mu.Unlock()
+	prober := c.WithContext(ctx)
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// keep the previous endpoint when none answers
+				prober.selectAcceleration(candidates, opts.ProbeSize)
+			}
+		}
+	}()
+	return nil
+}
+
+// DisableAcceleration stop re-probing and send uploads to the regular endpoints again
+func (c *Client) DisableAcceleration() {
+	c.accel.mu.Lock()
+	defer c.accel.mu.Unlock()
+	if c.accel.cancel != nil {
+		c.accel.cancel()
+		c.accel.cancel = nil
+	}
+	c.accel.current = ""
+}
+
+// AccelerationEndpoint endpoint uploads are pinned to, empty when acceleration is off
+func (c *Client) AccelerationEndpoint() string {
+	c.accel.mu.Lock()
+	defer c.accel.mu.Unlock()
+	return c.accel.current
+}
+
+// AccelerationResults results of the last acceleration probe
+func (c *Client) AccelerationResults() []AccelerationEndpoint {
+	c.accel.mu.Lock()
+	defer c.accel.mu.Unlock()
+	return append([]AccelerationEndpoint(nil), c.accel.results...)
+}
+
+// GetAccelerationEndpoints acceleration endpoints offered by the server
+func (c *Client) GetAccelerationEndpoints() (_ []string, err error) {
+	ctx, op := c.startOperation("GetAccelerationEndpoints", "", "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Endpoints []string `json:"endpoints"`
+	}
+	if err := c.call(ctx, "GET", "/api/public/acceleration/endpoints", nil, &data); err != nil {
+		return nil, fmt.Errorf("get acceleration endpoints %w", err)
+	}
+	return data.Endpoints, nil
+}
+
+// selectAcceleration probe every candidate and pin the fastest. Measured
+// throughput wins over latency, endpoints failing the probe are skipped
+func (c *Client) selectAcceleration(candidates []string, probeSize int) error {
+	results := make([]AccelerationEndpoint, len(candidates))
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func(i int, base string) {
+			defer wg.Done()
+			results[i] = c.probeAcceleration(base, probeSize)
+		}(i, strings.TrimRight(candidate, "/"))
+	}
+	wg.Wait()
+
+	var best *AccelerationEndpoint
+	for i := range results {
+		r := &results[i]
+		if r.Err != nil {
+			continue
+		}
+		switch {
+		case best == nil:
+			best = r
+		case r.Throughput > 0 || best.Throughput > 0:
+			if r.Throughput > best.Throughput {
+				best = r
+			}
+		case r.Latency < best.Latency:
+			best = r
+		}
+	}
+
+	c.accel.mu.Lock()
+	defer c.accel.mu.Unlock()
+	c.accel.results = results
+	if best == nil {
+		return ErrNoAccelerationEndpoint
+	}
+	c.accel.current = best.URL
+	return nil
+}
+
+// probeAcceleration measure latency of base, and upload throughput when the
+// endpoint serves the probe API
+func (c *Client) probeAcceleration(base string, probeSize int) AccelerationEndpoint {
+	result := AccelerationEndpoint{URL: base}
+	if result.Latency, result.Err = c.probe(base); result.Err != nil || probeSize <= 0 {
+		return result
+	}
+
+	payload := make([]byte, probeSize)
+	req, err := http.NewRequestWithContext(c.context(), "POST", base+"/api/public/acceleration/probe", bytes.NewReader(payload))
+	if err != nil {
+		return result
+	}
+	if err := c.setHeaders(req, payload); err != nil {
+		return result
+	}
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return result
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); resp.StatusCode < 400 && elapsed > 0 {
+		result.Throughput = float64(probeSize) / elapsed.Seconds()
+	}
+	return result
+}
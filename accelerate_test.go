@@ -0,0 +1,87 @@
+package lingstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accelerationServer 模拟加速节点，delay 控制探测与上传耗时
+func accelerationServer(uploads *int32, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		switch r.URL.Path {
+		case "/api/public/upload":
+			atomic.AddInt32(uploads, 1)
+			w.Write([]byte(`{"success":true,"data":{"key":"k"}}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func TestEnableAcceleration(t *testing.T) {
+	var primaryUploads, slowUploads, fastUploads int32
+	primary := accelerationServer(&primaryUploads, 0)
+	defer primary.Close()
+	slow := accelerationServer(&slowUploads, 50*time.Millisecond)
+	defer slow.Close()
+	fast := accelerationServer(&fastUploads, 0)
+	defer fast.Close()
+
+	client := NewClient(&Config{BaseURL: primary.URL, APIKey: "test-key", RetryCount: -1})
+	require.NoError(t, client.EnableAcceleration(func(o *AccelerationOptions) {
+		o.Endpoints = []string{slow.URL, fast.URL}
+	}))
+	defer client.DisableAcceleration()
+	assert.Equal(t, fast.URL, client.AccelerationEndpoint())
+
+	results := client.AccelerationResults()
+	require.Len(t, results, 2)
+	assert.Greater(t, results[1].Throughput, results[0].Throughput)
+
+	_, err := client.UploadBytes(&UploadBytesRequest{Bucket: "bkt", Key: "k", Filename: "k.txt", Data: []byte("hello")})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, fastUploads)
+	assert.EqualValues(t, 0, primaryUploads)
+
+	// 非上传请求不经过加速节点
+	require.NoError(t, client.DeleteFile("bkt", "k"))
+
+	// 加速节点不可达时回退到主节点
+	fast.Close()
+	_, err = client.UploadBytes(&UploadBytesRequest{Bucket: "bkt", Key: "k", Filename: "k.txt", Data: []byte("hello")})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primaryUploads)
+	assert.Empty(t, client.AccelerationEndpoint())
+}
+
+func TestEnableAccelerationFromServer(t *testing.T) {
+	var uploads int32
+	accel := accelerationServer(&uploads, 0)
+	defer accel.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/acceleration/endpoints", r.URL.Path)
+		w.Write([]byte(`{"success":true,"data":{"endpoints":["` + accel.URL + `"]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	require.NoError(t, client.EnableAcceleration(func(o *AccelerationOptions) { o.Interval = -1 }))
+	assert.Equal(t, accel.URL, client.AccelerationEndpoint())
+}
+
+func TestEnableAccelerationUnreachable(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	client := NewClient(&Config{BaseURL: "https://example.com", RetryCount: -1})
+	err := client.EnableAcceleration(func(o *AccelerationOptions) { o.Endpoints = []string{down.URL} })
+	assert.ErrorIs(t, err, ErrNoAccelerationEndpoint)
+	assert.Empty(t, client.AccelerationEndpoint())
+}
@@ -0,0 +1,53 @@
+package lingstorage
+
+import "fmt"
+
+// ObjectACL access control of a single object, overriding the bucket privacy
+type ObjectACL string
+
+const (
+	// ACLPrivate only the bucket owner's credentials can read the object
+	ACLPrivate ObjectACL = "private"
+	// ACLPublicRead anyone can read the object, also in a private bucket
+	ACLPublicRead ObjectACL = "public-read"
+	// ACLAuthenticatedRead any authenticated user can read the object
+	ACLAuthenticatedRead ObjectACL = "authenticated-read"
+)
+
+// Valid whether acl is one of the known ACLs
+func (acl ObjectACL) Valid() bool {
+	switch acl {
+	case ACLPrivate, ACLPublicRead, ACLAuthenticatedRead:
+		return true
+	}
+	return false
+}
+
+// SetObjectACL 设置单个对象的访问权限
+func (c *Client) SetObjectACL(bucket, key string, acl ObjectACL) (err error) {
+	ctx, op := c.startOperation("SetObjectACL", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if !acl.Valid() {
+		return fmt.Errorf("unsupported object ACL %q", acl)
+	}
+	if err := c.requireFeature(FeatureACL); err != nil {
+		return err
+	}
+	return c.call(ctx, "PUT", objectPath(bucket, key)+"/acl", map[string]ObjectACL{"acl": acl}, nil)
+}
+
+// GetObjectACL 获取单个对象的访问权限
+func (c *Client) GetObjectACL(bucket, key string) (_ ObjectACL, err error) {
+	ctx, op := c.startOperation("GetObjectACL", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if err := c.requireFeature(FeatureACL); err != nil {
+		return "", err
+	}
+	var data struct {
+		ACL ObjectACL `json:"acl"`
+	}
+	if err := c.call(ctx, "GET", objectPath(bucket, key)+"/acl", nil, &data); err != nil {
+		return "", err
+	}
+	return data.ACL, nil
+}
@@ -0,0 +1,62 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectACL(t *testing.T) {
+	acls := map[string]ObjectACL{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"features": []string{FeatureACL}}})
+		case "/api/public/files/private-bkt/logo.png/acl":
+			if r.Method == "PUT" {
+				var body map[string]ObjectACL
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				acls["logo.png"] = body["acl"]
+				w.Write([]byte(`{"success":true}`))
+				return
+			}
+			acl, ok := acls["logo.png"]
+			if !ok {
+				acl = ACLPrivate
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"acl": acl}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	acl, err := client.GetObjectACL("private-bkt", "logo.png")
+	require.NoError(t, err)
+	assert.Equal(t, ACLPrivate, acl)
+
+	require.NoError(t, client.SetObjectACL("private-bkt", "logo.png", ACLPublicRead))
+	acl, err = client.GetObjectACL("private-bkt", "logo.png")
+	require.NoError(t, err)
+	assert.Equal(t, ACLPublicRead, acl)
+
+	assert.Error(t, client.SetObjectACL("private-bkt", "logo.png", "public-write"))
+}
+
+func TestObjectACLNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/public/info", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"features": []string{FeatureTags}}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	assert.ErrorIs(t, client.SetObjectACL("bkt", "a.txt", ACLPublicRead), ErrNotSupported)
+	_, err := client.GetObjectACL("bkt", "a.txt")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
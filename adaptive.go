@@ -0,0 +1,142 @@
+package lingstorage
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAdaptiveMinPartSize smallest part size the adaptive mode picks
+	DefaultAdaptiveMinPartSize = 1024 * 1024
+	// DefaultAdaptiveMaxPartSize largest part size the adaptive mode picks
+	DefaultAdaptiveMaxPartSize = 64 * 1024 * 1024
+	// DefaultAdaptiveMaxConcurrency most parallel part requests the adaptive mode runs
+	DefaultAdaptiveMaxConcurrency = 16
+
+	// adaptiveThreshold relative throughput change treated as a real change
+	adaptiveThreshold = 0.05
+)
+
+// partTuner source of the part size and concurrency of a transfer
+type partTuner interface {
+	settings() (partSize int64, concurrency int)
+	// record account a completed part of n bytes
+	record(n int64)
+}
+
+// fixedTuner settings that never change
+type fixedTuner struct {
+	partSize    int64
+	concurrency int
+}
+
+func (t fixedTuner) settings() (int64, int) { return t.partSize, t.concurrency }
+func (t fixedTuner) record(int64)           {}
+
+// adaptiveTuner hill climbing tuner of part size and concurrency. Each window
+// of completed parts adjusts one of the two, alternating; when the aggregate
+// throughput drops the last adjustment is stepped back and its direction reversed
+type adaptiveTuner struct {
+	mu                  sync.Mutex
+	partSize            int64
+	minPart, maxPart    int64
+	concurrency, maxCon int
+	onTune              func(partSize int64, concurrency int)
+
+	windowStart time.Time
+	windowBytes int64
+	windowParts int
+	lastRate    float64 // bytes per second of the previous window
+
+	tuneSize      bool // the next window adjusts the part size, otherwise concurrency
+	sizeUp, conUp bool
+}
+
+func newAdaptiveTuner(partSize, minPart, maxPart int64, concurrency, maxCon int, onTune func(int64, int)) *adaptiveTuner {
+	t := &adaptiveTuner{
+		minPart:     minPart,
+		maxPart:     maxPart,
+		maxCon:      maxCon,
+		onTune:      onTune,
+		windowStart: time.Now(),
+		tuneSize:    true,
+		sizeUp:      true,
+		conUp:       true,
+	}
+	t.partSize = clampInt64(partSize, minPart, maxPart)
+	t.concurrency = int(clampInt64(int64(concurrency), 1, int64(maxCon)))
+	return t
+}
+
+// settings part size of the next part and the allowed parallel parts
+func (t *adaptiveTuner) settings() (int64, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.partSize, t.concurrency
+}
+
+// record account a completed part, adjusting the settings once a window is full
+func (t *adaptiveTuner) record(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.windowBytes += n
+	t.windowParts++
+	if t.windowParts < t.concurrency || t.windowParts < 2 {
+		return
+	}
+	elapsed := time.Since(t.windowStart).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	rate := float64(t.windowBytes) / elapsed
+	if t.lastRate > 0 && rate < t.lastRate*(1-adaptiveThreshold) {
+		// the last adjustment hurt: step it back and head the other way next time
+		if t.tuneSize {
+			t.conUp = !t.conUp
+			t.stepConcurrency()
+		} else {
+			t.sizeUp = !t.sizeUp
+			t.stepPartSize()
+		}
+	} else {
+		if t.tuneSize {
+			t.stepPartSize()
+		} else {
+			t.stepConcurrency()
+		}
+		t.tuneSize = !t.tuneSize
+	}
+	t.lastRate = rate
+	t.windowStart = time.Now()
+	t.windowBytes = 0
+	t.windowParts = 0
+	if t.onTune != nil {
+		t.onTune(t.partSize, t.concurrency)
+	}
+}
+
+func (t *adaptiveTuner) stepPartSize() {
+	if t.sizeUp {
+		t.partSize = clampInt64(t.partSize*2, t.minPart, t.maxPart)
+	} else {
+		t.partSize = clampInt64(t.partSize/2, t.minPart, t.maxPart)
+	}
+}
+
+func (t *adaptiveTuner) stepConcurrency() {
+	step := -1
+	if t.conUp {
+		step = 1
+	}
+	t.concurrency = int(clampInt64(int64(t.concurrency+step), 1, int64(t.maxCon)))
+}
+
+func clampInt64(v, min, max int64) int64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
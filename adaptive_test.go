@@ -0,0 +1,63 @@
+package lingstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// window 模拟一个耗时 elapsed 的窗口内完成 parts 个分片
+func window(tuner *adaptiveTuner, parts int, bytes int64, elapsed time.Duration) {
+	tuner.windowStart = time.Now().Add(-elapsed)
+	for i := 0; i < parts; i++ {
+		tuner.record(bytes)
+	}
+}
+
+func TestAdaptiveTuner(t *testing.T) {
+	tuner := newAdaptiveTuner(4<<20, 1<<20, 16<<20, 2, 4, nil)
+
+	// 第一个窗口: 增大分片
+	window(tuner, 2, 4<<20, time.Second)
+	size, con := tuner.settings()
+	assert.EqualValues(t, 8<<20, size)
+	assert.Equal(t, 2, con)
+
+	// 吞吐提升: 继续调整并发
+	window(tuner, 2, 8<<20, time.Second)
+	size, con = tuner.settings()
+	assert.EqualValues(t, 8<<20, size)
+	assert.Equal(t, 3, con)
+
+	window(tuner, 3, 8<<20, time.Second)
+	size, _ = tuner.settings()
+	assert.EqualValues(t, 16<<20, size)
+
+	// 吞吐下降: 撤回上一次的分片调整，之后继续调整并发
+	window(tuner, 3, 1<<20, time.Second)
+	size, con = tuner.settings()
+	assert.EqualValues(t, 8<<20, size)
+	assert.Equal(t, 3, con)
+	window(tuner, 3, 8<<20, time.Second)
+	_, con = tuner.settings()
+	assert.Equal(t, 4, con)
+}
+
+func TestAdaptiveTunerBounds(t *testing.T) {
+	var tunes int
+	tuner := newAdaptiveTuner(64<<20, 1<<20, 8<<20, 10, 3, func(int64, int) { tunes++ })
+	size, con := tuner.settings()
+	assert.EqualValues(t, 8<<20, size)
+	assert.Equal(t, 3, con)
+
+	for i := 0; i < 10; i++ {
+		window(tuner, 3, 1<<20, time.Second)
+		size, con = tuner.settings()
+		assert.GreaterOrEqual(t, size, int64(1<<20))
+		assert.LessOrEqual(t, size, int64(8<<20))
+		assert.GreaterOrEqual(t, con, 1)
+		assert.LessOrEqual(t, con, 3)
+	}
+	assert.Equal(t, 10, tunes)
+}
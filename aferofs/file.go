@@ -0,0 +1,325 @@
+package aferofs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/spf13/afero"
+)
+
+// File open file or directory of an Fs
+type File struct {
+	fs   *Fs
+	name string
+	key  string
+	flag int
+	info *fileInfo
+
+	mu     sync.Mutex
+	data   []byte
+	loaded bool
+	dirty  bool
+	offset int64
+	closed bool
+
+	entries   []os.FileInfo
+	listed    bool
+	dirOffset int
+}
+
+var _ afero.File = (*File)(nil)
+
+// Name name the file was opened with
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) pathError(op string, err error) error {
+	return &os.PathError{Op: op, Path: f.name, Err: err}
+}
+
+// check file is open and not a directory, caller holds mu
+func (f *File) check(op string) error {
+	if f.closed {
+		return f.pathError(op, os.ErrClosed)
+	}
+	if f.info.IsDir() {
+		return f.pathError(op, errors.New("is a directory"))
+	}
+	return nil
+}
+
+// load download the content once, caller holds mu or has exclusive access
+func (f *File) load() error {
+	if f.loaded {
+		return nil
+	}
+	result, err := f.fs.client.Download(&lingstorage.DownloadRequest{Bucket: f.fs.bucket, Key: f.key})
+	if err != nil {
+		return f.pathError("read", err)
+	}
+	defer result.Body.Close()
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return f.pathError("read", err)
+	}
+	f.data = data
+	f.loaded = true
+	return nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.readAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.readAt(p, off)
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (f *File) readAt(p []byte, off int64) (int, error) {
+	if err := f.check("read"); err != nil {
+		return 0, err
+	}
+	if f.flag&os.O_WRONLY != 0 {
+		return 0, f.pathError("read", errors.New("file opened for writing only"))
+	}
+	if err := f.load(); err != nil {
+		return 0, err
+	}
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	return copy(p, f.data[off:]), nil
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("seek"); err != nil {
+		return 0, err
+	}
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = f.offset
+	case io.SeekEnd:
+		if err := f.load(); err != nil {
+			return 0, err
+		}
+		base = int64(len(f.data))
+	default:
+		return 0, f.pathError("seek", errors.New("invalid whence"))
+	}
+	if base+offset < 0 {
+		return 0, f.pathError("seek", errors.New("negative position"))
+	}
+	f.offset = base + offset
+	return f.offset, nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.flag&os.O_APPEND != 0 {
+		f.offset = int64(len(f.data))
+	}
+	n, err := f.writeAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.flag&os.O_APPEND != 0 {
+		return 0, f.pathError("writeat", errors.New("invalid use of WriteAt on file opened with O_APPEND"))
+	}
+	return f.writeAt(p, off)
+}
+
+func (f *File) writeAt(p []byte, off int64) (int, error) {
+	if err := f.check("write"); err != nil {
+		return 0, err
+	}
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, f.pathError("write", errors.New("file opened for reading only"))
+	}
+	if end := off + int64(len(p)); end > int64(len(f.data)) {
+		f.data = append(f.data, make([]byte, end-int64(len(f.data)))...)
+	}
+	copy(f.data[off:], p)
+	f.dirty = true
+	return len(p), nil
+}
+
+func (f *File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *File) Truncate(size int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("truncate"); err != nil {
+		return err
+	}
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return f.pathError("truncate", errors.New("file opened for reading only"))
+	}
+	if size < 0 {
+		return f.pathError("truncate", errors.New("negative size"))
+	}
+	if size <= int64(len(f.data)) {
+		f.data = f.data[:size]
+	} else {
+		f.data = append(f.data, make([]byte, size-int64(len(f.data)))...)
+	}
+	f.dirty = true
+	return nil
+}
+
+// Sync upload buffered writes
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return f.pathError("sync", os.ErrClosed)
+	}
+	return f.flush()
+}
+
+// flush upload the buffer if it changed, caller holds mu
+func (f *File) flush() error {
+	if !f.dirty {
+		return nil
+	}
+	_, err := f.fs.client.UploadBytes(&lingstorage.UploadBytesRequest{
+		Data:     f.data,
+		Filename: path.Base(f.key),
+		Bucket:   f.fs.bucket,
+		Key:      f.key,
+	})
+	if err != nil {
+		return f.pathError("sync", err)
+	}
+	f.dirty = false
+	f.info = &fileInfo{name: f.info.name, size: int64(len(f.data)), mode: f.info.mode, modTime: time.Now()}
+	return nil
+}
+
+// Close upload buffered writes and release the content
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return f.pathError("close", os.ErrClosed)
+	}
+	f.closed = true
+	err := f.flush()
+	f.data = nil
+	return err
+}
+
+// Stat file info, the size includes unsynced writes
+func (f *File) Stat() (os.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, f.pathError("stat", os.ErrClosed)
+	}
+	info := *f.info
+	if f.loaded {
+		info.size = int64(len(f.data))
+	}
+	return &info, nil
+}
+
+// Readdir entries of a directory in name order, count <= 0 returns all remaining
+func (f *File) Readdir(count int) ([]os.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, f.pathError("readdir", os.ErrClosed)
+	}
+	if !f.info.IsDir() {
+		return nil, f.pathError("readdir", errors.New("not a directory"))
+	}
+	if !f.listed {
+		entries, err := f.list()
+		if err != nil {
+			return nil, f.pathError("readdir", err)
+		}
+		f.entries = entries
+		f.listed = true
+	}
+	rest := f.entries[f.dirOffset:]
+	if count <= 0 {
+		f.dirOffset = len(f.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	f.dirOffset += count
+	return rest[:count], nil
+}
+
+func (f *File) Readdirnames(n int) ([]string, error) {
+	infos, err := f.Readdir(n)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
+
+// list direct children of the directory, the marker object is skipped
+func (f *File) list() ([]os.FileInfo, error) {
+	prefix := ""
+	if f.key != "" {
+		prefix = f.key + "/"
+	}
+	var entries []os.FileInfo
+	req := &lingstorage.ListFilesRequest{Bucket: f.fs.bucket, Prefix: prefix, Delimiter: "/"}
+	for {
+		result, err := f.fs.client.ListFiles(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range result.Directories {
+			entries = append(entries, &fileInfo{name: path.Base(strings.TrimSuffix(dir, "/")), mode: os.ModeDir | 0755})
+		}
+		for _, file := range result.Files {
+			if file.Key == prefix {
+				continue
+			}
+			entries = append(entries, &fileInfo{name: path.Base(file.Key), size: file.Size, mode: 0644, modTime: file.LastModified})
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			break
+		}
+		req.Marker = result.NextMarker
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
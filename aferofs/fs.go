@@ -0,0 +1,325 @@
+// Package aferofs implements afero.Fs over a LingStorage bucket, so
+// applications using afero can store files in a bucket by swapping the
+// filesystem.
+//
+//	var appFs afero.Fs = aferofs.New(client, "assets")
+//	afero.WriteFile(appFs, "/css/site.css", data, 0644)
+//
+// Object stores have no partial writes: a file opened for writing is buffered
+// in memory and uploaded on Sync or Close, a file opened for reading is
+// downloaded on the first read. Directories are key prefixes, Mkdir stores a
+// "dir/" marker object so empty directories exist. Permissions, owners and
+// times are not stored, Chmod, Chown and Chtimes return errors.ErrUnsupported.
+package aferofs
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/spf13/afero"
+)
+
+// Fs afero.Fs backed by a bucket
+type Fs struct {
+	client *lingstorage.Client
+	bucket string
+}
+
+var _ afero.Fs = (*Fs)(nil)
+
+// New filesystem over bucket, paths map to object keys without the leading slash
+func New(client *lingstorage.Client, bucket string) *Fs {
+	return &Fs{client: client, bucket: bucket}
+}
+
+// Name name of the filesystem
+func (fs *Fs) Name() string {
+	return "LingStorageFs"
+}
+
+// keyOf object key of a path, "" is the root
+func keyOf(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// Create create or truncate a file for writing
+func (fs *Fs) Create(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+// Open open a file or directory for reading
+func (fs *Fs) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile open a file, O_RDWR and O_APPEND download the current content first
+func (fs *Fs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	key := keyOf(name)
+	info, err := fs.stat(key)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+
+	switch {
+	case exists && info.IsDir():
+		if writable {
+			return nil, &os.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+		}
+		return &File{fs: fs, name: name, key: key, info: info}, nil
+	case exists && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	case !exists && (!writable || flag&os.O_CREATE == 0):
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	f := &File{fs: fs, name: name, key: key, flag: flag, info: info}
+	if !exists {
+		f.info = &fileInfo{name: path.Base(key), mode: 0644, modTime: time.Now()}
+		f.loaded = true
+		f.dirty = true
+	} else if flag&os.O_TRUNC != 0 {
+		f.loaded = true
+		f.dirty = true
+	} else if writable {
+		// keep the current content for in place edits and appends
+		if err := f.load(); err != nil {
+			return nil, err
+		}
+	}
+	if flag&os.O_APPEND != 0 {
+		f.offset = int64(len(f.data))
+	}
+	return f, nil
+}
+
+// Stat file info of a file or directory
+func (fs *Fs) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.stat(keyOf(name))
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+// stat object first, then directory marker or objects below the prefix
+func (fs *Fs) stat(key string) (*fileInfo, error) {
+	if key == "" {
+		return &fileInfo{name: "/", mode: os.ModeDir | 0755}, nil
+	}
+	info, err := fs.client.GetFileInfo(fs.bucket, key)
+	if err == nil {
+		return &fileInfo{name: path.Base(key), size: info.Size, mode: 0644, modTime: info.LastModified}, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	result, err := fs.client.ListFiles(&lingstorage.ListFilesRequest{Bucket: fs.bucket, Prefix: key + "/", Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Files) == 0 && len(result.Directories) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return &fileInfo{name: path.Base(key), mode: os.ModeDir | 0755}, nil
+}
+
+// Mkdir create a directory marker, the parent must exist
+func (fs *Fs) Mkdir(name string, perm os.FileMode) error {
+	key := keyOf(name)
+	if key == "" {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	if _, err := fs.stat(key); err == nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	if parent := path.Dir(key); parent != "." {
+		info, err := fs.stat(parent)
+		if err != nil {
+			return &os.PathError{Op: "mkdir", Path: name, Err: err}
+		}
+		if !info.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+	}
+	return fs.mkdir(name, key)
+}
+
+// MkdirAll create a directory marker and its missing parents
+func (fs *Fs) MkdirAll(name string, perm os.FileMode) error {
+	key := keyOf(name)
+	if key == "" {
+		return nil
+	}
+	if info, err := fs.stat(key); err == nil {
+		if !info.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if parent := path.Dir(key); parent != "." {
+		if err := fs.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	return fs.mkdir(name, key)
+}
+
+func (fs *Fs) mkdir(name, key string) error {
+	_, err := fs.client.UploadBytes(&lingstorage.UploadBytesRequest{
+		Data:     []byte{},
+		Filename: path.Base(key),
+		Bucket:   fs.bucket,
+		Key:      key + "/",
+	})
+	if err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+// Remove delete a file or an empty directory
+func (fs *Fs) Remove(name string) error {
+	key := keyOf(name)
+	info, err := fs.stat(key)
+	if err != nil {
+		return &os.PathError{Op: "remove", Path: name, Err: err}
+	}
+	if !info.IsDir() {
+		if err := fs.client.DeleteFile(fs.bucket, key); err != nil {
+			return &os.PathError{Op: "remove", Path: name, Err: err}
+		}
+		return nil
+	}
+	keys, err := fs.keysBelow(key)
+	if err != nil {
+		return &os.PathError{Op: "remove", Path: name, Err: err}
+	}
+	for _, k := range keys {
+		if k != key+"/" {
+			return &os.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+		}
+	}
+	if err := fs.client.DeleteFile(fs.bucket, key+"/"); err != nil && !isNotFound(err) {
+		return &os.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return nil
+}
+
+// RemoveAll delete a path and everything below it, a missing path is fine
+func (fs *Fs) RemoveAll(name string) error {
+	key := keyOf(name)
+	keys, err := fs.keysBelow(key)
+	if err != nil {
+		return &os.PathError{Op: "removeall", Path: name, Err: err}
+	}
+	if key != "" {
+		keys = append(keys, key)
+	}
+	for _, k := range keys {
+		if err := fs.client.DeleteFile(fs.bucket, k); err != nil && !isNotFound(err) {
+			return &os.PathError{Op: "removeall", Path: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// Rename move a file, or every object below a directory
+func (fs *Fs) Rename(oldname, newname string) error {
+	oldKey, newKey := keyOf(oldname), keyOf(newname)
+	info, err := fs.stat(oldKey)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if !info.IsDir() {
+		if err := fs.move(oldKey, newKey); err != nil {
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+		}
+		return nil
+	}
+	if oldKey == "" || strings.HasPrefix(newKey+"/", oldKey+"/") {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errors.New("invalid argument")}
+	}
+	keys, err := fs.keysBelow(oldKey)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	for _, k := range keys {
+		if err := fs.move(k, newKey+strings.TrimPrefix(k, oldKey)); err != nil {
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+		}
+	}
+	return nil
+}
+
+func (fs *Fs) move(src, dst string) error {
+	return fs.client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: fs.bucket, SrcKey: src, DestBucket: fs.bucket, DestKey: dst})
+}
+
+// keysBelow every object key below a directory, the root lists the whole bucket
+func (fs *Fs) keysBelow(key string) ([]string, error) {
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+	var keys []string
+	req := &lingstorage.ListFilesRequest{Bucket: fs.bucket, Prefix: prefix}
+	for {
+		result, err := fs.client.ListFiles(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range result.Files {
+			keys = append(keys, f.Key)
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			return keys, nil
+		}
+		req.Marker = result.NextMarker
+	}
+}
+
+// Chmod not supported, objects have no permissions
+func (fs *Fs) Chmod(name string, mode os.FileMode) error {
+	return &os.PathError{Op: "chmod", Path: name, Err: errors.ErrUnsupported}
+}
+
+// Chown not supported, objects have no owners
+func (fs *Fs) Chown(name string, uid, gid int) error {
+	return &os.PathError{Op: "chown", Path: name, Err: errors.ErrUnsupported}
+}
+
+// Chtimes not supported, modification times are set by the server
+func (fs *Fs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return &os.PathError{Op: "chtimes", Path: name, Err: errors.ErrUnsupported}
+}
+
+func isNotFound(err error) bool {
+	var apiErr *lingstorage.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// fileInfo os.FileInfo of an object or prefix
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *fileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,117 @@
+package aferofs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFs(t *testing.T) (*Fs, *lingstoragetest.FakeServer) {
+	server := lingstoragetest.NewFakeServer()
+	t.Cleanup(server.Close)
+	return New(server.Client(), lingstoragetest.DefaultBucket), server
+}
+
+func TestReadWriteFile(t *testing.T) {
+	fs, server := newTestFs(t)
+
+	require.NoError(t, afero.WriteFile(fs, "/css/site.css", []byte("body{}"), 0644))
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "css/site.css")
+	require.True(t, ok)
+	assert.Equal(t, "body{}", string(obj.Data))
+
+	data, err := afero.ReadFile(fs, "css/site.css")
+	require.NoError(t, err)
+	assert.Equal(t, "body{}", string(data))
+
+	info, err := fs.Stat("/css/site.css")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), info.Size())
+	assert.False(t, info.IsDir())
+	info, err = fs.Stat("/css")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	_, err = fs.Open("/missing.txt")
+	assert.True(t, os.IsNotExist(err))
+	_, err = fs.OpenFile("/css/site.css", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	assert.True(t, os.IsExist(err))
+
+	// 追加写入需要先读取原内容
+	f, err := fs.OpenFile("/css/site.css", os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("a{}")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	data, _ = afero.ReadFile(fs, "/css/site.css")
+	assert.Equal(t, "body{}a{}", string(data))
+
+	// 随机读取与定位
+	f, err = fs.Open("/css/site.css")
+	require.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = f.ReadAt(buf, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "a{}", string(buf))
+	pos, err := f.Seek(-3, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), pos)
+	_, err = f.Write([]byte("x"))
+	assert.Error(t, err)
+	require.NoError(t, f.Close())
+	assert.True(t, errors.Is(f.Close(), os.ErrClosed))
+}
+
+func TestDirectories(t *testing.T) {
+	fs, server := newTestFs(t)
+
+	require.NoError(t, fs.MkdirAll("/a/b/c", 0755))
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "a/b/c/")
+	assert.True(t, ok)
+	exists, err := afero.DirExists(fs, "/a/b")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.True(t, os.IsExist(fs.Mkdir("/a", 0755)))
+	assert.Error(t, fs.Mkdir("/x/y", 0755))
+
+	require.NoError(t, afero.WriteFile(fs, "/a/one.txt", []byte("1"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/a/b/two.txt", []byte("22"), 0644))
+
+	infos, err := afero.ReadDir(fs, "/a")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "b", infos[0].Name())
+	assert.True(t, infos[0].IsDir())
+	assert.Equal(t, "one.txt", infos[1].Name())
+
+	var walked []string
+	require.NoError(t, afero.Walk(fs, "/a", func(p string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		walked = append(walked, p)
+		return nil
+	}))
+	assert.Equal(t, []string{"/a", "/a/b", "/a/b/c", "/a/b/two.txt", "/a/one.txt"}, walked)
+
+	assert.Error(t, fs.Remove("/a"), "directory not empty")
+	require.NoError(t, fs.Remove("/a/b/c"))
+
+	require.NoError(t, fs.Rename("/a", "/z"))
+	data, err := afero.ReadFile(fs, "/z/b/two.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "22", string(data))
+	_, err = fs.Stat("/a")
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, fs.RemoveAll("/z"))
+	_, err = fs.Stat("/z")
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, fs.RemoveAll("/never-existed"))
+
+	assert.True(t, errors.Is(fs.Chmod("/z", 0600), errors.ErrUnsupported))
+}
@@ -0,0 +1,40 @@
+package lingstorage
+
+import (
+	"fmt"
+)
+
+// AudioTranscodeSpec audio transcode target
+type AudioTranscodeSpec struct {
+	Format     string `json:"format"`               // mp3, aac, opus, flac ...
+	Bitrate    int    `json:"bitrate,omitempty"`    // audio bitrate in kbps, default chosen by the server
+	SampleRate int    `json:"sampleRate,omitempty"` // e.g. 44100, default source sample rate
+	Channels   int    `json:"channels,omitempty"`   // 1 mono, 2 stereo, default source channels
+	// NormalizeLoudness normalize to TargetLUFS (EBU R128), e.g. for podcasts
+	NormalizeLoudness bool    `json:"normalizeLoudness,omitempty"`
+	TargetLUFS        float64 `json:"targetLufs,omitempty"` // default -16
+	OutputKey         string  `json:"outputKey,omitempty"`  // output key, default chosen by the server
+}
+
+// TranscodeAudio start a server side audio transcode, poll it with WaitForTranscodeJob
+func (c *Client) TranscodeAudio(bucket, key string, spec *AudioTranscodeSpec) (_ *TranscodeJob, err error) {
+	ctx, op := c.startOperation("TranscodeAudio", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if spec == nil {
+		return nil, fmt.Errorf("audio transcode spec is required")
+	}
+	if spec.Format == "" {
+		return nil, fmt.Errorf("transcode format is required")
+	}
+	if spec.Bitrate < 0 || spec.SampleRate < 0 || spec.Channels < 0 {
+		return nil, fmt.Errorf("invalid audio transcode spec")
+	}
+	if spec.TargetLUFS > 0 {
+		return nil, fmt.Errorf("target loudness must be negative LUFS, got %g", spec.TargetLUFS)
+	}
+	var result TranscodeJob
+	if err := c.call(ctx, "POST", objectPath(bucket, key)+"/transcode-audio", spec, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
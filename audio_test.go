@@ -0,0 +1,45 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscodeAudio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/podcasts/ep1.wav/transcode-audio":
+			var spec AudioTranscodeSpec
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&spec))
+			assert.Equal(t, "aac", spec.Format)
+			assert.Equal(t, 128, spec.Bitrate)
+			assert.True(t, spec.NormalizeLoudness)
+			assert.Equal(t, -16.0, spec.TargetLUFS)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1", "status": "pending"}})
+		case "/api/public/jobs/job-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1", "status": "succeeded", "outputKey": "ep1.m4a"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	job, err := client.TranscodeAudio("podcasts", "ep1.wav", &AudioTranscodeSpec{Format: "aac", Bitrate: 128, NormalizeLoudness: true, TargetLUFS: -16})
+	require.NoError(t, err)
+	job, err = client.WaitForTranscodeJob(context.Background(), job.ID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "ep1.m4a", job.OutputKey)
+
+	_, err = client.TranscodeAudio("podcasts", "ep1.wav", &AudioTranscodeSpec{Format: "aac", TargetLUFS: 3})
+	assert.Error(t, err)
+	_, err = client.TranscodeAudio("podcasts", "ep1.wav", nil)
+	assert.ErrorContains(t, err, "audio transcode spec is required")
+}
@@ -0,0 +1,134 @@
+package lingstorage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// setHeaders set common headers and authenticate the request
+func (c *Client) setHeaders(req *http.Request, body []byte) error {
+	if c.initErr != nil {
+		return c.initErr
+	}
+	req.Header.Set(constants.USER_AGENT, c.config.UserAgent)
+	for name, values := range c.headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	c.injectTraceHeaders(req)
+	if c.config.TokenSource != nil {
+		return c.setTokenHeader(req)
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return err
+	}
+	if creds.APIKey != "" {
+		req.Header.Set(constants.XAPIKEY, creds.APIKey)
+	}
+	if c.config.SignRequests {
+		bodyHash := sha256.Sum256(body)
+		signRequest(req, creds.APISecret, bodyHash[:])
+		return nil
+	}
+	if creds.APISecret != "" {
+		req.Header.Set(constants.XAPISECRET, creds.APISecret)
+	}
+	return nil
+}
+
+// signAttempt sign an attempt again with a fresh timestamp and nonce, servers
+// with replay protection reject a retry carrying the signature of the first
+// attempt. Bodies readable only once keep the signature of the template
+func (c *Client) signAttempt(req *http.Request) error {
+	if !c.config.SignRequests || req.Header.Get(constants.XSIGNATURE) == "" {
+		return nil
+	}
+	h := sha256.New()
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return fmt.Errorf("failed to replay request body: %w", err)
+		}
+		_, err = io.Copy(h, body)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to hash request body: %w", err)
+		}
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return err
+	}
+	signRequest(req, creds.APISecret, h.Sum(nil))
+	return nil
+}
+
+// signRequest sign request with timestamp and nonce, the secret never goes on the wire
+func signRequest(req *http.Request, secret string, bodyHash []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := newNonce()
+	req.Header.Set(constants.XTIMESTAMP, timestamp)
+	req.Header.Set(constants.XNONCE, nonce)
+	req.Header.Set(constants.XSIGNATURE, signHash(secret, req.Method, signedPath(req.URL), bodyHash, timestamp, nonce))
+}
+
+// signedPath escaped path of u followed by its canonical query, so query
+// parameters cannot be changed without breaking the signature
+func signedPath(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return u.EscapedPath()
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return u.EscapedPath() + "?" + query.Encode()
+}
+
+// Sign compute the request signature. PATH is the escaped path, followed for
+// requests with a query by '?' and the query sorted by key and value
+//
+// string to sign: METHOD\nPATH\nhex(sha256(body))\nTIMESTAMP\nNONCE
+// signature:      hex(hmac-sha256(secret, string to sign))
+func Sign(secret, method, path string, body []byte, timestamp, nonce string) string {
+	bodyHash := sha256.Sum256(body)
+	return signHash(secret, method, path, bodyHash[:], timestamp, nonce)
+}
+
+func signHash(secret, method, path string, bodyHash []byte, timestamp, nonce string) string {
+	stringToSign := strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		hex.EncodeToString(bodyHash),
+		timestamp,
+		nonce,
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newNonce random 16 bytes hex nonce
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// fall back to time based nonce, rand.Read should never fail
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
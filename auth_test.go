@@ -0,0 +1,102 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 签名模式下不应发送 secret
+		assert.Empty(t, r.Header.Get("X-API-Secret"))
+		assert.Equal(t, "test-key", r.Header.Get("X-API-Key"))
+
+		timestamp := r.Header.Get("X-Timestamp")
+		nonce := r.Header.Get("X-Nonce")
+		assert.NotEmpty(t, timestamp)
+		assert.NotEmpty(t, nonce)
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		expected := Sign("test-secret", r.Method, signedPath(r.URL), body, timestamp, nonce)
+		assert.Equal(t, expected, r.Header.Get("X-Signature"))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL:      server.URL,
+		APIKey:       "test-key",
+		APISecret:    "test-secret",
+		SignRequests: true,
+	})
+
+	require.NoError(t, client.DeleteFile("test-bucket", "test-key"))
+	require.NoError(t, client.CreateBucket(&CreateBucketRequest{BucketName: "signed"}))
+	_, err := client.GetFileURL("test-bucket", "test-key", time.Hour)
+	require.NoError(t, err)
+}
+
+func TestSignedRetryUsesFreshNonce(t *testing.T) {
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, nonce := r.Header.Get("X-Timestamp"), r.Header.Get("X-Nonce")
+		assert.Equal(t, Sign("test-secret", r.Method, signedPath(r.URL), body, timestamp, nonce), r.Header.Get("X-Signature"))
+		assert.Equal(t, `{"isPrivate":true}`, string(body))
+		nonces = append(nonces, nonce)
+		if len(nonces) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL:      server.URL,
+		APIKey:       "test-key",
+		APISecret:    "test-secret",
+		SignRequests: true,
+		RetryCount:   1,
+	})
+	require.NoError(t, client.SetBucketPrivate(&SetBucketPrivateRequest{BucketName: "signed", IsPrivate: true}))
+	// 重试必须重新签名, 否则开启防重放的服务端会拒绝
+	require.Len(t, nonces, 2)
+	assert.NotEqual(t, nonces[0], nonces[1])
+}
+
+func TestSignedPathCoversQuery(t *testing.T) {
+	parse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return u
+	}
+	assert.Equal(t, "/api/public/buckets", signedPath(parse("/api/public/buckets")))
+	// 参数按键和值排序, 与顺序无关
+	assert.Equal(t, "/f/a%23b/url?expires=60&type=a&type=b", signedPath(parse("/f/a%23b/url?type=b&expires=60&type=a")))
+
+	a := Sign("secret", "GET", signedPath(parse("/f/a/url?expires=60")), nil, "1700000000", "abc")
+	assert.NotEqual(t, a, Sign("secret", "GET", signedPath(parse("/f/a/url?expires=999999")), nil, "1700000000", "abc"))
+}
+
+func TestSignDeterministic(t *testing.T) {
+	a := Sign("secret", "get", "/api/public/buckets", nil, "1700000000", "abc")
+	b := Sign("secret", "GET", "/api/public/buckets", nil, "1700000000", "abc")
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, Sign("other", "GET", "/api/public/buckets", nil, "1700000000", "abc"))
+	assert.NotEqual(t, a, Sign("secret", "GET", "/api/public/buckets", nil, "1700000000", "abd"))
+}
@@ -0,0 +1,230 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// BucketIterator iterate over every bucket, fetching pages on demand
+//
+//	it := client.IterateBuckets(&ListBucketsRequest{})
+//	for it.Next() {
+//		fmt.Println(it.Bucket())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type BucketIterator struct {
+	client *Client
+	req    ListBucketsRequest
+	page   []string
+	bucket string
+	done   bool
+	err    error
+}
+
+// IterateBuckets iterator over the buckets matching req, starting after req.Marker
+func (c *Client) IterateBuckets(req *ListBucketsRequest) *BucketIterator {
+	it := &BucketIterator{client: c}
+	if req != nil {
+		it.req = *req
+	}
+	return it
+}
+
+// Next advance to the next bucket, false at the end or on error
+func (it *BucketIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		result, err := it.client.ListBucketsPage(&it.req)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = result.Buckets
+		// a page without a marker or repeating the previous one ends the listing
+		if !result.IsTruncated || result.NextMarker == "" || result.NextMarker == it.req.Marker {
+			it.done = true
+		}
+		it.req.Marker = result.NextMarker
+	}
+	it.bucket, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Bucket current bucket name
+func (it *BucketIterator) Bucket() string {
+	return it.bucket
+}
+
+// Err first error met while listing
+func (it *BucketIterator) Err() error {
+	return it.err
+}
+
+// ForceDeleteBucket 清空并删除存储桶。onProgress 可为 nil, 每删除一页对象后
+// 以累计删除数调用。版本和未完成的分片上传尚无列举接口, 由服务端随存储桶清理
+func (c *Client) ForceDeleteBucket(bucket string, onProgress func(deleted int)) (err error) {
+	ctx, op := c.startOperation("ForceDeleteBucket", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	client := c.WithContext(ctx)
+	deleted := 0
+	for {
+		// deleted keys leave the listing, so every page starts from the beginning
+		page, err := client.ListFiles(&ListFilesRequest{Bucket: bucket})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", bucket, err)
+		}
+		if len(page.Files) == 0 {
+			break
+		}
+		keys := make([]string, len(page.Files))
+		for i, file := range page.Files {
+			keys[i] = file.Key
+		}
+		result, err := c.deleteKeys(ctx, bucket, keys)
+		if err != nil {
+			return fmt.Errorf("failed to empty %s: %w", bucket, err)
+		}
+		deleted += len(result.Deleted)
+		if onProgress != nil && len(result.Deleted) > 0 {
+			onProgress(deleted)
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("failed to empty %s: %d objects not deleted, first %s: %s", bucket, len(result.Failed), result.Failed[0].Key, result.Failed[0].Error)
+		}
+	}
+	return client.DeleteBucket(bucket)
+}
+
+// Permissions operations the configured credentials may perform on a bucket
+type Permissions struct {
+	Read   bool `json:"read"`   // download objects and read their info
+	Write  bool `json:"write"`  // upload, copy and move into the bucket
+	List   bool `json:"list"`   // list objects
+	Delete bool `json:"delete"` // delete objects
+}
+
+// BucketExists 检查存储桶是否存在。无权访问的存储桶也存在, 返回 true
+func (c *Client) BucketExists(bucket string) (_ bool, err error) {
+	ctx, op := c.startOperation("BucketExists", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	err = c.call(ctx, "GET", withQuery(bucketPath(bucket)+"/files", url.Values{"limit": {"1"}}), nil, nil)
+	switch {
+	case err == nil, isForbidden(err):
+		return true, nil
+	case isNotFound(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// CheckBucketAccess 检查当前凭据对存储桶的权限, 适合在启动时校验配置。
+// 服务端没有权限接口时以列举探测读权限, 写和删除权限取自 WhoAmI 的 scopes
+func (c *Client) CheckBucketAccess(bucket string) (_ Permissions, err error) {
+	ctx, op := c.startOperation("CheckBucketAccess", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	var perms Permissions
+	err = c.call(ctx, "GET", bucketPath(bucket)+"/access", nil, &perms)
+	if err == nil || !isMissingEndpoint(err) {
+		return perms, err
+	}
+	// a missing bucket fails the listing probe as well
+
+	err = c.call(ctx, "GET", withQuery(bucketPath(bucket)+"/files", url.Values{"limit": {"1"}}), nil, nil)
+	if isForbidden(err) {
+		return Permissions{}, nil
+	}
+	if err != nil {
+		return Permissions{}, err
+	}
+	perms = Permissions{Read: true, List: true, Write: true, Delete: true}
+	identity, err := c.WithContext(ctx).WhoAmI()
+	if err != nil && !isMissingEndpoint(err) {
+		return Permissions{}, err
+	}
+	// credentials without reported scopes are assumed to be unrestricted
+	if identity != nil && len(identity.Scopes) > 0 {
+		perms.Write = identity.HasScope("write")
+		perms.Delete = identity.HasScope("delete")
+	}
+	return perms, nil
+}
+
+// isForbidden err is a 401 or 403 API error
+func isForbidden(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusUnauthorized)
+}
+
+// ErrBucketOwnedByOther the bucket name is taken by another account
+var ErrBucketOwnedByOther = errors.New("lingstorage: bucket is owned by another account")
+
+// EnsureBucketRequest EnsureBucket request
+type EnsureBucketRequest struct {
+	CreateBucketRequest
+	// Reconcile apply IsPrivate and Tags to a bucket that already exists
+	Reconcile bool
+}
+
+// SetBucketTags 替换存储桶标签
+func (c *Client) SetBucketTags(bucket string, tags map[string]string) (err error) {
+	ctx, op := c.startOperation("SetBucketTags", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := c.requireFeature(FeatureTags); err != nil {
+		return err
+	}
+	return c.call(ctx, "PUT", bucketPath(bucket)+"/tags", map[string]interface{}{"tags": tags}, nil)
+}
+
+// EnsureBucket 存储桶不存在时创建, 已存在且属于自己时视为成功,
+// 返回是否新建。名称被其他账号占用时返回 ErrBucketOwnedByOther
+func (c *Client) EnsureBucket(req *EnsureBucketRequest) (created bool, err error) {
+	ctx, op := c.startOperation("EnsureBucket", req.BucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	client := c.WithContext(ctx)
+	err = client.CreateBucket(&req.CreateBucketRequest)
+	if err == nil {
+		return true, nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		return false, err
+	}
+
+	// the conflict does not tell whose bucket it is, a bucket we cannot list is not ours
+	perms, err := client.CheckBucketAccess(req.BucketName)
+	if err != nil {
+		return false, err
+	}
+	if !perms.List {
+		return false, fmt.Errorf("%w: %s", ErrBucketOwnedByOther, req.BucketName)
+	}
+	if !req.Reconcile {
+		return false, nil
+	}
+	if err := client.reconcileBucket(req); err != nil {
+		return false, fmt.Errorf("failed to reconcile %s: %w", req.BucketName, err)
+	}
+	return false, nil
+}
+
+// reconcileBucket apply privacy and tags of req to an existing bucket, privacy
+// only when it differs
+func (c *Client) reconcileBucket(req *EnsureBucketRequest) error {
+	info, err := c.fetchBucketDomains(req.BucketName)
+	if err != nil {
+		return err
+	}
+	if info.private != req.IsPrivate {
+		if err := c.SetBucketPrivate(&SetBucketPrivateRequest{BucketName: req.BucketName, IsPrivate: req.IsPrivate}); err != nil {
+			return err
+		}
+	}
+	if len(req.Tags) > 0 {
+		return c.SetBucketTags(req.BucketName, req.Tags)
+	}
+	return nil
+}
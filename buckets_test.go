@@ -0,0 +1,196 @@
+package lingstorage_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBucketsPagination(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	existing, err := client.ListBuckets("", false)
+	require.NoError(t, err)
+	for i := 0; i < 25; i++ {
+		server.CreateBucket(fmt.Sprintf("bucket-%02d", i))
+	}
+
+	page, err := client.ListBucketsPage(&lingstorage.ListBucketsRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Buckets, 10)
+	assert.True(t, page.IsTruncated)
+	assert.Equal(t, page.Buckets[9], page.NextMarker)
+
+	// 迭代器逐页获取全部存储桶
+	var names []string
+	it := client.IterateBuckets(&lingstorage.ListBucketsRequest{Limit: 10})
+	for it.Next() {
+		names = append(names, it.Bucket())
+	}
+	require.NoError(t, it.Err())
+	assert.Len(t, names, 25+len(existing))
+	assert.IsIncreasing(t, names)
+
+	all, err := client.ListBuckets("", false)
+	require.NoError(t, err)
+	assert.Equal(t, names, all)
+}
+
+func TestBucketIteratorError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("marker") == "" {
+			w.Write([]byte(`{"success":true,"data":{"buckets":["a","b"],"nextMarker":"b","isTruncated":true}}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"denied"}`))
+	}))
+	defer server.Close()
+
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+	it := client.IterateBuckets(nil)
+	var names []string
+	for it.Next() {
+		names = append(names, it.Bucket())
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Error(t, it.Err())
+	assert.False(t, it.Next())
+	assert.Equal(t, 2, calls)
+
+	_, err := client.ListBuckets("", false)
+	assert.Error(t, err)
+}
+
+func TestForceDeleteBucket(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	for i := 0; i < 30; i++ {
+		server.PutObject("tenant-a", fmt.Sprintf("dir/%02d.txt", i), []byte("x"))
+	}
+
+	// 非空存储桶无法直接删除
+	require.Error(t, client.DeleteBucket("tenant-a"))
+
+	var progress []int
+	require.NoError(t, client.ForceDeleteBucket("tenant-a", func(deleted int) {
+		progress = append(progress, deleted)
+	}))
+	require.NotEmpty(t, progress)
+	assert.Equal(t, 30, progress[len(progress)-1])
+	assert.NotContains(t, server.Buckets(), "tenant-a")
+
+	assert.Error(t, client.ForceDeleteBucket("tenant-a", nil))
+}
+
+func TestBucketExists(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	exists, err := client.BucketExists(lingstoragetest.DefaultBucket)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = client.BucketExists("missing-bucket")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// 无权限的存储桶同样存在
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+	exists, err = lingstorage.NewClient(&lingstorage.Config{BaseURL: forbidden.URL, RetryCount: -1}).BucketExists("other")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestCheckBucketAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets/assets/access":
+			w.Write([]byte(`{"success":true,"data":{"read":true,"list":true}}`))
+		case "/api/public/buckets/legacy/files":
+			w.Write([]byte(`{"success":true,"data":{"files":[]}}`))
+		case "/api/public/buckets/locked/files":
+			w.WriteHeader(http.StatusForbidden)
+		case "/api/public/whoami":
+			w.Write([]byte(`{"success":true,"data":{"userId":"u1","scopes":["read","write"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+
+	perms, err := client.CheckBucketAccess("assets")
+	require.NoError(t, err)
+	assert.Equal(t, lingstorage.Permissions{Read: true, List: true}, perms)
+
+	// 旧版服务端: 列举探测加 scopes 推断
+	perms, err = client.CheckBucketAccess("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, lingstorage.Permissions{Read: true, List: true, Write: true}, perms)
+
+	perms, err = client.CheckBucketAccess("locked")
+	require.NoError(t, err)
+	assert.Equal(t, lingstorage.Permissions{}, perms)
+
+	_, err = client.CheckBucketAccess("missing")
+	assert.Error(t, err)
+}
+
+func TestEnsureBucket(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	req := &lingstorage.EnsureBucketRequest{CreateBucketRequest: lingstorage.CreateBucketRequest{BucketName: "tenant-7", IsPrivate: true}}
+
+	created, err := client.EnsureBucket(req)
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = client.EnsureBucket(req)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// 已存在时按请求调整权限和标签
+	req.IsPrivate = false
+	req.Tags = map[string]string{"tenant": "7"}
+	req.Reconcile = true
+	created, err = client.EnsureBucket(req)
+	require.NoError(t, err)
+	assert.False(t, created)
+	settings, _ := server.BucketSettings("tenant-7")
+	assert.False(t, settings.IsPrivate)
+	assert.Equal(t, map[string]string{"tenant": "7"}, settings.Tags)
+}
+
+func TestEnsureBucketOwnedByOther(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success":false,"message":"bucket already exists"}`))
+		case "/api/public/buckets/shared-name/access":
+			w.Write([]byte(`{"success":true,"data":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+
+	_, err := client.EnsureBucket(&lingstorage.EnsureBucketRequest{CreateBucketRequest: lingstorage.CreateBucketRequest{BucketName: "shared-name"}})
+	assert.ErrorIs(t, err, lingstorage.ErrBucketOwnedByOther)
+}
@@ -0,0 +1,44 @@
+package lingstorage
+
+import "fmt"
+
+// SyncOptions options of a server side bucket sync
+type SyncOptions struct {
+	Prefix           string // only objects below the prefix, in both buckets
+	DeleteExtraneous bool   // delete destination objects missing from the source
+}
+
+// SyncBuckets ask the server to make dest match src: new and changed objects
+// are copied server side, without passing through the client. Poll the job
+// with WaitForJob; the metadata cache of dest is not updated by the job
+func (c *Client) SyncBuckets(src, dest string, opts *SyncOptions) (_ *Job, err error) {
+	ctx, op := c.startOperation("SyncBuckets", src, "")
+	defer func() { c.endOperation(op, err) }()
+	if dest == "" {
+		return nil, fmt.Errorf("sync destination bucket is empty")
+	}
+	if dest == src {
+		return nil, fmt.Errorf("sync source and destination are the same bucket %s", src)
+	}
+	if !c.config.SkipNameValidation {
+		if err := ValidateBucketName(dest); err != nil {
+			return nil, err
+		}
+	}
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+	if err := c.requireFeature(FeatureBucketSync); err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"destBucket":       dest,
+		"prefix":           opts.Prefix,
+		"deleteExtraneous": opts.DeleteExtraneous,
+	}
+	var result Job
+	if err := c.call(ctx, "POST", bucketPath(src)+"/sync", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
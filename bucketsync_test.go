@@ -0,0 +1,37 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncBuckets(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets/media/sync":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "sync-1", "type": "bucket-sync", "status": "pending", "bucket": "media"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	job, err := client.SyncBuckets("media", "media-backup", &SyncOptions{Prefix: "photos/", DeleteExtraneous: true})
+	require.NoError(t, err)
+	assert.Equal(t, "sync-1", job.ID)
+	assert.Equal(t, map[string]interface{}{"destBucket": "media-backup", "prefix": "photos/", "deleteExtraneous": true}, body)
+
+	_, err = client.SyncBuckets("media", "media", nil)
+	assert.Error(t, err)
+	_, err = client.SyncBuckets("media", "Bad_Bucket", nil)
+	assert.ErrorIs(t, err, ErrInvalidBucketName)
+}
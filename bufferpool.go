@@ -0,0 +1,86 @@
+package lingstorage
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxPooledBufferSize buffers grown beyond this are left to the GC, so a
+	// single large upload does not pin its memory in the pool
+	maxPooledBufferSize = 4 << 20
+	copyBufferSize      = 32 << 10
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+var copyBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
+// getBuffer empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer return buf to the pool, buf must not be used afterwards
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// copyBuffered io.Copy with a pooled copy buffer
+func copyBuffered(dst io.Writer, src io.Reader) (int64, error) {
+	buf := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(buf)
+	return io.CopyBuffer(dst, src, *buf)
+}
+
+// pooledBody request body over a pooled buffer. The transport may close a
+// body after RoundTrip returned, so the buffer only goes back to the pool
+// when every body handed out has been closed; otherwise it is left to the GC
+type pooledBody struct {
+	buf  *bytes.Buffer
+	open int32
+}
+
+// body new reader over the buffer, usable as Request.Body and by GetBody
+func (p *pooledBody) body() io.ReadCloser {
+	atomic.AddInt32(&p.open, 1)
+	return &pooledBodyReader{Reader: bytes.NewReader(p.buf.Bytes()), owner: p}
+}
+
+func (p *pooledBody) getBody() (io.ReadCloser, error) {
+	return p.body(), nil
+}
+
+// release return the buffer to the pool if no body is still open
+func (p *pooledBody) release() {
+	if atomic.LoadInt32(&p.open) == 0 {
+		putBuffer(p.buf)
+	}
+}
+
+type pooledBodyReader struct {
+	*bytes.Reader
+	owner  *pooledBody
+	closed int32
+}
+
+func (r *pooledBodyReader) Close() error {
+	if atomic.CompareAndSwapInt32(&r.closed, 0, 1) {
+		atomic.AddInt32(&r.owner.open, -1)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package lingstorage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooledBody(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("payload")
+	pooled := &pooledBody{buf: buf}
+
+	body := pooled.body()
+	replay, err := pooled.getBody()
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, "payload", string(data))
+	data, _ = io.ReadAll(replay)
+	assert.Equal(t, "payload", string(data))
+
+	// 仍有未关闭的 body 时不回收
+	body.Close()
+	body.Close()
+	assert.EqualValues(t, 1, pooled.open)
+	replay.Close()
+	assert.EqualValues(t, 0, pooled.open)
+	pooled.release()
+}
+
+func TestPutBufferDropsLargeBuffers(t *testing.T) {
+	large := bytes.NewBuffer(make([]byte, 0, maxPooledBufferSize+1))
+	putBuffer(large)
+	for i := 0; i < 10; i++ {
+		assert.NotSame(t, large, getBuffer())
+	}
+}
+
+func TestUploadRetryReplaysPooledBody(t *testing.T) {
+	var bodies []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		bodies = append(bodies, n)
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"key":"k"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 1})
+	_, err := client.UploadBytes(&UploadBytesRequest{Bucket: "bkt", Key: "k", Filename: "k.txt", Data: bytes.Repeat([]byte("x"), 64<<10)})
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Greater(t, bodies[0], int64(64<<10))
+}
+
+func benchmarkServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"success":true,"data":{"key":"k"}}`))
+	}))
+}
+
+// BenchmarkUploadBytes 批量小文件上传的单次开销
+func BenchmarkUploadBytes(b *testing.B) {
+	server := benchmarkServer()
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	for _, size := range []int{1 << 10, 64 << 10, 1 << 20} {
+		data := bytes.Repeat([]byte("x"), size)
+		b.Run(fmt.Sprintf("%dKB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := client.UploadBytes(&UploadBytesRequest{Bucket: "bkt", Key: "k", Filename: "k.txt", Data: data}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMultipartEncode multipart 编码: 池化缓冲区与每次新建缓冲区对比
+func BenchmarkMultipartEncode(b *testing.B) {
+	data := bytes.Repeat([]byte("x"), 64<<10)
+	encode := func(buf *bytes.Buffer, copyFn func(io.Writer, io.Reader) (int64, error)) {
+		writer := multipart.NewWriter(buf)
+		part, _ := writer.CreateFormFile("file", "k.txt")
+		copyFn(part, struct{ io.Reader }{bytes.NewReader(data)})
+		writer.WriteField("bucket", "bkt")
+		writer.Close()
+	}
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			encode(&buf, io.Copy)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := getBuffer()
+			encode(buf, copyBuffered)
+			putBuffer(buf)
+		}
+	})
+}
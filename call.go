@@ -0,0 +1,99 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// apiURL absolute URL of an API path such as "/api/public/buckets"
+func (c *Client) apiURL(path string) string {
+	return strings.TrimRight(c.config.BaseURL, "/") + path
+}
+
+// objectPath API path of an object. Bucket and key segments are escaped so
+// keys with "#", "?", "%" or spaces address the object itself
+func objectPath(bucket, key string) string {
+	return "/api/public/files/" + url.PathEscape(bucket) + "/" + escapeKey(key)
+}
+
+// bucketPath API path of a bucket, the name escaped
+func bucketPath(bucket string) string {
+	return "/api/public/buckets/" + url.PathEscape(bucket)
+}
+
+// jobPath API path of a job, the id escaped
+func jobPath(jobID string) string {
+	return "/api/public/jobs/" + url.PathEscape(jobID)
+}
+
+// escapeKey escape each segment of an object key, keeping the slashes
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// withQuery path with the encoded query appended, path unchanged when q is empty
+func withQuery(path string, q url.Values) string {
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
+
+// newRequest API request with headers and auth set. A non-nil body is sent as JSON
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var data []byte
+	var reader io.Reader
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.apiURL(path), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set(constants.CONTENT_TYPE, "application/json")
+	}
+	if err := c.setHeaders(httpReq, data); err != nil {
+		return nil, err
+	}
+	return httpReq, nil
+}
+
+// send API request with retry, the response is returned whatever its status
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	httpReq, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.doRequestWithRetry(httpReq)
+}
+
+// call send API request and decode the data of the response into out, which
+// may be nil. Responses outside 2xx are returned as *APIError
+func (c *Client) call(ctx context.Context, method, path string, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return c.handleErrorResponse(resp)
+	}
+	return c.decodeResponse(resp, out)
+}
@@ -0,0 +1,79 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-API-Key"))
+		switch r.URL.Path {
+		case "/api/public/echo":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "a b", r.URL.Query().Get("q"))
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": body})
+		case "/api/public/empty":
+			assert.Empty(t, r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL + "/", APIKey: "test-key", RetryCount: -1})
+	ctx := context.Background()
+
+	var out map[string]string
+	err := client.call(ctx, "POST", withQuery("/api/public/echo", url.Values{"q": {"a b"}}), map[string]string{"name": "x"}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "x"}, out)
+
+	require.NoError(t, client.call(ctx, "DELETE", "/api/public/empty", nil, nil))
+
+	err = client.call(ctx, "GET", "/api/public/missing", nil, &out)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestWithQuery(t *testing.T) {
+	assert.Equal(t, "/api/public/jobs", withQuery("/api/public/jobs", nil))
+	assert.Equal(t, "/api/public/jobs?limit=5&type=ocr", withQuery("/api/public/jobs", url.Values{"type": {"ocr"}, "limit": {"5"}}))
+}
+
+func TestObjectPathEscaping(t *testing.T) {
+	var paths, queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"key": "k"}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	// 键中的 # ? % 和空格必须原样到达服务端, 不能截断或变成查询参数
+	keys := []string{"photos/a#b.jpg", "photos/a?x=1", "100%.txt", "my photos/a b.jpg"}
+	for _, key := range keys {
+		paths, queries = nil, nil
+		require.NoError(t, client.DeleteFile("bkt", key), key)
+		_, err := client.GetFileInfo("bkt", key)
+		require.NoError(t, err, key)
+		assert.Equal(t, []string{"/api/public/files/bkt/" + key, "/api/public/files/bkt/" + key + "/info"}, paths, key)
+		assert.Equal(t, []string{"", ""}, queries, key)
+	}
+
+	assert.Equal(t, "/api/public/files/bkt/a/b%23c%3Fd%25e%20f", objectPath("bkt", "a/b#c?d%e f"))
+	assert.Equal(t, "/api/public/buckets/my%20bucket", bucketPath("my bucket"))
+}
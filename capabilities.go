@@ -0,0 +1,86 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrNotSupported the server does not support the called feature
+var ErrNotSupported = errors.New("lingstorage: not supported by server")
+
+// Is report 501 Not Implemented responses as ErrNotSupported, 412
+// Precondition Failed responses as ErrPreconditionFailed and 413 Request
+// Entity Too Large responses as ErrUploadTooLarge
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotSupported:
+		return e.StatusCode == http.StatusNotImplemented
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case ErrUploadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// capabilityCache server info detected on first use, shared by client copies
+type capabilityCache struct {
+	mu      sync.Mutex
+	fetched bool
+	info    *ServerInfo // nil when the server does not report its info
+}
+
+// Capabilities server info fetched once and cached for the client lifetime.
+// Returns nil without error when the server predates the info API
+func (c *Client) Capabilities() (*ServerInfo, error) {
+	c.capabilities.mu.Lock()
+	defer c.capabilities.mu.Unlock()
+	if c.capabilities.fetched {
+		return c.capabilities.info, nil
+	}
+	info, err := c.ServerInfo()
+	if err != nil {
+		if !isMissingEndpoint(err) {
+			return nil, err
+		}
+		info = nil
+	}
+	c.capabilities.fetched = true
+	c.capabilities.info = info
+	return info, nil
+}
+
+// cached server info when already fetched, nil otherwise
+func (cc *capabilityCache) cached() *ServerInfo {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.info
+}
+
+// RefreshCapabilities drop the cached server info, e.g. after a server upgrade
+func (c *Client) RefreshCapabilities() {
+	c.capabilities.mu.Lock()
+	defer c.capabilities.mu.Unlock()
+	c.capabilities.fetched = false
+	c.capabilities.info = nil
+}
+
+// Supports check the server reports feature, true when it is unknown
+func (c *Client) Supports(feature string) bool {
+	info, err := c.Capabilities()
+	if err != nil || info == nil {
+		return true
+	}
+	return info.HasFeature(feature)
+}
+
+// requireFeature fail fast with ErrNotSupported instead of an opaque 404.
+// Unknown capabilities let the call through for the server to decide
+func (c *Client) requireFeature(feature string) error {
+	if c.config.SkipCapabilityCheck || c.Supports(feature) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNotSupported, feature)
+}
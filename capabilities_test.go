@@ -0,0 +1,85 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityCheck(t *testing.T) {
+	var infoCalls, purgeCalls int32
+	features := []string{FeatureInventory}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/info":
+			atomic.AddInt32(&infoCalls, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"version": "1.0", "features": features}})
+		case "/api/public/buckets/site/cdn/purge":
+			atomic.AddInt32(&purgeCalls, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1"}})
+		case "/api/public/buckets/site/inventory":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-2"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	assert.True(t, client.Supports(FeatureInventory))
+	assert.False(t, client.Supports(FeatureCDN))
+
+	_, err := client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Prefix: "assets/"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.EqualValues(t, 0, purgeCalls)
+
+	_, err = client.CreateInventoryReport("site", InventoryCSV, "reports")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, infoCalls)
+
+	// 服务端升级后刷新
+	features = append(features, FeatureCDN)
+	client.RefreshCapabilities()
+	_, err = client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Prefix: "assets/"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, infoCalls)
+
+	// 关闭检查后直接调用
+	features = nil
+	unchecked := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, SkipCapabilityCheck: true})
+	_, err = unchecked.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Prefix: "assets/"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, infoCalls)
+	assert.EqualValues(t, 2, purgeCalls)
+}
+
+func TestCapabilitiesUnknown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets/site/cdn/prefetch":
+			w.WriteHeader(http.StatusNotImplemented)
+			w.Write([]byte(`{"message":"cdn not configured"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// 旧版服务端没有 info 接口, 交给服务端判断
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	info, err := client.Capabilities()
+	require.NoError(t, err)
+	assert.Nil(t, info)
+	assert.True(t, client.Supports(FeatureCDN))
+
+	_, err = client.PrefetchCDN("site", []string{"index.html"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "cdn not configured", apiErr.Message)
+}
@@ -0,0 +1,79 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxCDNKeysPerRequest max keys of one purge or prefetch request
+const MaxCDNKeysPerRequest = 1000
+
+// CDNPurgeRequest CDN 缓存刷新请求, Keys 与 Prefix 二选一
+type CDNPurgeRequest struct {
+	Bucket string
+	Keys   []string // objects to purge
+	Prefix string   // purge every object under the prefix
+}
+
+// PurgeCDNCache invalidate cached copies of objects on the CDN, e.g. right
+// after a deploy uploaded new assets. The purge runs as a job, poll it with WaitForJob
+func (c *Client) PurgeCDNCache(req *CDNPurgeRequest) (_ *Job, err error) {
+	ctx, op := c.startOperation("PurgeCDNCache", req.Bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if (len(req.Keys) == 0) == (req.Prefix == "") {
+		return nil, fmt.Errorf("exactly one of keys or prefix must be set")
+	}
+	if err := c.checkCDNKeys(req.Keys); err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{}
+	if req.Prefix != "" {
+		body["prefix"] = req.Prefix
+	} else {
+		body["keys"] = req.Keys
+	}
+	return c.cdnJob(ctx, "purge", req.Bucket, body)
+}
+
+// PrefetchCDN warm the CDN cache with the objects before clients request them.
+// The prefetch runs as a job, poll it with WaitForJob
+func (c *Client) PrefetchCDN(bucket string, keys []string) (_ *Job, err error) {
+	ctx, op := c.startOperation("PrefetchCDN", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys to prefetch")
+	}
+	if err := c.checkCDNKeys(keys); err != nil {
+		return nil, err
+	}
+	return c.cdnJob(ctx, "prefetch", bucket, map[string]interface{}{"keys": keys})
+}
+
+// checkCDNKeys enforce the per request key limit and naming rules
+func (c *Client) checkCDNKeys(keys []string) error {
+	if len(keys) > MaxCDNKeysPerRequest {
+		return fmt.Errorf("too many keys: %d, at most %d per request", len(keys), MaxCDNKeysPerRequest)
+	}
+	if c.config.SkipNameValidation {
+		return nil
+	}
+	for _, key := range keys {
+		if err := ValidateObjectKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cdnJob post a CDN action of the bucket and return the started job
+func (c *Client) cdnJob(ctx context.Context, action, bucket string, body map[string]interface{}) (*Job, error) {
+	if err := c.requireFeature(FeatureCDN); err != nil {
+		return nil, err
+	}
+	var result Job
+	if err := c.call(ctx, "POST", bucketPath(bucket)+"/cdn/"+action, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,68 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCDNPurgeAndPrefetch(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets/site/cdn/purge", "/api/public/buckets/site/cdn/prefetch":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			bodies = append(bodies, body)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "cdn-1", "type": "cdn", "status": "running"}})
+		case "/api/public/jobs/cdn-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "cdn-1", "type": "cdn", "status": "succeeded"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	job, err := client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Keys: []string{"index.html", "app.js"}})
+	require.NoError(t, err)
+	assert.Equal(t, "cdn-1", job.ID)
+	job, err = client.WaitForJob(context.Background(), job.ID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, JobSucceeded, job.Status)
+
+	_, err = client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Prefix: "assets/"})
+	require.NoError(t, err)
+	_, err = client.PrefetchCDN("site", []string{"index.html"})
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 3)
+	assert.Equal(t, []interface{}{"index.html", "app.js"}, bodies[0]["keys"])
+	assert.Equal(t, "assets/", bodies[1]["prefix"])
+	assert.NotContains(t, bodies[1], "keys")
+	assert.Equal(t, []interface{}{"index.html"}, bodies[2]["keys"])
+}
+
+func TestCDNRequestValidation(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "http://127.0.0.1:1", RetryCount: -1})
+	_, err := client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site"})
+	assert.ErrorContains(t, err, "exactly one")
+	_, err = client.PurgeCDNCache(&CDNPurgeRequest{Bucket: "site", Keys: []string{"a"}, Prefix: "b/"})
+	assert.ErrorContains(t, err, "exactly one")
+	_, err = client.PrefetchCDN("site", []string{"../secret"})
+	assert.ErrorIs(t, err, ErrInvalidObjectKey)
+
+	keys := make([]string, MaxCDNKeysPerRequest+1)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+	}
+	_, err = client.PrefetchCDN("site", keys)
+	assert.ErrorContains(t, err, "too many keys")
+}
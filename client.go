@@ -2,24 +2,51 @@ package lingstorage
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/LingByte/lingstorage-sdk-go/constants"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client LingStorage SDK Client
 type Client struct {
-	config     *Config
-	httpClient *http.Client
+	config              *Config
+	httpClient          *http.Client
+	credentialsProvider CredentialsProvider
+	initErr             error // config error reported by every request
+	ctx                 context.Context
+	tracer              trace.Tracer
+	propagator          propagation.TextMapPropagator
+	requestHooks        []RequestHook
+	preUploadHooks      []PreUploadHook
+	responseHooks       []ResponseHook
+	headers             http.Header   // extra headers of every request, see WithHeader
+	endpoints           *endpointPool // primary and replicas, nil without Replicas
+	accel               *accelerator  // upload acceleration, shared by clients derived with WithContext
+	regions             *regionResolver
+	domains             *domainCache
+	capabilities        *capabilityCache
+	metadata            *metadataCache          // nil unless Config.MetadataCacheSize is set
+	responseFormat      *atomic.Int32           // last detected ResponseFormat
+	token               *atomic.Pointer[string] // access token last sent, scrubbed from logs and errors
+	stats               *statsRecorder          // shared by clients derived with WithContext
 }
 
 // Config LingStorage client config
@@ -28,28 +55,185 @@ type Config struct {
 	APIKey     string        // API Key
 	APISecret  string        // API Secret
 	Timeout    time.Duration // Request Timeout
-	RetryCount int           // retry times
+	RetryCount int           // retry times, default 3, negative disables retry
 	UserAgent  string        // user agent
+	// AttemptTimeout deadline of each attempt including reading the response
+	// body, a timed out attempt is retried. 0 leaves only Timeout
+	AttemptTimeout time.Duration
+
+	// SignRequests sign every request with HMAC-SHA256 instead of sending APISecret
+	SignRequests bool
+	// Credentials provider of rotating credentials, overrides APIKey/APISecret.
+	// It is wrapped with a CachedProvider unless it already is one
+	Credentials CredentialsProvider
+	// TokenSource enable token auth mode, the token replaces API key/secret headers
+	TokenSource TokenSource
+	// TokenHeader header carrying the token, default Authorization with "Bearer" scheme
+	TokenHeader string
+
+	// TLS options, applied on top of TLSConfig when set
+	TLSConfig          *tls.Config // base tls config
+	ClientCertFile     string      // client certificate PEM file for mutual TLS
+	ClientKeyFile      string      // client private key PEM file for mutual TLS
+	ClientCertPEM      []byte      // client certificate PEM, alternative to ClientCertFile
+	ClientKeyPEM       []byte      // client private key PEM, alternative to ClientKeyFile
+	CAFile             string      // extra CA certificates PEM file trusted besides system roots
+	CAPEM              []byte      // extra CA certificates PEM
+	PinnedPublicKeys   []string    // base64 sha256 of trusted server SPKI, matched against the verified chain
+	InsecureSkipVerify bool        // skip server certificate verification, testing only
+
+	// Connection pool options of the internal transport
+	MaxIdleConns        int           // idle connections kept across all hosts, default 100
+	MaxIdleConnsPerHost int           // idle connections kept per host, default 32
+	MaxConnsPerHost     int           // dialing, active and idle connections per host, 0 means no limit
+	IdleConnTimeout     time.Duration // how long an idle connection is kept, default 90s
+	// ForceHTTP1 disable HTTP/2, which is otherwise used when a TLS server supports it
+	ForceHTTP1 bool
+	// DialContext dial connections of the internal transport, default net.Dialer
+	DialContext DialContextFunc
+	// DNSCacheTTL cache resolved server addresses for this long, 0 resolves on every dial
+	DNSCacheTTL time.Duration
+	// Resolver look up server addresses instead of the system resolver
+	Resolver HostResolver
+	// Proxy options, when any is set the proxy environment variables are ignored.
+	// URLs may use the http, https, socks5 and socks5h schemes
+	HTTPProxy  string   // proxy of http server addresses
+	HTTPSProxy string   // proxy of https server addresses, default HTTPProxy
+	NoProxy    []string // hosts, domains, IPs and CIDRs reached directly, "*" disables the proxy
+
+	// Redactor mark extra headers / query parameters as sensitive in dumps and errors
+	Redactor Redactor
+
+	// TracerProvider enable OpenTelemetry spans around every operation
+	TracerProvider trace.TracerProvider
+	// Propagator inject trace context into outgoing requests, default otel global propagator
+	Propagator propagation.TextMapPropagator
+	// Metrics receive request, error, latency, bytes and retry metrics
+	Metrics MetricsCollector
+
+	// Logger structured logger of requests, nil disables logging
+	Logger *slog.Logger
+	// Debug dump redacted headers and bodies of every request
+	Debug bool
+	// DebugBodyLimit max body bytes dumped in debug mode, default 1024, negative disables bodies
+	DebugBodyLimit int
+	// CollectTiming collect a per attempt timing breakdown, returned in
+	// UploadResult.Timing, DownloadResult.Timing and by ResponseTiming
+	CollectTiming bool
+	// DataTransport carries uploads and downloads instead of the HTTP API, nil uses HTTP
+	DataTransport DataTransport
+
+	// Replicas extra server addresses serving the same API, BaseURL stays the primary.
+	// Requests fail over to the next healthy endpoint on connection errors
+	Replicas []string
+	// ReadFromNearest route GET and HEAD requests to the healthy endpoint with the lowest probed latency
+	ReadFromNearest bool
+	// HealthCheckInterval how long a failed endpoint is skipped and the probe period, default 30s
+	HealthCheckInterval time.Duration
+	// Region region the client targets. Requests go to the regional endpoint
+	// listed by ListRegions and bucket operations fail with ErrRegionMismatch
+	// when the bucket lives in another region
+	Region string
+	// SkipNameValidation send bucket names and object keys without checking
+	// them against ValidateBucketName and ValidateObjectKey
+	SkipNameValidation bool
+	// SanitizeKeys pass upload keys and filenames through SanitizeKey, for
+	// names supplied by end users of multi-tenant applications
+	SanitizeKeys bool
+	// DomainCacheTTL how long PublicURL caches bucket domains, default 5m, negative disables the cache
+	DomainCacheTTL time.Duration
+	// MetadataCacheSize entries of the LRU cache of GetFileInfo and GetFileURL
+	// results, 0 disables it. Deletes, copies, moves and uploads through the
+	// client invalidate the objects they change
+	MetadataCacheSize int
+	// MetadataCacheTTL how long cached results are served, default 1m
+	MetadataCacheTTL time.Duration
+	// SkipCapabilityCheck call optional server features without checking
+	// ServerInfo first, the server then reports unsupported calls itself
+	SkipCapabilityCheck bool
+	// Multipart copy options. Sources larger than MultipartCopyThreshold are
+	// copied with ranged part copies; 0 copies in one request and splits only
+	// when the server rejects the source as too large, negative never splits
+	MultipartCopyThreshold int64
+	CopyPartSize           int64 // bytes per part copy, default 512MB
+	CopyConcurrency        int   // parallel part copies, default 4
+	// MaxUploadSize bytes a single upload may carry, larger uploads fail with
+	// ErrUploadTooLarge before any data is sent. 0 takes the limit reported by
+	// ServerInfo once Capabilities has fetched it, negative disables the check
+	MaxUploadSize int64
 }
 
 // NewClient create new lingStorage client
-func NewClient(config *Config) *Client {
+func NewClient(config *Config, opts ...ClientOption) *Client {
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
 	if config.RetryCount == 0 {
 		config.RetryCount = 3
+	} else if config.RetryCount < 0 {
+		config.RetryCount = 0
 	}
 	if config.UserAgent == "" {
 		config.UserAgent = constants.DEFAULT_USER_AGENT
 	}
 
-	return &Client{
+	client := &Client{
 		config: config,
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
+		stats:          newStatsRecorder(),
+		accel:          &accelerator{},
+		regions:        &regionResolver{},
+		domains:        &domainCache{},
+		capabilities:   &capabilityCache{},
+		metadata:       newMetadataCache(config.MetadataCacheSize, config.MetadataCacheTTL),
+		responseFormat: new(atomic.Int32),
+		token:          new(atomic.Pointer[string]),
+	}
+	if transport, err := newTransport(config); err != nil {
+		client.initErr = err
+	} else {
+		client.httpClient.Transport = transport
+	}
+	if len(config.Replicas) > 0 {
+		client.endpoints = newEndpointPool(config)
+	}
+	if config.TracerProvider != nil {
+		client.tracer = config.TracerProvider.Tracer(tracerName)
+		client.propagator = config.Propagator
+		if client.propagator == nil {
+			client.propagator = otel.GetTextMapPropagator()
+		}
+	}
+	if config.Credentials != nil {
+		if cached, ok := config.Credentials.(*CachedProvider); ok {
+			client.credentialsProvider = cached
+		} else {
+			client.credentialsProvider = NewCachedProvider(config.Credentials)
+		}
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// WithContext shallow copy of the client whose requests are bound to ctx,
+// used for cancellation, deadlines and trace propagation
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+// context context of the client requests
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
 	}
+	return c.ctx
 }
 
 // UploadRequest upload request
@@ -63,7 +247,28 @@ type UploadRequest struct {
 	Watermark         bool                        // if watermark
 	WatermarkText     string                      // watermark text
 	WatermarkPosition string                      // watermark position
+	WatermarkImageKey string                      // key of the overlay image, e.g. a logo in the same bucket
+	WatermarkOpacity  int                         // watermark opacity 1-100, default 100
+	WatermarkScale    float64                     // watermark width relative to the image width, 0-1
+	WatermarkMargin   int                         // watermark margin to the image edge in pixels
+	Resize            *ResizeOptions              // resize image
+	Format            string                      // convert image: jpeg, png, webp or avif
+	AutoOrient        bool                        // rotate image by its EXIF orientation
+	StripEXIF         bool                        // remove EXIF data such as GPS location
+	ModerationPolicy  *ModerationPolicy           // content moderation of the upload
+	ScanMalware       bool                        // request a server side malware scan
+	Metadata          map[string]string           // custom object metadata
+	SSEAlgorithm      string                      // server side encryption: AES256 or kms
+	SSEKMSKeyID       string                      // kms key id when SSEAlgorithm is kms
+	SSECustomerKey    []byte                      // customer provided 32 bytes key (SSE-C)
+	IfMatch           string                      // overwrite only the object with this ETag, else ErrPreconditionFailed
+	IfNoneMatch       string                      // "*" creates the object only when the key is free
 	OnProgress        func(uploaded, total int64) // upload progress callback
+	OnProgressCheck   ProgressFunc                // like OnProgress, returning an error aborts the upload
+
+	// PreserveAttributes store the file modification time and permissions as
+	// metadata, restored by DownloadFileWithAttributes. UploadFile only
+	PreserveAttributes bool
 }
 
 // UploadBytesRequest upload request from  bytes
@@ -78,22 +283,59 @@ type UploadBytesRequest struct {
 	Watermark         bool                        // if watermark
 	WatermarkText     string                      // watermark text
 	WatermarkPosition string                      // watermark position
+	WatermarkImageKey string                      // key of the overlay image, e.g. a logo in the same bucket
+	WatermarkOpacity  int                         // watermark opacity 1-100, default 100
+	WatermarkScale    float64                     // watermark width relative to the image width, 0-1
+	WatermarkMargin   int                         // watermark margin to the image edge in pixels
+	Resize            *ResizeOptions              // resize image
+	Format            string                      // convert image: jpeg, png, webp or avif
+	AutoOrient        bool                        // rotate image by its EXIF orientation
+	StripEXIF         bool                        // remove EXIF data such as GPS location
+	ModerationPolicy  *ModerationPolicy           // content moderation of the upload
+	ScanMalware       bool                        // request a server side malware scan
+	Metadata          map[string]string           // custom object metadata
+	SSEAlgorithm      string                      // server side encryption: AES256 or kms
+	SSEKMSKeyID       string                      // kms key id when SSEAlgorithm is kms
+	SSECustomerKey    []byte                      // customer provided 32 bytes key (SSE-C)
+	IfMatch           string                      // overwrite only the object with this ETag, else ErrPreconditionFailed
+	IfNoneMatch       string                      // "*" creates the object only when the key is free
 	OnProgress        func(uploaded, total int64) // upload progress callback
+	OnProgressCheck   ProgressFunc                // like OnProgress, returning an error aborts the upload
 }
 
 // BatchUploadRequest batch upload request
 type BatchUploadRequest struct {
-	Files             []string                                   // file list
-	Bucket            string                                     // bucket name
-	KeyPrefix         string                                     // key prefix
-	AllowedTypes      []string                                   // all types
-	Compress          bool                                       // if compress file
-	Quality           int                                        // quality 1-100 - default 100
-	Watermark         bool                                       // if watermark
-	WatermarkText     string                                     // watermark text
-	WatermarkPosition string                                     // watermark position
-	OnProgress        func(completed, total int, current string) // batch upload progress callback
-	OnFileProgress    func(uploaded, total int64)                // signal file upload progress
+	Files               []string                                   // file list
+	Patterns            []string                                   // glob patterns added to Files, ** matches any number of directories
+	Exclude             []string                                   // gitignore style patterns of files to skip
+	ManifestPath        string                                     // write a manifest of uploaded objects, csv by .csv extension, else json
+	Bucket              string                                     // bucket name
+	KeyPrefix           string                                     // key prefix
+	AllowedTypes        []string                                   // all types
+	Compress            bool                                       // if compress file
+	Quality             int                                        // quality 1-100 - default 100
+	Watermark           bool                                       // if watermark
+	WatermarkText       string                                     // watermark text
+	WatermarkPosition   string                                     // watermark position
+	WatermarkImageKey   string                                     // key of the overlay image, e.g. a logo in the same bucket
+	WatermarkOpacity    int                                        // watermark opacity 1-100, default 100
+	WatermarkScale      float64                                    // watermark width relative to the image width, 0-1
+	WatermarkMargin     int                                        // watermark margin to the image edge in pixels
+	Resize              *ResizeOptions                             // resize image
+	Format              string                                     // convert image: jpeg, png, webp or avif
+	AutoOrient          bool                                       // rotate image by its EXIF orientation
+	StripEXIF           bool                                       // remove EXIF data such as GPS location
+	ModerationPolicy    *ModerationPolicy                          // content moderation of the upload
+	ScanMalware         bool                                       // request a server side malware scan
+	SSEAlgorithm        string                                     // server side encryption: AES256 or kms
+	SSEKMSKeyID         string                                     // kms key id when SSEAlgorithm is kms
+	SSECustomerKey      []byte                                     // customer provided 32 bytes key (SSE-C)
+	OnProgress          func(completed, total int, current string) // batch upload progress callback
+	OnFileProgress      func(uploaded, total int64)                // signal file upload progress
+	OnFileProgressCheck ProgressFunc                               // like OnFileProgress, returning an error aborts the file
+	Progress            *MultiProgress                             // render per file and total progress bars
+
+	PreserveAttributes bool // store file modification times and permissions as metadata
 }
 
 // UploadFromReaderRequest read from io.Reader
@@ -109,16 +351,48 @@ type UploadFromReaderRequest struct {
 	Watermark         bool
 	WatermarkText     string
 	WatermarkPosition string
+	WatermarkImageKey string
+	WatermarkOpacity  int
+	WatermarkScale    float64
+	WatermarkMargin   int
+	Resize            *ResizeOptions
+	Format            string
+	AutoOrient        bool
+	StripEXIF         bool
+	ModerationPolicy  *ModerationPolicy
+	ScanMalware       bool
+	Metadata          map[string]string
+	SSEAlgorithm      string
+	SSEKMSKeyID       string
+	SSECustomerKey    []byte
+	IfMatch           string
+	IfNoneMatch       string
 	OnProgress        func(uploaded, total int64)
+	OnProgressCheck   ProgressFunc
 }
 
 // FileInfo 文件信息
 type FileInfo struct {
-	Key          string    `json:"key"`
-	Size         int64     `json:"size"`
-	LastModified time.Time `json:"lastModified"`
-	ETag         string    `json:"etag"`
-	ContentType  string    `json:"contentType"`
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"lastModified"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"contentType"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+
+	StorageClass   string `json:"storageClass,omitempty"`
+	Owner          string `json:"owner,omitempty"`     // id of the account that uploaded the object
+	VersionID      string `json:"versionId,omitempty"` // empty unless the bucket is versioned
+	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+
+	ServerSideEncryption string `json:"serverSideEncryption,omitempty"` // AES256, kms or empty if not encrypted
+	SSEKMSKeyID          string `json:"sseKmsKeyId,omitempty"`
+	SSECustomerKeyMD5    string `json:"sseCustomerKeyMd5,omitempty"`
+}
+
+// Encrypted object is encrypted at rest
+func (f *FileInfo) Encrypted() bool {
+	return f.ServerSideEncryption != "" || f.SSECustomerKeyMD5 != ""
 }
 
 // ListFilesRequest 列举文件请求
@@ -128,6 +402,14 @@ type ListFilesRequest struct {
 	Marker    string `json:"marker"`
 	Delimiter string `json:"delimiter"`
 	Limit     int    `json:"limit"`
+	// DirectoriesOnly return only Directories, Delimiter defaults to "/"
+	DirectoriesOnly bool `json:"directoriesOnly,omitempty"`
+	// SortBy order of Files, default SortByName ascending
+	SortBy     ListSortKey `json:"sortBy,omitempty"`
+	Descending bool        `json:"descending,omitempty"`
+	// Fields FileInfo attributes to return, see the Field constants; the key is
+	// always returned and empty returns every attribute
+	Fields []string `json:"fields,omitempty"`
 }
 
 // ListFilesResult 列举文件结果
@@ -138,18 +420,54 @@ type ListFilesResult struct {
 	IsTruncated bool       `json:"isTruncated"`
 }
 
+// ListBucketsRequest 列举存储桶请求
+type ListBucketsRequest struct {
+	TagCondition string `json:"tagCondition"`
+	Shared       bool   `json:"shared"`
+	Marker       string `json:"marker"` // start after this bucket name
+	Limit        int    `json:"limit"`  // page size, 0 uses the server default
+}
+
+// ListBucketsResult 列举存储桶结果
+type ListBucketsResult struct {
+	Buckets     []string `json:"buckets"`
+	NextMarker  string   `json:"nextMarker"`
+	IsTruncated bool     `json:"isTruncated"`
+}
+
 // CreateBucketRequest 创建存储桶请求
 type CreateBucketRequest struct {
 	BucketName string `json:"bucketName"`
 	Region     string `json:"region"`
+
+	IsPrivate    bool              `json:"isPrivate,omitempty"`
+	Quota        int64             `json:"quota,omitempty"`        // max stored bytes, 0 means unlimited
+	StorageClass string            `json:"storageClass,omitempty"` // default class of uploads without one
+	Tags         map[string]string `json:"tags,omitempty"`
+	Versioning   bool              `json:"versioning,omitempty"`
 }
 
+// MetadataDirective whether a copy keeps the source attributes or replaces them
+type MetadataDirective string
+
+const (
+	// MetadataDirectiveCopy keep the source metadata and content type, the default
+	MetadataDirectiveCopy MetadataDirective = "COPY"
+	// MetadataDirectiveReplace use the metadata and content type of the copy request
+	MetadataDirectiveReplace MetadataDirective = "REPLACE"
+)
+
 // CopyFileRequest 复制文件请求
 type CopyFileRequest struct {
 	SrcBucket  string `json:"srcBucket"`
 	SrcKey     string `json:"srcKey"`
 	DestBucket string `json:"destBucket"`
 	DestKey    string `json:"destKey"`
+
+	MetadataDirective MetadataDirective `json:"metadataDirective,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`     // requires MetadataDirectiveReplace
+	ContentType       string            `json:"contentType,omitempty"`  // requires MetadataDirectiveReplace
+	StorageClass      string            `json:"storageClass,omitempty"` // empty keeps the source class
 }
 
 // MoveFileRequest 移动文件请求
@@ -158,6 +476,11 @@ type MoveFileRequest struct {
 	SrcKey     string `json:"srcKey"`
 	DestBucket string `json:"destBucket"`
 	DestKey    string `json:"destKey"`
+
+	// FailIfDestExists fail with ErrDestinationExists instead of overwriting the destination
+	FailIfDestExists bool `json:"failIfDestExists,omitempty"`
+	// OnlyIfSourceETagMatches move only the source version with this ETag, else ErrSourceChanged
+	OnlyIfSourceETagMatches string `json:"ifSourceMatch,omitempty"`
 }
 
 // SetBucketPrivateRequest 设置存储桶权限请求
@@ -176,6 +499,20 @@ type UploadResult struct {
 	Compressed   bool   `json:"compressed"`
 	Watermarked  bool   `json:"watermarked"`
 	URL          string `json:"url"`
+	Width        int    `json:"width,omitempty"`  // image width after processing
+	Height       int    `json:"height,omitempty"` // image height after processing
+	Format       string `json:"format,omitempty"` // image format after processing
+
+	ETag           string `json:"etag,omitempty"`           // server computed MD5 of the stored object
+	ChecksumSHA256 string `json:"checksumSha256,omitempty"` // server computed SHA-256, hex encoded
+	VersionID      string `json:"versionId,omitempty"`      // empty unless the bucket is versioned
+	StorageClass   string `json:"storageClass,omitempty"`
+	ContentType    string `json:"contentType,omitempty"`
+
+	Moderation  *ModerationResult `json:"moderation,omitempty"`  // set when ModerationPolicy is requested
+	MalwareScan *ScanResult       `json:"malwareScan,omitempty"` // set when ScanMalware is requested, usually pending
+
+	Timing *Timing `json:"-"` // set when Config.CollectTiming is enabled
 }
 
 // UploadError upload error
@@ -189,11 +526,13 @@ type BatchUploadResult struct {
 	Success []UploadResult `json:"success"`
 	Failed  []UploadError  `json:"failed"`
 	Total   int            `json:"total"`
+	Skipped []SkippedFile  `json:"skipped,omitempty"` // entries of a directory upload left out
 }
 
 // APIError API Error
 type APIError struct {
 	StatusCode int    `json:"statusCode"`
+	Code       int    `json:"code"` // application code of code/msg responses
 	Message    string `json:"message"`
 	Details    string `json:"details"`
 }
@@ -202,7 +541,9 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("ling storage api error %d: %s", e.StatusCode, e.Message)
 }
 
-// UploadFile upload single files
+// UploadFile upload single files. The file is streamed from disk, so memory
+// use does not grow with the file size; with SignRequests or a DataTransport
+// the request body is buffered in memory instead
 func (c *Client) UploadFile(req *UploadRequest) (*UploadResult, error) {
 	file, err := os.Open(req.FilePath)
 	if err != nil {
@@ -213,12 +554,19 @@ func (c *Client) UploadFile(req *UploadRequest) (*UploadResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
+	if req.PreserveAttributes {
+		req = withFileAttributes(req, fileInfo)
+	}
+	if c.config.DataTransport == nil && !c.config.SignRequests && fileInfo.Mode().IsRegular() {
+		return c.uploadFile(file, filepath.Base(req.FilePath), fileInfo.Size(), req)
+	}
 	var reader io.Reader = file
-	if req.OnProgress != nil {
+	if req.OnProgress != nil || req.OnProgressCheck != nil {
 		reader = &progressReader{
 			reader:   file,
 			total:    fileInfo.Size(),
 			callback: req.OnProgress,
+			check:    req.OnProgressCheck,
 		}
 	}
 
@@ -236,11 +584,12 @@ func (c *Client) UploadFromReader(req *UploadFromReaderRequest) (*UploadResult,
 		}
 	}
 	var reader io.Reader = req.Reader
-	if req.OnProgress != nil && size > 0 {
+	if (req.OnProgress != nil && size > 0) || req.OnProgressCheck != nil {
 		reader = &progressReader{
 			reader:   req.Reader,
 			total:    size,
 			callback: req.OnProgress,
+			check:    req.OnProgressCheck,
 		}
 	}
 	uploadReq := &UploadRequest{
@@ -252,6 +601,22 @@ func (c *Client) UploadFromReader(req *UploadFromReaderRequest) (*UploadResult,
 		Watermark:         req.Watermark,
 		WatermarkText:     req.WatermarkText,
 		WatermarkPosition: req.WatermarkPosition,
+		WatermarkImageKey: req.WatermarkImageKey,
+		WatermarkOpacity:  req.WatermarkOpacity,
+		WatermarkScale:    req.WatermarkScale,
+		WatermarkMargin:   req.WatermarkMargin,
+		Resize:            req.Resize,
+		Format:            req.Format,
+		AutoOrient:        req.AutoOrient,
+		StripEXIF:         req.StripEXIF,
+		ModerationPolicy:  req.ModerationPolicy,
+		ScanMalware:       req.ScanMalware,
+		Metadata:          req.Metadata,
+		SSEAlgorithm:      req.SSEAlgorithm,
+		SSEKMSKeyID:       req.SSEKMSKeyID,
+		SSECustomerKey:    req.SSECustomerKey,
+		IfMatch:           req.IfMatch,
+		IfNoneMatch:       req.IfNoneMatch,
 	}
 
 	return c.uploadReader(reader, req.Filename, size, uploadReq)
@@ -261,11 +626,12 @@ func (c *Client) UploadFromReader(req *UploadFromReaderRequest) (*UploadResult,
 func (c *Client) UploadBytes(req *UploadBytesRequest) (*UploadResult, error) {
 	reader := bytes.NewReader(req.Data)
 	var readerWithProgress io.Reader = reader
-	if req.OnProgress != nil {
+	if req.OnProgress != nil || req.OnProgressCheck != nil {
 		readerWithProgress = &progressReader{
 			reader:   reader,
 			total:    int64(len(req.Data)),
 			callback: req.OnProgress,
+			check:    req.OnProgressCheck,
 		}
 	}
 
@@ -278,22 +644,72 @@ func (c *Client) UploadBytes(req *UploadBytesRequest) (*UploadResult, error) {
 		Watermark:         req.Watermark,
 		WatermarkText:     req.WatermarkText,
 		WatermarkPosition: req.WatermarkPosition,
+		WatermarkImageKey: req.WatermarkImageKey,
+		WatermarkOpacity:  req.WatermarkOpacity,
+		WatermarkScale:    req.WatermarkScale,
+		WatermarkMargin:   req.WatermarkMargin,
+		Resize:            req.Resize,
+		Format:            req.Format,
+		AutoOrient:        req.AutoOrient,
+		StripEXIF:         req.StripEXIF,
+		ModerationPolicy:  req.ModerationPolicy,
+		ScanMalware:       req.ScanMalware,
+		Metadata:          req.Metadata,
+		SSEAlgorithm:      req.SSEAlgorithm,
+		SSEKMSKeyID:       req.SSEKMSKeyID,
+		SSECustomerKey:    req.SSECustomerKey,
+		IfMatch:           req.IfMatch,
+		IfNoneMatch:       req.IfNoneMatch,
 	}
 
 	return c.uploadReader(readerWithProgress, req.Filename, int64(len(req.Data)), uploadReq)
 }
 
 // BatchUpload batch upload files
-func (c *Client) BatchUpload(req *BatchUploadRequest) (*BatchUploadResult, error) {
+func (c *Client) BatchUpload(req *BatchUploadRequest) (_ *BatchUploadResult, err error) {
+	ctx, op := c.startOperation("BatchUpload", req.Bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	files, err := expandFiles(req.Files, req.Patterns, req.Exclude)
+	if err != nil {
+		return nil, err
+	}
 	result := &BatchUploadResult{
 		Success: make([]UploadResult, 0),
 		Failed:  make([]UploadError, 0),
-		Total:   len(req.Files),
+		Total:   len(files),
+	}
+	if req.Progress != nil {
+		var totalBytes int64
+		for _, filePath := range files {
+			if info, err := os.Stat(filePath); err == nil {
+				totalBytes += info.Size()
+			}
+		}
+		req.Progress.SetTotal(len(files), totalBytes)
 	}
 
-	for i, filePath := range req.Files {
+	var uploaded []string
+	for i, filePath := range files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
 		if req.OnProgress != nil {
-			req.OnProgress(i, len(req.Files), filePath)
+			req.OnProgress(i, len(files), filePath)
+		}
+		onFileProgress := req.OnFileProgress
+		var tracker *ProgressTracker
+		if req.Progress != nil {
+			var size int64
+			if info, err := os.Stat(filePath); err == nil {
+				size = info.Size()
+			}
+			tracker = req.Progress.Track(filePath, size)
+			onFileProgress = func(uploaded, total int64) {
+				tracker.OnProgress(uploaded, total)
+				if req.OnFileProgress != nil {
+					req.OnFileProgress(uploaded, total)
+				}
+			}
 		}
 		uploadReq := &UploadRequest{
 			FilePath:          filePath,
@@ -304,13 +720,32 @@ func (c *Client) BatchUpload(req *BatchUploadRequest) (*BatchUploadResult, error
 			Watermark:         req.Watermark,
 			WatermarkText:     req.WatermarkText,
 			WatermarkPosition: req.WatermarkPosition,
-			OnProgress:        req.OnFileProgress,
+			WatermarkImageKey: req.WatermarkImageKey,
+			WatermarkOpacity:  req.WatermarkOpacity,
+			WatermarkScale:    req.WatermarkScale,
+			WatermarkMargin:   req.WatermarkMargin,
+			Resize:            req.Resize,
+			Format:            req.Format,
+			AutoOrient:        req.AutoOrient,
+			StripEXIF:         req.StripEXIF,
+			ModerationPolicy:  req.ModerationPolicy,
+			ScanMalware:       req.ScanMalware,
+			SSEAlgorithm:      req.SSEAlgorithm,
+			SSEKMSKeyID:       req.SSEKMSKeyID,
+			SSECustomerKey:    req.SSECustomerKey,
+			OnProgress:        onFileProgress,
+			OnProgressCheck:   req.OnFileProgressCheck,
+
+			PreserveAttributes: req.PreserveAttributes,
 		}
 		if req.KeyPrefix != "" {
 			filename := filepath.Base(filePath)
 			uploadReq.Key = req.KeyPrefix + "/" + filename
 		}
-		uploadResult, err := c.UploadFile(uploadReq)
+		uploadResult, err := c.WithContext(ctx).UploadFile(uploadReq)
+		if tracker != nil {
+			tracker.Done(err)
+		}
 		if err != nil {
 			result.Failed = append(result.Failed, UploadError{
 				File:  filePath,
@@ -318,46 +753,32 @@ func (c *Client) BatchUpload(req *BatchUploadRequest) (*BatchUploadResult, error
 			})
 		} else {
 			result.Success = append(result.Success, *uploadResult)
+			uploaded = append(uploaded, filePath)
 		}
 	}
 	if req.OnProgress != nil {
-		req.OnProgress(len(req.Files), len(req.Files), "")
+		req.OnProgress(len(files), len(files), "")
+	}
+	if req.Progress != nil {
+		req.Progress.Finish()
+	}
+	if req.ManifestPath != "" {
+		if err := writeManifest(req.ManifestPath, manifestEntries(uploaded, result.Success)); err != nil {
+			return result, err
+		}
 	}
 
 	return result, nil
 }
 
 // Ping check server if is alive
-func (c *Client) Ping() error {
-	url := strings.TrimRight(c.config.BaseURL, "/")
+func (c *Client) Ping() (err error) {
+	ctx, op := c.startOperation("Ping", "", "")
+	defer func() { c.endOperation(op, err) }()
 
-	httpReq, err := http.NewRequest("HEAD", url, nil)
+	resp, err := c.send(ctx, "HEAD", "", nil)
 	if err != nil {
-		return fmt.Errorf("failed to create ping request: %w", err)
-	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
-	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
-	}
-
-	var resp *http.Response
-	var lastErr error
-	for i := 0; i <= c.config.RetryCount; i++ {
-		resp, lastErr = c.httpClient.Do(httpReq)
-		if lastErr == nil && resp.StatusCode < 500 {
-			break
-		}
-		if i < c.config.RetryCount {
-			time.Sleep(time.Duration(i+1) * time.Second)
-		}
-	}
-
-	if lastErr != nil {
-		return fmt.Errorf("ping request failed after %d retries: %w", c.config.RetryCount, lastErr)
+		return fmt.Errorf("ping %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -369,133 +790,72 @@ func (c *Client) Ping() error {
 }
 
 // DeleteFile 删除文件
-func (c *Client) DeleteFile(bucket, key string) error {
-	url := fmt.Sprintf("%s/api/public/files/%s/%s", strings.TrimRight(c.config.BaseURL, "/"), bucket, key)
-
-	httpReq, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
-	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
-	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
-	}
-
-	return nil
+func (c *Client) DeleteFile(bucket, key string) (err error) {
+	ctx, op := c.startOperation("DeleteFile", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	defer c.metadata.invalidate(bucket, key)
+	return c.call(ctx, "DELETE", objectPath(bucket, key), nil, nil)
 }
 
-// GetFileURL 获取文件访问URL
-func (c *Client) GetFileURL(bucket, key string, expires time.Duration) (string, error) {
-	url := fmt.Sprintf("%s/api/public/files/%s/%s/url", strings.TrimRight(c.config.BaseURL, "/"), bucket, key)
+// URLOption optional query parameter of GetFileURL
+type URLOption func(q url.Values)
 
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+// GetFileURL 获取文件访问URL
+func (c *Client) GetFileURL(bucket, key string, expires time.Duration, opts ...URLOption) (_ string, err error) {
+	ctx, op := c.startOperation("GetFileURL", bucket, key)
+	defer func() { c.endOperation(op, err) }()
 
 	// 添加过期时间参数
+	q := url.Values{}
 	if expires > 0 {
-		q := httpReq.URL.Query()
 		q.Set("expires", expires.String())
-		httpReq.URL.RawQuery = q.Encode()
 	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	for _, opt := range opts {
+		opt(q)
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
-	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+	if err := validateURLOptions(q); err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", c.handleErrorResponse(resp)
+	path := withQuery(objectPath(bucket, key)+"/url", q)
+	if cached, ok := c.metadata.get("url\x00" + path); ok {
+		return cached.(string), nil
 	}
 
-	var apiResp struct {
-		Success bool `json:"success"`
-		Data    struct {
-			URL string `json:"url"`
-		} `json:"data"`
+	var data struct {
+		URL string `json:"url"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if err := c.call(ctx, "GET", path, nil, &data); err != nil {
+		return "", err
 	}
-
-	return apiResp.Data.URL, nil
+	// a cached URL must stay valid for a while after it is served
+	c.metadata.put(bucket, key, "url\x00"+path, data.URL, expires/2)
+	return data.URL, nil
 }
 
 // GetFileInfo 获取文件信息
-func (c *Client) GetFileInfo(bucket, key string) (*FileInfo, error) {
-	url := fmt.Sprintf("%s/api/public/files/%s/%s/info", strings.TrimRight(c.config.BaseURL, "/"), bucket, key)
-
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
-	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
-	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+func (c *Client) GetFileInfo(bucket, key string) (_ *FileInfo, err error) {
+	ctx, op := c.startOperation("GetFileInfo", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	path := objectPath(bucket, key) + "/info"
+	if cached, ok := c.metadata.get("info\x00" + path); ok {
+		info := cached.(FileInfo)
+		return &info, nil
+	}
+	var info FileInfo
+	if err := c.call(ctx, "GET", path, nil, &info); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var apiResp struct {
-		Success bool     `json:"success"`
-		Data    FileInfo `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return &apiResp.Data, nil
+	c.metadata.put(bucket, key, "info\x00"+path, info, 0)
+	return &info, nil
 }
 
 // ListFiles 列举文件
-func (c *Client) ListFiles(req *ListFilesRequest) (*ListFilesResult, error) {
-	url := fmt.Sprintf("%s/api/public/buckets/%s/files", strings.TrimRight(c.config.BaseURL, "/"), req.Bucket)
-
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+func (c *Client) ListFiles(req *ListFilesRequest) (_ *ListFilesResult, err error) {
+	ctx, op := c.startOperation("ListFiles", req.Bucket, "")
+	defer func() { c.endOperation(op, err) }()
 
 	// 添加查询参数
-	q := httpReq.URL.Query()
+	q := url.Values{}
 	if req.Prefix != "" {
 		q.Set("prefix", req.Prefix)
 	}
@@ -508,323 +868,299 @@ func (c *Client) ListFiles(req *ListFilesRequest) (*ListFilesResult, error) {
 	if req.Limit > 0 {
 		q.Set("limit", strconv.Itoa(req.Limit))
 	}
-	httpReq.URL.RawQuery = q.Encode()
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
-	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if req.DirectoriesOnly {
+		if req.Delimiter == "" {
+			q.Set("delimiter", "/")
+		}
+		q.Set("directoriesOnly", "true")
 	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+	if err := setListOrder(q, req); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
+	var result ListFilesResult
+	if err := c.call(ctx, "GET", withQuery(bucketPath(req.Bucket)+"/files", q), nil, &result); err != nil {
+		return nil, err
 	}
-
-	var apiResp struct {
-		Success bool            `json:"success"`
-		Data    ListFilesResult `json:"data"`
+	if req.DirectoriesOnly {
+		// older servers ignore directoriesOnly and still return the files
+		result.Files = nil
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if !result.IsTruncated && (req.SortBy != "" || req.Descending) {
+		// a complete listing is sorted here too, for servers ignoring sort
+		sortFiles(result.Files, req.SortBy, req.Descending)
 	}
-
-	return &apiResp.Data, nil
+	return &result, nil
 }
 
-// ListBuckets 列举存储桶
+// ListBuckets 列举存储桶，自动翻页返回全部存储桶
 func (c *Client) ListBuckets(tagCondition string, shared bool) ([]string, error) {
-	url := fmt.Sprintf("%s/api/public/buckets", strings.TrimRight(c.config.BaseURL, "/"))
-
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var buckets []string
+	it := c.IterateBuckets(&ListBucketsRequest{TagCondition: tagCondition, Shared: shared})
+	for it.Next() {
+		buckets = append(buckets, it.Bucket())
 	}
+	return buckets, it.Err()
+}
+
+// ListBucketsPage 列举一页存储桶
+func (c *Client) ListBucketsPage(req *ListBucketsRequest) (_ *ListBucketsResult, err error) {
+	ctx, op := c.startOperation("ListBuckets", "", "")
+	defer func() { c.endOperation(op, err) }()
 
 	// 添加查询参数
-	q := httpReq.URL.Query()
-	if tagCondition != "" {
-		q.Set("tagCondition", tagCondition)
+	q := url.Values{}
+	if req.TagCondition != "" {
+		q.Set("tagCondition", req.TagCondition)
 	}
-	if shared {
+	if req.Shared {
 		q.Set("shared", "true")
 	}
-	httpReq.URL.RawQuery = q.Encode()
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	if req.Marker != "" {
+		q.Set("marker", req.Marker)
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
 	}
 
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+	var result ListBucketsResult
+	if err := c.call(ctx, "GET", withQuery("/api/public/buckets", q), nil, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
-	}
-
-	var apiResp struct {
-		Success bool `json:"success"`
-		Data    struct {
-			Buckets []string `json:"buckets"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return apiResp.Data.Buckets, nil
+	return &result, nil
 }
 
 // CreateBucket 创建存储桶
-func (c *Client) CreateBucket(req *CreateBucketRequest) error {
-	url := fmt.Sprintf("%s/api/public/buckets", strings.TrimRight(c.config.BaseURL, "/"))
-
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set(constants.CONETENT_TYPE, "application/json")
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+func (c *Client) CreateBucket(req *CreateBucketRequest) (err error) {
+	ctx, op := c.startOperation("CreateBucket", req.BucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	if c.config.Region != "" {
+		if req.Region == "" {
+			withRegion := *req
+			withRegion.Region = c.config.Region
+			req = &withRegion
+		} else if req.Region != c.config.Region {
+			return fmt.Errorf("%w: cannot create %s in %s, client targets %s", ErrRegionMismatch, req.BucketName, req.Region, c.config.Region)
+		}
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if req.Quota < 0 {
+		return fmt.Errorf("invalid bucket quota %d", req.Quota)
 	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
-		return err
+	// servers without the feature would silently create the bucket without it
+	if len(req.Tags) > 0 {
+		if err := c.requireFeature(FeatureTags); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
+	if req.Versioning {
+		if err := c.requireFeature(FeatureVersioning); err != nil {
+			return err
+		}
 	}
-
-	return nil
+	return c.call(ctx, "POST", "/api/public/buckets", req, nil)
 }
 
 // DeleteBucket 删除存储桶
-func (c *Client) DeleteBucket(bucketName string) error {
-	url := fmt.Sprintf("%s/api/public/buckets/%s", strings.TrimRight(c.config.BaseURL, "/"), bucketName)
+func (c *Client) DeleteBucket(bucketName string) (err error) {
+	ctx, op := c.startOperation("DeleteBucket", bucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	return c.call(ctx, "DELETE", bucketPath(bucketName), nil, nil)
+}
 
-	httpReq, err := http.NewRequest("DELETE", url, nil)
+// GetBucketDomains 获取存储桶域名
+func (c *Client) GetBucketDomains(bucketName string) ([]string, error) {
+	info, err := c.fetchBucketDomains(bucketName)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
-	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+		return nil, err
 	}
+	return info.domains, nil
+}
 
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+// SetBucketPrivate 设置存储桶权限
+func (c *Client) SetBucketPrivate(req *SetBucketPrivateRequest) (err error) {
+	ctx, op := c.startOperation("SetBucketPrivate", req.BucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	body := map[string]bool{"isPrivate": req.IsPrivate}
+	if err := c.call(ctx, "PUT", bucketPath(req.BucketName)+"/private", body, nil); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
-	}
-
+	c.InvalidateDomainCache(req.BucketName)
 	return nil
 }
 
-// GetBucketDomains 获取存储桶域名
-func (c *Client) GetBucketDomains(bucketName string) ([]string, error) {
-	url := fmt.Sprintf("%s/api/public/buckets/%s/domains", strings.TrimRight(c.config.BaseURL, "/"), bucketName)
-
-	httpReq, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// CopyFile 复制文件
+func (c *Client) CopyFile(req *CopyFileRequest) (err error) {
+	ctx, op := c.startOperation("CopyFile", req.SrcBucket, req.SrcKey)
+	defer func() { c.endOperation(op, err) }()
+	defer c.metadata.invalidate(req.DestBucket, req.DestKey)
+	if err := validateCopyRequest(req); err != nil {
+		return err
 	}
-
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	if c.config.MultipartCopyThreshold > 0 {
+		info, err := c.sourceInfo(ctx, req)
+		if err != nil {
+			return err
+		}
+		if info.Size > c.config.MultipartCopyThreshold {
+			return c.copyMultipart(ctx, req, info)
+		}
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	err = c.copyObject(ctx, req)
+	if c.config.MultipartCopyThreshold == 0 && isTooLarge(err) {
+		info, err := c.sourceInfo(ctx, req)
+		if err != nil {
+			return err
+		}
+		return c.copyMultipart(ctx, req, info)
 	}
+	return err
+}
 
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
-		return nil, err
+// copyObject copy in a single request
+func (c *Client) copyObject(ctx context.Context, req *CopyFileRequest) error {
+	body := map[string]interface{}{
+		"destBucket": req.DestBucket,
+		"destKey":    req.DestKey,
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
+	if req.MetadataDirective != "" {
+		body["metadataDirective"] = req.MetadataDirective
 	}
-
-	var apiResp struct {
-		Success bool `json:"success"`
-		Data    struct {
-			Domains []string `json:"domains"`
-		} `json:"data"`
+	if req.MetadataDirective == MetadataDirectiveReplace {
+		// an empty map clears the metadata instead of keeping the source one
+		metadata := req.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		body["metadata"] = metadata
+		if req.ContentType != "" {
+			body["contentType"] = req.ContentType
+		}
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if req.StorageClass != "" {
+		body["storageClass"] = req.StorageClass
 	}
-
-	return apiResp.Data.Domains, nil
+	return c.call(ctx, "POST", objectPath(req.SrcBucket, req.SrcKey)+"/copy", body, nil)
 }
 
-// SetBucketPrivate 设置存储桶权限
-func (c *Client) SetBucketPrivate(req *SetBucketPrivateRequest) error {
-	url := fmt.Sprintf("%s/api/public/buckets/%s/private", strings.TrimRight(c.config.BaseURL, "/"), req.BucketName)
-
-	jsonData, err := json.Marshal(map[string]bool{"isPrivate": req.IsPrivate})
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+// validateCopyRequest check the directive and the attributes it allows
+func validateCopyRequest(req *CopyFileRequest) error {
+	switch req.MetadataDirective {
+	case "", MetadataDirectiveCopy:
+		if len(req.Metadata) > 0 || req.ContentType != "" {
+			return errors.New("metadata and content type of a copy require MetadataDirectiveReplace")
+		}
+		// copying an object onto itself must change something
+		if req.SrcBucket == req.DestBucket && req.SrcKey == req.DestKey && req.StorageClass == "" {
+			return errors.New("copy onto itself requires MetadataDirectiveReplace or a new storage class")
+		}
+	case MetadataDirectiveReplace:
+	default:
+		return fmt.Errorf("unsupported metadata directive %q", req.MetadataDirective)
 	}
+	return nil
+}
 
-	httpReq, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// MoveFile 移动文件
+func (c *Client) MoveFile(req *MoveFileRequest) (err error) {
+	ctx, op := c.startOperation("MoveFile", req.SrcBucket, req.SrcKey)
+	defer func() { c.endOperation(op, err) }()
+	defer c.metadata.invalidate(req.SrcBucket, req.SrcKey)
+	defer c.metadata.invalidate(req.DestBucket, req.DestKey)
+	body := map[string]interface{}{
+		"destBucket": req.DestBucket,
+		"destKey":    req.DestKey,
 	}
-
-	httpReq.Header.Set(constants.CONETENT_TYPE, "application/json")
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	if req.FailIfDestExists {
+		body["failIfDestExists"] = true
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if req.OnlyIfSourceETagMatches != "" {
+		body["ifSourceMatch"] = req.OnlyIfSourceETagMatches
 	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
+	err = c.call(ctx, "POST", objectPath(req.SrcBucket, req.SrcKey)+"/move", body, nil)
 	if err != nil {
-		return err
+		return c.moveError(ctx, req, err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
-	}
-
 	return nil
 }
 
-// CopyFile 复制文件
-func (c *Client) CopyFile(req *CopyFileRequest) error {
-	url := fmt.Sprintf("%s/api/public/files/%s/%s/copy", strings.TrimRight(c.config.BaseURL, "/"), req.SrcBucket, req.SrcKey)
-
-	jsonData, err := json.Marshal(map[string]string{
-		"destBucket": req.DestBucket,
-		"destKey":    req.DestKey,
-	})
+// uploadReader common upload method
+func (c *Client) uploadReader(reader io.Reader, filename string, size int64, req *UploadRequest) (_ *UploadResult, err error) {
+	filename, req, err = c.sanitizeUpload(filename, req)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
-
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	ctx, op := c.startOperation("Upload", req.Bucket, req.Key)
+	defer func() { c.endOperation(op, err) }()
+	if reader, err = c.runPreUploadHooks(req, filename, reader); err != nil {
+		return nil, err
 	}
-
-	httpReq.Header.Set(constants.CONETENT_TYPE, "application/json")
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	if reader, err = c.checkUploadSize(filename, size, reader); err != nil {
+		return nil, err
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if err := validateUploadRequest(req); err != nil {
+		return nil, err
 	}
-
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
-		return err
+	// a canceled context stops the upload while the body is still being read
+	reader = &progressReader{reader: reader, total: size, ctx: ctx}
+	if c.config.DataTransport != nil {
+		if err := c.validateNames(op); err != nil {
+			return nil, err
+		}
+		return c.uploadWithTransport(ctx, reader, filename, size, req)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
+	// the encoded form lives in a pooled buffer, recycled once the request is done
+	pooled := &pooledBody{buf: getBuffer()}
+	defer pooled.release()
+	buf := pooled.buf
+	writer := multipart.NewWriter(buf)
+	fileWriter, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
 
-	return nil
-}
-
-// MoveFile 移动文件
-func (c *Client) MoveFile(req *MoveFileRequest) error {
-	url := fmt.Sprintf("%s/api/public/files/%s/%s/move", strings.TrimRight(c.config.BaseURL, "/"), req.SrcBucket, req.SrcKey)
-
-	jsonData, err := json.Marshal(map[string]string{
-		"destBucket": req.DestBucket,
-		"destKey":    req.DestKey,
-	})
+	copied, err := copyBuffered(fileWriter, reader)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to copy file data: %w", err)
 	}
-
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err := writeUploadFields(writer, req); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL("/api/public/upload"), nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-
-	httpReq.Header.Set(constants.CONETENT_TYPE, "application/json")
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	httpReq.Body = pooled.body()
+	httpReq.GetBody = pooled.getBody
+	httpReq.ContentLength = int64(buf.Len())
+	httpReq.Header.Set(constants.CONTENT_TYPE, writer.FormDataContentType())
+	if err := c.setHeaders(httpReq, buf.Bytes()); err != nil {
+		return nil, err
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	result, err := c.sendUpload(ctx, httpReq, req)
+	if err != nil {
+		return nil, err
 	}
+	// recorded once the upload succeeded, failed uploads and retries are not counted
+	c.recordBytes(ctx, DirectionUpload, copied)
+	return result, nil
+}
 
-	resp, err := c.doRequestWithRetry(httpReq)
-	if err != nil {
+// validateUploadRequest check processing options before anything is sent
+func validateUploadRequest(req *UploadRequest) error {
+	if err := validateSSE(req.SSEAlgorithm, req.SSECustomerKey); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return c.handleErrorResponse(resp)
+	if err := validateImageOptions(req.Resize, req.Format); err != nil {
+		return err
 	}
-
-	return nil
-}
-
-// uploadReader common upload method
-func (c *Client) uploadReader(reader io.Reader, filename string, size int64, req *UploadRequest) (*UploadResult, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-	fileWriter, err := writer.CreateFormFile("file", filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
+	if err := validateWatermark(req.WatermarkOpacity, req.WatermarkScale, req.WatermarkMargin); err != nil {
+		return err
 	}
+	return validateModerationPolicy(req.ModerationPolicy)
+}
 
-	_, err = io.Copy(fileWriter, reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file data: %w", err)
-	}
+// writeUploadFields write the form fields following the file part and close the form
+func writeUploadFields(writer *multipart.Writer, req *UploadRequest) error {
 	if req.Bucket != "" {
 		writer.WriteField("bucket", req.Bucket)
 	}
@@ -837,7 +1173,7 @@ func (c *Client) uploadReader(reader io.Reader, filename string, size int64, req
 			writer.WriteField("quality", strconv.Itoa(req.Quality))
 		}
 	}
-	if req.Watermark {
+	if req.Watermark || req.WatermarkImageKey != "" {
 		writer.WriteField("watermark", "true")
 		if req.WatermarkText != "" {
 			writer.WriteField("watermarkText", req.WatermarkText)
@@ -845,21 +1181,44 @@ func (c *Client) uploadReader(reader io.Reader, filename string, size int64, req
 		if req.WatermarkPosition != "" {
 			writer.WriteField("watermarkPosition", req.WatermarkPosition)
 		}
+		if req.WatermarkImageKey != "" {
+			writer.WriteField("watermarkImageKey", req.WatermarkImageKey)
+		}
+		if req.WatermarkOpacity > 0 {
+			writer.WriteField("watermarkOpacity", strconv.Itoa(req.WatermarkOpacity))
+		}
+		if req.WatermarkScale > 0 {
+			writer.WriteField("watermarkScale", strconv.FormatFloat(req.WatermarkScale, 'f', -1, 64))
+		}
+		if req.WatermarkMargin > 0 {
+			writer.WriteField("watermarkMargin", strconv.Itoa(req.WatermarkMargin))
+		}
 	}
-	writer.Close()
-	url := strings.TrimRight(c.config.BaseURL, "/") + "/api/public/upload"
-	httpReq, err := http.NewRequest("POST", url, &buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	writeImageFields(writer, req)
+	if req.ModerationPolicy != nil {
+		policy, err := json.Marshal(req.ModerationPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to marshal moderation policy: %w", err)
+		}
+		writer.WriteField("moderationPolicy", string(policy))
 	}
-	httpReq.Header.Set(constants.CONETENT_TYPE, writer.FormDataContentType())
-	httpReq.Header.Set(constants.USER_AGENT, c.config.UserAgent)
-	if c.config.APIKey != "" {
-		httpReq.Header.Set(constants.XAPIKEY, c.config.APIKey)
+	if req.ScanMalware {
+		writer.WriteField("scanMalware", "true")
 	}
-	if c.config.APISecret != "" {
-		httpReq.Header.Set(constants.XAPISECRET, c.config.APISecret)
+	if len(req.Metadata) > 0 {
+		metadata, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		writer.WriteField("metadata", string(metadata))
 	}
+	return writer.Close()
+}
+
+// sendUpload send the encoded upload request and parse the result
+func (c *Client) sendUpload(ctx context.Context, httpReq *http.Request, req *UploadRequest) (*UploadResult, error) {
+	setSSEHeaders(httpReq.Header, req.SSEAlgorithm, req.SSEKMSKeyID, req.SSECustomerKey)
+	setConditionHeaders(httpReq.Header, req.IfMatch, req.IfNoneMatch)
 	if len(req.AllowedTypes) > 0 {
 		q := httpReq.URL.Query()
 		for _, t := range req.AllowedTypes {
@@ -867,115 +1226,179 @@ func (c *Client) uploadReader(reader io.Reader, filename string, size int64, req
 		}
 		httpReq.URL.RawQuery = q.Encode()
 	}
-	var resp *http.Response
-	var lastErr error
-	for i := 0; i <= c.config.RetryCount; i++ {
-		resp, lastErr = c.httpClient.Do(httpReq)
-		if lastErr == nil && resp.StatusCode < 500 {
-			break
-		}
-		if i < c.config.RetryCount {
-			time.Sleep(time.Duration(i+1) * time.Second)
-		}
-	}
-	if lastErr != nil {
-		return nil, fmt.Errorf("request failed after %d retries: %w", c.config.RetryCount, lastErr)
-	}
-	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
+	defer c.metadata.invalidate(req.Bucket, req.Key)
+	resp, err := c.doRequestWithRetry(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if json.Unmarshal(respBody, &apiErr) == nil {
-			apiErr.StatusCode = resp.StatusCode
-			return nil, &apiErr
-		}
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    string(respBody),
-		}
+		return nil, c.handleErrorResponse(resp)
 	}
-	var apiResp struct {
-		Success bool         `json:"success"`
-		Message string       `json:"message"`
-		Data    UploadResult `json:"data"`
-		Code    int          `json:"code"`
-		Msg     string       `json:"msg"`
+	var result UploadResult
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(respBody, &apiResp)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	fillUploadResultFromHeader(&result, resp.Header)
+	c.metadata.invalidate(result.Bucket, result.Key)
+	result.Timing = operationTiming(ctx)
+	return &result, nil
+}
+
+// fillUploadResultFromHeader take object attributes the body left out from the response headers
+func fillUploadResultFromHeader(result *UploadResult, header http.Header) {
+	if result.ETag == "" {
+		result.ETag = strings.Trim(header.Get(constants.ETAG), `"`)
 	}
-	isSuccess := apiResp.Success || (apiResp.Code == 200)
-	if !isSuccess {
-		message := apiResp.Message
-		if message == "" {
-			message = apiResp.Msg
-		}
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    message,
-		}
+	if result.ChecksumSHA256 == "" {
+		result.ChecksumSHA256 = header.Get(constants.XCHECKSUMSHA256)
+	}
+	if result.VersionID == "" {
+		result.VersionID = header.Get(constants.XVERSIONID)
+	}
+	if result.StorageClass == "" {
+		result.StorageClass = header.Get(constants.XSTORAGECLASS)
 	}
-	return &apiResp.Data, nil
 }
 
-// doRequestWithRetry 执行带重试的HTTP请求
+// doRequestWithRetry 执行带重试的HTTP请求。req 只作为模板，每次尝试都由 newAttempt
+// 构建新的请求，返回的响应 body 关闭时释放该次尝试的 context
 func (c *Client) doRequestWithRetry(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var lastErr error
 
-	for i := 0; i <= c.config.RetryCount; i++ {
-		resp, lastErr = c.httpClient.Do(req)
-		if lastErr == nil && resp.StatusCode < 500 {
+	if err := c.validateNames(operationFromContext(req.Context())); err != nil {
+		return nil, err
+	}
+	regional, err := c.prepareRegion(req)
+	if err != nil {
+		return nil, err
+	}
+	attempt := 0
+	for retry := 0; retry <= c.config.RetryCount; {
+		attempt++
+		attemptReq, cancel, err := c.newAttempt(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		ep := c.endpoints.pick(req.Method)
+		accelerated := c.accel.pinned(req)
+		if attemptReq, err = c.routeRequest(attemptReq, ep, accelerated, regional); err != nil {
+			cancel()
+			return nil, err
+		}
+		// signed after routing, the endpoint may serve the API below another base path
+		if err := c.signAttempt(attemptReq); err != nil {
+			cancel()
+			return nil, err
+		}
+		c.runRequestHooks(attemptReq)
+		start := time.Now()
+		tracedReq, at := c.traceAttempt(attemptReq)
+		resp, lastErr = c.httpClient.Do(tracedReq)
+		c.finishAttempt(attemptReq, at, resp)
+		c.runResponseHooks(resp, lastErr)
+		c.recordAttempt(attemptReq, attempt, resp)
+		c.logAttempt(attemptReq, attempt, resp, lastErr, time.Since(start))
+		if lastErr == nil {
+			if accelerated == "" {
+				c.endpoints.markUp(ep)
+			}
+			if resp.StatusCode < 500 {
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+				return resp, nil
+			}
+		} else if req.Context().Err() == nil {
+			failover := false
+			if accelerated != "" {
+				// fall back to the regular endpoints until the next probe
+				c.accel.unpin(accelerated)
+				failover = true
+			} else {
+				// another endpoint may be healthy, fail over without spending a retry
+				failover = c.endpoints.markDown(ep, lastErr)
+			}
+			if failover && replayable(req) {
+				cancel()
+				continue
+			}
+		}
+		retry++
+		if retry > c.config.RetryCount || !replayable(req) {
+			if lastErr == nil {
+				// out of retries, the caller reports the server error
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+				return resp, nil
+			}
+			cancel()
 			break
 		}
-		if i < c.config.RetryCount {
-			time.Sleep(time.Duration(i+1) * time.Second)
+		if lastErr == nil {
+			discardResponse(resp)
+		}
+		cancel()
+		if err := retryDelay(req.Context(), retry); err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			break
 		}
 	}
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("request failed after %d retries: %w", c.config.RetryCount, lastErr)
-	}
-
-	return resp, nil
+	return nil, fmt.Errorf("request failed after %d retries: %w", c.config.RetryCount, c.redactError(lastErr))
 }
 
-// handleErrorResponse 处理错误响应
+// handleErrorResponse 处理错误响应, 兼容 success/message 与 code/msg 两种格式
 func (c *Client) handleErrorResponse(resp *http.Response) error {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read error response: %w", err)
 	}
 
-	var apiErr APIError
-	if json.Unmarshal(respBody, &apiErr) == nil {
-		apiErr.StatusCode = resp.StatusCode
-		return &apiErr
+	var env envelope
+	if json.Unmarshal(respBody, &env) == nil {
+		c.recordFormat(env.format())
+		return c.redactAPIError(env.apiError(resp.StatusCode))
 	}
 
-	return &APIError{
+	return c.redactAPIError(&APIError{
 		StatusCode: resp.StatusCode,
 		Message:    string(respBody),
-	}
+	})
 }
 
+// ProgressFunc progress callback able to abort the transfer, a non-nil error
+// stops it and is returned wrapped with ErrTransferAborted
+type ProgressFunc func(transferred, total int64) error
+
+// ErrTransferAborted transfer stopped by a progress callback
+var ErrTransferAborted = errors.New("lingstorage: transfer aborted")
+
 // progressReader func reader from io.Reader
 type progressReader struct {
 	reader   io.Reader
 	total    int64
 	read     int64
 	callback func(uploaded, total int64)
+	check    ProgressFunc
+	ctx      context.Context // stop reading once done
 }
 
 func (pr *progressReader) Read(p []byte) (n int, err error) {
+	if pr.ctx != nil {
+		if err := pr.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
 	n, err = pr.reader.Read(p)
 	pr.read += int64(n)
 	if pr.callback != nil {
 		pr.callback(pr.read, pr.total)
 	}
+	if pr.check != nil {
+		if checkErr := pr.check(pr.read, pr.total); checkErr != nil {
+			return n, fmt.Errorf("%w: %w", ErrTransferAborted, checkErr)
+		}
+	}
 	return
 }
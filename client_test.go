@@ -1,7 +1,9 @@
 package lingstorage
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -677,6 +679,44 @@ func TestCreateBucket(t *testing.T) {
 	}
 }
 
+func TestCreateBucketOptions(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"features": []string{FeatureTags}}})
+		case "/api/public/buckets":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	require.NoError(t, client.CreateBucket(&CreateBucketRequest{
+		BucketName:   "tenant-42",
+		IsPrivate:    true,
+		Quota:        10 << 30,
+		StorageClass: "STANDARD_IA",
+		Tags:         map[string]string{"tenant": "42"},
+	}))
+	assert.Equal(t, map[string]interface{}{
+		"bucketName":   "tenant-42",
+		"region":       "",
+		"isPrivate":    true,
+		"quota":        float64(10 << 30),
+		"storageClass": "STANDARD_IA",
+		"tags":         map[string]interface{}{"tenant": "42"},
+	}, created)
+
+	// 服务端不支持版本控制时不创建存储桶
+	created = nil
+	err := client.CreateBucket(&CreateBucketRequest{BucketName: "tenant-43", Versioning: true})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Nil(t, created)
+	assert.Error(t, client.CreateBucket(&CreateBucketRequest{BucketName: "tenant-44", Quota: -1}))
+}
+
 func TestCopyFile(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -722,6 +762,37 @@ func TestCopyFile(t *testing.T) {
 	}
 }
 
+func TestCopyFileMetadataDirective(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.Equal(t, "REPLACE", reqBody["metadataDirective"])
+		assert.Equal(t, map[string]interface{}{}, reqBody["metadata"])
+		assert.Equal(t, "image/webp", reqBody["contentType"])
+		assert.Equal(t, "ARCHIVE", reqBody["storageClass"])
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	// 替换模式下空的 Metadata 表示清空元数据
+	require.NoError(t, client.CopyFile(&CopyFileRequest{
+		SrcBucket: "bkt", SrcKey: "a.webp", DestBucket: "bkt", DestKey: "a.webp",
+		MetadataDirective: MetadataDirectiveReplace,
+		ContentType:       "image/webp",
+		StorageClass:      "ARCHIVE",
+	}))
+
+	for _, req := range []*CopyFileRequest{
+		{SrcBucket: "bkt", SrcKey: "a", DestBucket: "bkt", DestKey: "b", Metadata: map[string]string{"k": "v"}},
+		{SrcBucket: "bkt", SrcKey: "a", DestBucket: "bkt", DestKey: "b", ContentType: "text/plain", MetadataDirective: MetadataDirectiveCopy},
+		{SrcBucket: "bkt", SrcKey: "a", DestBucket: "bkt", DestKey: "a"},
+		{SrcBucket: "bkt", SrcKey: "a", DestBucket: "bkt", DestKey: "b", MetadataDirective: "MERGE"},
+	} {
+		assert.Error(t, client.CopyFile(req))
+	}
+}
+
 func TestListFiles(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
@@ -784,3 +855,102 @@ func TestListFiles(t *testing.T) {
 		t.Errorf("Expected first file key uploads/file1.txt, got %s", result.Files[0].Key)
 	}
 }
+
+func TestProgressCheckAbortsTransfer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(strings.Repeat("x", 64*1024)))
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	// 回调返回错误时中止上传，请求不会发出
+	errTooLarge := errors.New("file too large")
+	_, err := client.UploadFromReader(&UploadFromReaderRequest{
+		Reader:   io.LimitReader(zeroReader{}, 1<<20),
+		Filename: "big.bin",
+		Bucket:   "bucket",
+		OnProgressCheck: func(transferred, total int64) error {
+			if transferred > 1024 {
+				return errTooLarge
+			}
+			return nil
+		},
+	})
+	assert.ErrorIs(t, err, ErrTransferAborted)
+	assert.ErrorIs(t, err, errTooLarge)
+	assert.Equal(t, 0, requests)
+
+	// 取消 context 同样中止上传
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.WithContext(ctx).UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt", Bucket: "bucket"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, requests)
+
+	// 下载时中止读取
+	result, err := client.Download(&DownloadRequest{
+		Bucket: "bucket",
+		Key:    "key",
+		OnProgressCheck: func(transferred, total int64) error {
+			if transferred > 0 {
+				return errTooLarge
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	defer result.Body.Close()
+	_, err = io.ReadAll(result.Body)
+	assert.ErrorIs(t, err, ErrTransferAborted)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestUploadResultAttributesFromHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"5d41402abc4b2a76b9719d911017c592"`)
+		w.Header().Set("X-Version-Id", "v2")
+		w.Header().Set("X-Storage-Class", "STANDARD_IA")
+		w.Write([]byte(`{"success":true,"data":{"key":"hello.txt","storageClass":"STANDARD","contentType":"text/plain"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	result, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "hello.txt", Bucket: "bucket"})
+	require.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", result.ETag)
+	assert.Equal(t, "v2", result.VersionID)
+	// 响应体中的字段优先
+	assert.Equal(t, "STANDARD", result.StorageClass)
+	assert.Equal(t, "text/plain", result.ContentType)
+}
+
+func TestFileInfoExtendedFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":{"files":[
+			{"key":"a.txt","size":5,"metadata":{"author":"ling"},"storageClass":"ARCHIVE","owner":"user-1","versionId":"v3"},
+			{"key":"b.txt","versionId":"v4","isDeleteMarker":true}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	result, err := client.ListFiles(&ListFilesRequest{Bucket: "bucket"})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 2)
+	a := result.Files[0]
+	assert.Equal(t, "ling", a.Metadata["author"])
+	assert.Equal(t, "ARCHIVE", a.StorageClass)
+	assert.Equal(t, "user-1", a.Owner)
+	assert.Equal(t, "v3", a.VersionID)
+	assert.False(t, a.IsDeleteMarker)
+	assert.True(t, result.Files[1].IsDeleteMarker)
+}
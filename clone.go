@@ -0,0 +1,46 @@
+package lingstorage
+
+import (
+	"net/http"
+	"time"
+)
+
+// Clone derived client with the overrides applied. The clone has its own
+// config, timeout, hooks and headers but shares the connection pool, stats
+// and caches with c, so it is cheap enough to create per call:
+//
+//	client.Clone(WithHeader("X-Tenant-ID", tenant)).DeleteFile(bucket, key)
+func (c *Client) Clone(overrides ...ClientOption) *Client {
+	clone := *c
+	config := *c.config
+	clone.config = &config
+	httpClient := *c.httpClient
+	clone.httpClient = &httpClient
+	clone.requestHooks = append([]RequestHook(nil), c.requestHooks...)
+	clone.preUploadHooks = append([]PreUploadHook(nil), c.preUploadHooks...)
+	clone.responseHooks = append([]ResponseHook(nil), c.responseHooks...)
+	clone.headers = c.headers.Clone()
+	for _, opt := range overrides {
+		opt(&clone)
+	}
+	return &clone
+}
+
+// WithHeader set a header on every request of the client, e.g. a tenant
+// scoping header required by a gateway. Auth and signature headers win
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// WithTimeout replace the per request timeout of the client
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.config.Timeout = timeout
+		c.httpClient.Timeout = timeout
+	}
+}
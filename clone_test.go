@@ -0,0 +1,45 @@
+package lingstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	var tenants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenants = append(tenants, r.Header.Get("X-Tenant-ID"))
+		assert.Equal(t, "test-key", r.Header.Get("X-API-Key"))
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+
+	var hooked int
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1},
+		WithRequestHook(func(*http.Request) { hooked++ }))
+	tenantA := client.Clone(WithHeader("X-Tenant-ID", "a"), WithTimeout(time.Second))
+	tenantB := tenantA.Clone(WithHeader("X-Tenant-ID", "b"))
+
+	require.NoError(t, client.DeleteFile("bkt", "k"))
+	require.NoError(t, tenantA.DeleteFile("bkt", "k"))
+	require.NoError(t, client.Clone(WithHeader("X-Tenant-ID", "c")).DeleteFile("bkt", "k"))
+	assert.Equal(t, []string{"", "a", "c"}, tenants)
+	assert.Equal(t, []string{"b"}, tenantB.headers.Values("X-Tenant-ID"))
+	assert.Equal(t, "a", tenantA.headers.Get("X-Tenant-ID"))
+	assert.Equal(t, 3, hooked)
+
+	// 覆盖项不影响原客户端, 统计共享
+	assert.Equal(t, time.Second, tenantA.httpClient.Timeout)
+	assert.Equal(t, 30*time.Second, client.httpClient.Timeout)
+	assert.Equal(t, 30*time.Second, client.config.Timeout)
+	assert.Nil(t, client.headers)
+	assert.EqualValues(t, 3, client.Stats().Operations)
+
+	tenantA.Clone(WithRequestHook(func(*http.Request) {}))
+	assert.Len(t, tenantA.requestHooks, 1)
+}
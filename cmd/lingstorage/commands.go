@@ -0,0 +1,288 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go"
+)
+
+// parseFlags parse command flags, expecting between min and max positional arguments, max < 0 is unbounded
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		return errUsage
+	}
+	return nil
+}
+
+func runLs(e *env, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "list recursively")
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		buckets, err := e.client.ListBuckets("", false)
+		if err != nil {
+			return err
+		}
+		for _, b := range buckets {
+			fmt.Fprintf(e.stdout, "%s%s\n", remoteScheme, b)
+		}
+		return nil
+	}
+
+	loc := parseLocation(fs.Arg(0))
+	if !loc.remote || loc.bucket == "" {
+		return fmt.Errorf("expected %sbucket[/prefix], got %q", remoteScheme, fs.Arg(0))
+	}
+	req := &lingstorage.ListFilesRequest{Bucket: loc.bucket, Prefix: loc.key}
+	if !*recursive {
+		req.Delimiter = "/"
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	for {
+		result, err := e.client.ListFiles(req)
+		if err != nil {
+			return err
+		}
+		for _, dir := range result.Directories {
+			fmt.Fprintf(w, "\tDIR\t%s\n", dir)
+		}
+		for _, f := range result.Files {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", f.LastModified.Format(time.RFC3339), f.Size, f.Key)
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			return nil
+		}
+		req.Marker = result.NextMarker
+	}
+}
+
+func runCp(e *env, args []string) error {
+	fs := flag.NewFlagSet("cp", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	src, dst := parseLocation(fs.Arg(0)), parseLocation(fs.Arg(1))
+	switch {
+	case !src.remote && !dst.remote:
+		return fmt.Errorf("one of source and destination must be %sbucket/key", remoteScheme)
+	case !src.remote:
+		if dst.bucket == "" {
+			return fmt.Errorf("destination bucket is required")
+		}
+		key := dst.key
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += filepath.Base(src.path)
+		}
+		result, err := e.client.UploadFile(&lingstorage.UploadRequest{FilePath: src.path, Bucket: dst.bucket, Key: key})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "upload: %s to %s%s/%s\n", src.path, remoteScheme, result.Bucket, result.Key)
+	case !dst.remote:
+		if _, err := remoteObject(fs.Arg(0)); err != nil {
+			return err
+		}
+		target := dst.path
+		if strings.HasSuffix(target, "/") || isDir(target) {
+			target = filepath.Join(target, path.Base(src.key))
+		}
+		if err := e.client.DownloadFile(src.bucket, src.key, target); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "download: %s to %s\n", fs.Arg(0), target)
+	default:
+		if _, err := remoteObject(fs.Arg(0)); err != nil {
+			return err
+		}
+		key := dst.key
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += path.Base(src.key)
+		}
+		if err := e.client.CopyFile(&lingstorage.CopyFileRequest{SrcBucket: src.bucket, SrcKey: src.key, DestBucket: dst.bucket, DestKey: key}); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "copy: %s to %s%s/%s\n", fs.Arg(0), remoteScheme, dst.bucket, key)
+	}
+	return nil
+}
+
+func runMv(e *env, args []string) error {
+	fs := flag.NewFlagSet("mv", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	src, err := remoteObject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	dst, err := remoteObject(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err := e.client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: src.bucket, SrcKey: src.key, DestBucket: dst.bucket, DestKey: dst.key}); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "move: %s to %s\n", fs.Arg(0), fs.Arg(1))
+	return nil
+}
+
+func runRm(e *env, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, -1); err != nil {
+		return err
+	}
+	for _, arg := range fs.Args() {
+		loc, err := remoteObject(arg)
+		if err != nil {
+			return err
+		}
+		if err := e.client.DeleteFile(loc.bucket, loc.key); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "delete: %s\n", arg)
+	}
+	return nil
+}
+
+func runSync(e *env, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	del := fs.Bool("delete", false, "delete extra files at the destination")
+	compare := fs.String("compare", lingstorage.CompareSize, "size, mtime or hash")
+	dryRun := fs.Bool("dry-run", false, "only print the changes")
+	if err := parseFlags(fs, args, 2, 2); err != nil {
+		return err
+	}
+	src, dst := parseLocation(fs.Arg(0)), parseLocation(fs.Arg(1))
+	printChange := func(change lingstorage.SyncChange) {
+		if change.Err != nil {
+			fmt.Fprintf(e.stderr, "%s failed: %s: %v\n", change.Action, change.Key, change.Err)
+			return
+		}
+		fmt.Fprintf(e.stdout, "%s: %s\n", change.Action, change.Key)
+	}
+
+	var report *lingstorage.SyncReport
+	var err error
+	switch {
+	case !src.remote && dst.remote && dst.bucket != "":
+		report, err = e.client.Sync(&lingstorage.SyncRequest{
+			LocalDir: src.path, Bucket: dst.bucket, Prefix: dst.key,
+			Delete: *del, Compare: *compare, DryRun: *dryRun, OnChange: printChange,
+		})
+	case src.remote && src.bucket != "" && !dst.remote:
+		report, err = e.client.SyncDown(&lingstorage.SyncDownRequest{
+			Bucket: src.bucket, Prefix: src.key, LocalDir: dst.path,
+			Delete: *del, Compare: *compare, DryRun: *dryRun, OnChange: printChange,
+		})
+	default:
+		return fmt.Errorf("sync needs a local directory and a %sbucket/prefix", remoteScheme)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "%d created, %d updated, %d deleted, %d unchanged, %d failed\n",
+		len(report.Created), len(report.Updated), len(report.Deleted), report.Unchanged, len(report.Failed))
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d changes failed", len(report.Failed))
+	}
+	return nil
+}
+
+func runPresign(e *env, args []string) error {
+	fs := flag.NewFlagSet("presign", flag.ContinueOnError)
+	expires := fs.Duration("expires", time.Hour, "URL lifetime")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	loc, err := remoteObject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	u, err := e.client.GetFileURL(loc.bucket, loc.key, *expires)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stdout, u)
+	return nil
+}
+
+func runMb(e *env, args []string) error {
+	fs := flag.NewFlagSet("mb", flag.ContinueOnError)
+	region := fs.String("region", "", "bucket region")
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	bucket, err := remoteBucket(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := e.client.CreateBucket(&lingstorage.CreateBucketRequest{BucketName: bucket, Region: *region}); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "make_bucket: %s\n", bucket)
+	return nil
+}
+
+func runRb(e *env, args []string) error {
+	fs := flag.NewFlagSet("rb", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	bucket, err := remoteBucket(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := e.client.DeleteBucket(bucket); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "remove_bucket: %s\n", bucket)
+	return nil
+}
+
+func runStat(e *env, args []string) error {
+	fs := flag.NewFlagSet("stat", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1, 1); err != nil {
+		return err
+	}
+	loc, err := remoteObject(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	info, err := e.client.GetFileInfo(loc.bucket, loc.key)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Key:\t%s\n", info.Key)
+	fmt.Fprintf(w, "Size:\t%d\n", info.Size)
+	fmt.Fprintf(w, "LastModified:\t%s\n", info.LastModified.Format(time.RFC3339))
+	fmt.Fprintf(w, "ETag:\t%s\n", info.ETag)
+	fmt.Fprintf(w, "ContentType:\t%s\n", info.ContentType)
+	if info.Encrypted() {
+		fmt.Fprintf(w, "Encryption:\t%s\n", info.ServerSideEncryption)
+	}
+	names := make([]string, 0, len(info.Metadata))
+	for name := range info.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "Metadata.%s:\t%s\n", name, info.Metadata[name])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,158 @@
+// Command lingstorage is a command line client of LingStorage built on the SDK.
+//
+// Remote locations are written as ls://bucket/key, anything else is a local path.
+// Credentials are read from LINGSTORAGE_BASE_URL, LINGSTORAGE_API_KEY and
+// LINGSTORAGE_API_SECRET, or from the global flags.
+//
+//	lingstorage ls ls://photos/2024/
+//	lingstorage cp ./cat.jpg ls://photos/cat.jpg
+//	lingstorage sync -delete ./site ls://www/
+//	lingstorage presign -expires 1h ls://photos/cat.jpg
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LingByte/lingstorage-sdk-go"
+)
+
+const remoteScheme = "ls://"
+
+// errUsage bad command line, usage was already printed
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// env state shared by commands
+type env struct {
+	client *lingstorage.Client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = []command{
+	{"ls", "ls [-r] [ls://bucket[/prefix]]", "list buckets, or objects below a prefix", runLs},
+	{"cp", "cp <src> <dst>", "copy between local files and objects", runCp},
+	{"mv", "mv ls://bucket/key ls://bucket/key", "move an object", runMv},
+	{"rm", "rm ls://bucket/key...", "delete objects", runRm},
+	{"sync", "sync [-delete] [-compare size|mtime|hash] [-dry-run] <src> <dst>", "sync a local directory and a bucket prefix", runSync},
+	{"presign", "presign [-expires 1h] ls://bucket/key", "print a temporary download URL", runPresign},
+	{"mb", "mb [-region r] ls://bucket", "create a bucket", runMb},
+	{"rb", "rb ls://bucket", "delete an empty bucket", runRb},
+	{"stat", "stat ls://bucket/key", "show object metadata", runStat},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run execute the command line, returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("lingstorage", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	baseURL := global.String("endpoint", os.Getenv("LINGSTORAGE_BASE_URL"), "server address")
+	// credentials fall back to the environment after parsing, a default would
+	// print them in the usage message
+	apiKey := global.String("key", "", "API key (default $LINGSTORAGE_API_KEY)")
+	apiSecret := global.String("secret", "", "API secret (default $LINGSTORAGE_API_SECRET)")
+	global.Usage = func() { printUsage(stderr, global) }
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if *apiKey == "" {
+		*apiKey = os.Getenv("LINGSTORAGE_API_KEY")
+	}
+	if *apiSecret == "" {
+		*apiSecret = os.Getenv("LINGSTORAGE_API_SECRET")
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+	name, rest := global.Arg(0), global.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if *baseURL == "" {
+			fmt.Fprintln(stderr, "lingstorage: server address is required, set LINGSTORAGE_BASE_URL or -endpoint")
+			return 2
+		}
+		e := &env{
+			client: lingstorage.NewClient(&lingstorage.Config{BaseURL: *baseURL, APIKey: *apiKey, APISecret: *apiSecret}),
+			stdout: stdout,
+			stderr: stderr,
+		}
+		if err := cmd.run(e, rest); err != nil {
+			if errors.Is(err, errUsage) {
+				fmt.Fprintf(stderr, "usage: lingstorage %s\n", cmd.usage)
+				return 2
+			}
+			fmt.Fprintf(stderr, "lingstorage %s: %v\n", name, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "lingstorage: unknown command %q\n", name)
+	global.Usage()
+	return 2
+}
+
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: lingstorage [global flags] <command> [flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nglobal flags:")
+	global.PrintDefaults()
+}
+
+// location parsed command line path
+type location struct {
+	remote bool
+	bucket string
+	key    string
+	path   string // local path
+}
+
+func parseLocation(s string) location {
+	if !strings.HasPrefix(s, remoteScheme) {
+		return location{path: s}
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(s, remoteScheme), "/")
+	return location{remote: true, bucket: bucket, key: key}
+}
+
+// remoteObject parse ls://bucket/key, the key is required
+func remoteObject(s string) (location, error) {
+	loc := parseLocation(s)
+	if !loc.remote || loc.bucket == "" || loc.key == "" {
+		return loc, fmt.Errorf("expected %sbucket/key, got %q", remoteScheme, s)
+	}
+	return loc, nil
+}
+
+// remoteBucket parse ls://bucket, a trailing slash is allowed
+func remoteBucket(s string) (string, error) {
+	loc := parseLocation(s)
+	if !loc.remote || loc.bucket == "" || loc.key != "" {
+		return "", fmt.Errorf("expected %sbucket, got %q", remoteScheme, s)
+	}
+	return loc.bucket, nil
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI 针对测试服务器执行命令行
+func runCLI(t *testing.T, server *lingstoragetest.FakeServer, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"-endpoint", server.URL, "-key", "k", "-secret", "s"}, args...), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCLIObjectCommands(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "cat.jpg")
+	require.NoError(t, os.WriteFile(src, []byte("meow"), 0644))
+
+	out, errOut, code := runCLI(t, server, "mb", "ls://photos")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "make_bucket: photos")
+
+	_, errOut, code = runCLI(t, server, "cp", src, "ls://photos/2024/")
+	require.Equal(t, 0, code, errOut)
+	obj, ok := server.Object("photos", "2024/cat.jpg")
+	require.True(t, ok)
+	assert.Equal(t, "meow", string(obj.Data))
+
+	out, _, code = runCLI(t, server, "ls", "ls://photos")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "DIR")
+	assert.Contains(t, out, "2024/")
+	out, _, _ = runCLI(t, server, "ls", "-r", "ls://photos")
+	assert.Contains(t, out, "2024/cat.jpg")
+
+	out, _, code = runCLI(t, server, "stat", "ls://photos/2024/cat.jpg")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "Size:")
+	assert.Contains(t, out, "4")
+
+	_, errOut, code = runCLI(t, server, "cp", "ls://photos/2024/cat.jpg", "ls://photos/copy.jpg")
+	require.Equal(t, 0, code, errOut)
+	_, errOut, code = runCLI(t, server, "mv", "ls://photos/copy.jpg", "ls://photos/moved.jpg")
+	require.Equal(t, 0, code, errOut)
+	_, ok = server.Object("photos", "moved.jpg")
+	assert.True(t, ok)
+
+	_, errOut, code = runCLI(t, server, "cp", "ls://photos/moved.jpg", dir)
+	require.Equal(t, 0, code, errOut)
+	data, err := os.ReadFile(filepath.Join(dir, "moved.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "meow", string(data))
+
+	out, _, code = runCLI(t, server, "presign", "-expires", "10m", "ls://photos/moved.jpg")
+	require.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(out, "http"))
+
+	_, errOut, code = runCLI(t, server, "rm", "ls://photos/moved.jpg", "ls://photos/2024/cat.jpg")
+	require.Equal(t, 0, code, errOut)
+	_, errOut, code = runCLI(t, server, "rb", "ls://photos")
+	require.Equal(t, 0, code, errOut)
+	assert.NotContains(t, server.Buckets(), "photos")
+
+	out, _, _ = runCLI(t, server, "ls")
+	assert.Contains(t, out, "ls://"+lingstoragetest.DefaultBucket)
+}
+
+func TestCLISync(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "css"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "css", "site.css"), []byte("body{}"), 0644))
+
+	out, errOut, code := runCLI(t, server, "sync", dir, "ls://default/www")
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "2 created")
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "www/css/site.css")
+	assert.True(t, ok)
+
+	restore := t.TempDir()
+	out, errOut, code = runCLI(t, server, "sync", "ls://default/www", restore)
+	require.Equal(t, 0, code, errOut)
+	assert.Contains(t, out, "2 created")
+	assert.FileExists(t, filepath.Join(restore, "css", "site.css"))
+}
+
+func TestCLIUsage(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	_, errOut, code := runCLI(t, server, "frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "unknown command")
+
+	_, errOut, code = runCLI(t, server, "mv", "ls://default/a")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "usage: lingstorage mv")
+
+	_, errOut, code = runCLI(t, server, "stat", "ls://default")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "expected ls://bucket/key")
+
+	var stderr bytes.Buffer
+	assert.Equal(t, 2, run(nil, &bytes.Buffer{}, &stderr))
+	assert.Contains(t, stderr.String(), "commands:")
+}
+
+func TestCLIUsageHidesEnvCredentials(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	t.Setenv("LINGSTORAGE_API_KEY", "env-key-123456")
+	t.Setenv("LINGSTORAGE_API_SECRET", "env-secret-abcdef")
+
+	// 帮助信息只提及环境变量名, 不输出其值
+	var stderr bytes.Buffer
+	assert.Equal(t, 2, run([]string{"-endpoint", server.URL, "frobnicate"}, &bytes.Buffer{}, &stderr))
+	assert.NotContains(t, stderr.String(), "env-key-123456")
+	assert.NotContains(t, stderr.String(), "env-secret-abcdef")
+	assert.Contains(t, stderr.String(), "$LINGSTORAGE_API_SECRET")
+
+	// 未指定参数时仍从环境变量读取
+	server.APIKey = "env-key-123456"
+	stderr.Reset()
+	assert.Equal(t, 0, run([]string{"-endpoint", server.URL, "ls"}, &bytes.Buffer{}, &stderr), stderr.String())
+}
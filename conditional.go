@@ -0,0 +1,55 @@
+package lingstorage
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// ErrPreconditionFailed an IfMatch or IfNoneMatch condition did not hold,
+// another writer changed the object first
+var ErrPreconditionFailed = errors.New("lingstorage: precondition failed")
+
+// quoteETag ETag as an entity tag of a conditional header, "*" stays bare
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// setConditionHeaders set If-Match / If-None-Match when given
+func setConditionHeaders(header http.Header, ifMatch, ifNoneMatch string) {
+	if ifMatch != "" {
+		header.Set(constants.IF_MATCH, quoteETag(ifMatch))
+	}
+	if ifNoneMatch != "" {
+		header.Set(constants.IF_NONE_MATCH, quoteETag(ifNoneMatch))
+	}
+}
+
+// DeleteFileIfMatch 仅当对象 ETag 与 etag 一致时删除, 否则返回 ErrPreconditionFailed
+func (c *Client) DeleteFileIfMatch(bucket, key, etag string) (err error) {
+	ctx, op := c.startOperation("DeleteFile", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	defer c.metadata.invalidate(bucket, key)
+	if etag == "" {
+		return errors.New("etag is required")
+	}
+	httpReq, err := c.newRequest(ctx, "DELETE", objectPath(bucket, key), nil)
+	if err != nil {
+		return err
+	}
+	setConditionHeaders(httpReq.Header, etag, "")
+	resp, err := c.doRequestWithRetry(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return c.handleErrorResponse(resp)
+	}
+	return c.decodeResponse(resp, nil)
+}
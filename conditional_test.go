@@ -0,0 +1,67 @@
+package lingstorage_test
+
+import (
+	"strings"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalWrites(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	upload := func(data, ifMatch, ifNoneMatch string) (*lingstorage.UploadResult, error) {
+		return client.UploadBytes(&lingstorage.UploadBytesRequest{
+			Data: []byte(data), Filename: "config.json", Key: "config.json",
+			IfMatch: ifMatch, IfNoneMatch: ifNoneMatch,
+		})
+	}
+
+	first, err := upload("v1", "", "*")
+	require.NoError(t, err)
+	// 两个写入方同时创建, 后到的失败
+	_, err = upload("v1-other", "", "*")
+	assert.ErrorIs(t, err, lingstorage.ErrPreconditionFailed)
+
+	second, err := upload("v2", first.ETag, "")
+	require.NoError(t, err)
+	// 基于旧版本的覆盖被拒绝
+	_, err = upload("v2-other", first.ETag, "")
+	assert.ErrorIs(t, err, lingstorage.ErrPreconditionFailed)
+	obj, _ := server.Object(lingstoragetest.DefaultBucket, "config.json")
+	assert.Equal(t, "v2", string(obj.Data))
+
+	err = client.DeleteFileIfMatch(lingstoragetest.DefaultBucket, "config.json", first.ETag)
+	assert.ErrorIs(t, err, lingstorage.ErrPreconditionFailed)
+	require.NoError(t, client.DeleteFileIfMatch(lingstoragetest.DefaultBucket, "config.json", second.ETag))
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "config.json")
+	assert.False(t, ok)
+	assert.Error(t, client.DeleteFileIfMatch(lingstoragetest.DefaultBucket, "config.json", ""))
+}
+
+func TestConditionalReaderUpload(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	upload := func(data, ifMatch, ifNoneMatch string) (*lingstorage.UploadResult, error) {
+		return client.UploadFromReader(&lingstorage.UploadFromReaderRequest{
+			Reader: strings.NewReader(data), Filename: "stream.log", Key: "stream.log",
+			IfMatch: ifMatch, IfNoneMatch: ifNoneMatch,
+		})
+	}
+
+	first, err := upload("v1", "", "*")
+	require.NoError(t, err)
+	_, err = upload("v1-other", "", "*")
+	assert.ErrorIs(t, err, lingstorage.ErrPreconditionFailed)
+	_, err = upload("v2", "stale", "")
+	assert.ErrorIs(t, err, lingstorage.ErrPreconditionFailed)
+	_, err = upload("v2", first.ETag, "")
+	require.NoError(t, err)
+	obj, _ := server.Object(lingstoragetest.DefaultBucket, "stream.log")
+	assert.Equal(t, "v2", string(obj.Data))
+}
@@ -2,8 +2,33 @@ package constants
 
 const (
 	DEFAULT_USER_AGENT = "LingStorage-SDK/1.0.0"
-	CONETENT_TYPE      = "Content-Type"
+	CONTENT_TYPE       = "Content-Type"
 	USER_AGENT         = "User-Agent"
 	XAPIKEY            = "X-API-Key"
 	XAPISECRET         = "X-API-Secret"
+	XTIMESTAMP         = "X-Timestamp"
+	XNONCE             = "X-Nonce"
+	XSIGNATURE         = "X-Signature"
+	AUTHORIZATION      = "Authorization"
+	XMETAPREFIX        = "X-Meta-"
+	XSSE               = "X-Server-Side-Encryption"
+	XSSEKMSKEYID       = "X-Server-Side-Encryption-Kms-Key-Id"
+	XSSECALGORITHM     = "X-Server-Side-Encryption-Customer-Algorithm"
+	XSSECKEY           = "X-Server-Side-Encryption-Customer-Key"
+	XSSECKEYMD5        = "X-Server-Side-Encryption-Customer-Key-Md5"
+	XQUARANTINEREASON  = "X-Quarantine-Reason"
+	RANGE              = "Range"
+	CONTENT_RANGE      = "Content-Range"
+	LAST_MODIFIED      = "Last-Modified"
+	ETAG               = "ETag"
+	IF_MATCH           = "If-Match"
+	IF_NONE_MATCH      = "If-None-Match"
+	XCHECKSUMSHA256    = "X-Checksum-Sha256"
+	XVERSIONID         = "X-Version-Id"
+	XSTORAGECLASS      = "X-Storage-Class"
 )
+
+// CONETENT_TYPE Content-Type header
+//
+// Deprecated: misspelled, use CONTENT_TYPE
+const CONETENT_TYPE = CONTENT_TYPE
@@ -0,0 +1,86 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignedCookie cookies granting read access to every object under a prefix
+// until Expires, so a page with many private objects needs no per-URL signing
+type SignedCookie struct {
+	Bucket  string
+	Prefix  string
+	Expires time.Time
+	Cookies []*http.Cookie
+}
+
+// signedCookieValue cookie as returned by the server
+type signedCookieValue struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+}
+
+// IssueSignedCookie 为前缀签发访问私有对象的 Cookie
+func (c *Client) IssueSignedCookie(bucket, prefix string, expiry time.Duration) (_ *SignedCookie, err error) {
+	ctx, op := c.startOperation("IssueSignedCookie", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if expiry <= 0 {
+		return nil, fmt.Errorf("signed cookie expiry must be positive")
+	}
+	body := map[string]string{
+		"prefix":  prefix,
+		"expires": expiry.String(),
+	}
+	var data struct {
+		Cookies   []signedCookieValue `json:"cookies"`
+		ExpiresAt time.Time           `json:"expiresAt"`
+	}
+	if err := c.call(ctx, "POST", bucketPath(bucket)+"/signed-cookies", body, &data); err != nil {
+		return nil, err
+	}
+
+	expires := data.ExpiresAt
+	if expires.IsZero() {
+		expires = time.Now().Add(expiry)
+	}
+	signed := &SignedCookie{Bucket: bucket, Prefix: prefix, Expires: expires}
+	for _, v := range data.Cookies {
+		path := v.Path
+		if path == "" {
+			path = "/"
+		}
+		signed.Cookies = append(signed.Cookies, &http.Cookie{
+			Name:     v.Name,
+			Value:    v.Value,
+			Domain:   v.Domain,
+			Path:     path,
+			Expires:  expires,
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return signed, nil
+}
+
+// SetCookies write the cookies to the response, call before WriteHeader
+func (s *SignedCookie) SetCookies(w http.ResponseWriter) {
+	for _, cookie := range s.Cookies {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// AddToRequest attach the cookies to an outgoing request, e.g. a server side fetch
+func (s *SignedCookie) AddToRequest(req *http.Request) {
+	for _, cookie := range s.Cookies {
+		req.AddCookie(cookie)
+	}
+}
+
+// Expired check cookies are past their expiry
+func (s *SignedCookie) Expired() bool {
+	return !time.Now().Before(s.Expires)
+}
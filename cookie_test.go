@@ -0,0 +1,58 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueSignedCookie(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/public/buckets/gallery/signed-cookies", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "albums/2024/", body["prefix"])
+		assert.Equal(t, "1h0m0s", body["expires"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"expiresAt": expiresAt,
+			"cookies": []map[string]string{
+				{"name": "LS-Policy", "value": "p", "domain": "cdn.example.com", "path": "/albums/2024/"},
+				{"name": "LS-Signature", "value": "s", "domain": "cdn.example.com"},
+			},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	signed, err := client.IssueSignedCookie("gallery", "albums/2024/", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, signed.Expires)
+	assert.False(t, signed.Expired())
+	require.Len(t, signed.Cookies, 2)
+	assert.Equal(t, "/", signed.Cookies[1].Path)
+
+	rec := httptest.NewRecorder()
+	signed.SetCookies(rec)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 2)
+	assert.Equal(t, "LS-Policy", cookies[0].Name)
+	assert.Equal(t, "/albums/2024/", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+
+	req := httptest.NewRequest("GET", "https://cdn.example.com/albums/2024/a.jpg", nil)
+	signed.AddToRequest(req)
+	cookie, err := req.Cookie("LS-Signature")
+	require.NoError(t, err)
+	assert.Equal(t, "s", cookie.Value)
+
+	_, err = client.IssueSignedCookie("gallery", "albums/", 0)
+	assert.Error(t, err)
+}
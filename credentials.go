@@ -0,0 +1,236 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// EnvAPIKey default env var of the api key
+	EnvAPIKey = "LINGSTORAGE_API_KEY"
+	// EnvAPISecret default env var of the api secret
+	EnvAPISecret = "LINGSTORAGE_API_SECRET"
+)
+
+// ErrNoCredentials no provider in the chain returned credentials
+var ErrNoCredentials = errors.New("lingstorage: no valid credentials found")
+
+// Credentials api key and secret pair
+type Credentials struct {
+	APIKey    string    `json:"apiKey"`
+	APISecret string    `json:"apiSecret"`
+	Expires   time.Time `json:"expires"` // zero means never expires
+}
+
+// expired check if credentials expire within window
+func (c Credentials) expired(window time.Duration) bool {
+	if c.Expires.IsZero() {
+		return false
+	}
+	return !time.Now().Add(window).Before(c.Expires)
+}
+
+// CredentialsProvider source of credentials
+type CredentialsProvider interface {
+	Retrieve() (Credentials, error)
+}
+
+// CredentialsProviderFunc custom provider func
+type CredentialsProviderFunc func() (Credentials, error)
+
+// Retrieve call the func
+func (f CredentialsProviderFunc) Retrieve() (Credentials, error) {
+	return f()
+}
+
+// StaticProvider fixed credentials
+type StaticProvider struct {
+	Credentials Credentials
+}
+
+// Retrieve return the static credentials
+func (p *StaticProvider) Retrieve() (Credentials, error) {
+	if p.Credentials.APIKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return p.Credentials, nil
+}
+
+// EnvProvider read credentials from env vars
+type EnvProvider struct {
+	KeyVar    string // default LINGSTORAGE_API_KEY
+	SecretVar string // default LINGSTORAGE_API_SECRET
+}
+
+// Retrieve read env vars
+func (p *EnvProvider) Retrieve() (Credentials, error) {
+	keyVar, secretVar := p.KeyVar, p.SecretVar
+	if keyVar == "" {
+		keyVar = EnvAPIKey
+	}
+	if secretVar == "" {
+		secretVar = EnvAPISecret
+	}
+	creds := Credentials{
+		APIKey:    os.Getenv(keyVar),
+		APISecret: os.Getenv(secretVar),
+	}
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("%w: env %s not set", ErrNoCredentials, keyVar)
+	}
+	return creds, nil
+}
+
+// FileProvider read credentials from a json file, the file is re-read on every refresh
+// so rotated keys are picked up
+type FileProvider struct {
+	Path string
+}
+
+// Retrieve read and parse the file
+func (p *FileProvider) Retrieve() (Credentials, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("%w: empty apiKey in %s", ErrNoCredentials, p.Path)
+	}
+	return creds, nil
+}
+
+// MetadataProvider fetch credentials from a metadata service, response is the json form of Credentials
+type MetadataProvider struct {
+	Endpoint   string
+	HTTPClient *http.Client // default client with 5s timeout
+}
+
+// Retrieve request the metadata service
+func (p *MetadataProvider) Retrieve() (Credentials, error) {
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := httpClient.Get(p.Endpoint)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to request metadata service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("metadata service returned status code: %d", resp.StatusCode)
+	}
+	var creds Credentials
+	if err := json.NewDecoder(resp.Body).Decode(&creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse metadata response: %w", err)
+	}
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("%w: empty apiKey from metadata service", ErrNoCredentials)
+	}
+	return creds, nil
+}
+
+// ChainProvider try providers in order, return the first success
+type ChainProvider struct {
+	Providers []CredentialsProvider
+}
+
+// NewChainProvider create chain provider
+func NewChainProvider(providers ...CredentialsProvider) *ChainProvider {
+	return &ChainProvider{Providers: providers}
+}
+
+// Retrieve first credentials found in the chain
+func (p *ChainProvider) Retrieve() (Credentials, error) {
+	var errs []error
+	for _, provider := range p.Providers {
+		creds, err := provider.Retrieve()
+		if err == nil {
+			return creds, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return Credentials{}, ErrNoCredentials
+	}
+	return Credentials{}, fmt.Errorf("%w: %w", ErrNoCredentials, errors.Join(errs...))
+}
+
+// CachedProvider cache credentials of another provider and refresh them
+// when they are about to expire or RefreshInterval elapsed
+type CachedProvider struct {
+	Provider        CredentialsProvider
+	RefreshInterval time.Duration // refresh non-expiring credentials, default 5 minutes
+	ExpiryWindow    time.Duration // refresh before expiry, default 1 minute
+
+	mu        sync.Mutex
+	creds     Credentials
+	fetchedAt time.Time
+}
+
+// NewCachedProvider wrap provider with cache
+func NewCachedProvider(provider CredentialsProvider) *CachedProvider {
+	return &CachedProvider{
+		Provider:        provider,
+		RefreshInterval: 5 * time.Minute,
+		ExpiryWindow:    time.Minute,
+	}
+}
+
+// Retrieve cached credentials, refresh if needed. if refresh fails but cached
+// credentials are still valid they are returned
+func (p *CachedProvider) Retrieve() (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.needRefresh() {
+		return p.creds, nil
+	}
+	creds, err := p.Provider.Retrieve()
+	if err != nil {
+		if !p.fetchedAt.IsZero() && !p.creds.expired(0) {
+			return p.creds, nil
+		}
+		return Credentials{}, err
+	}
+	p.creds = creds
+	p.fetchedAt = time.Now()
+	return creds, nil
+}
+
+// Invalidate force refresh on next Retrieve
+func (p *CachedProvider) Invalidate() {
+	p.mu.Lock()
+	p.fetchedAt = time.Time{}
+	p.mu.Unlock()
+}
+
+func (p *CachedProvider) needRefresh() bool {
+	if p.fetchedAt.IsZero() {
+		return true
+	}
+	if p.creds.expired(p.ExpiryWindow) {
+		return true
+	}
+	return p.RefreshInterval > 0 && time.Since(p.fetchedAt) >= p.RefreshInterval
+}
+
+// credentials current credentials of the client
+func (c *Client) credentials() (Credentials, error) {
+	if c.credentialsProvider == nil {
+		return Credentials{APIKey: c.config.APIKey, APISecret: c.config.APISecret}, nil
+	}
+	creds, err := c.credentialsProvider.Retrieve()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+	return creds, nil
+}
@@ -0,0 +1,95 @@
+package lingstorage
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainProvider(t *testing.T) {
+	t.Setenv("TEST_LS_KEY", "")
+	chain := NewChainProvider(
+		&EnvProvider{KeyVar: "TEST_LS_KEY", SecretVar: "TEST_LS_SECRET"},
+		&StaticProvider{Credentials: Credentials{APIKey: "static-key", APISecret: "static-secret"}},
+	)
+	creds, err := chain.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "static-key", creds.APIKey)
+
+	t.Setenv("TEST_LS_KEY", "env-key")
+	creds, err = chain.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "env-key", creds.APIKey)
+
+	_, err = NewChainProvider().Retrieve()
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestCachedProviderRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"apiKey":"key-1","apiSecret":"secret-1"}`), 0600))
+
+	cached := NewCachedProvider(&FileProvider{Path: path})
+	creds, err := cached.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", creds.APIKey)
+
+	// 轮换后在刷新前仍使用缓存
+	require.NoError(t, os.WriteFile(path, []byte(`{"apiKey":"key-2","apiSecret":"secret-2"}`), 0600))
+	creds, err = cached.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", creds.APIKey)
+
+	cached.Invalidate()
+	creds, err = cached.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", creds.APIKey)
+}
+
+func TestCachedProviderExpiry(t *testing.T) {
+	calls := 0
+	cached := NewCachedProvider(CredentialsProviderFunc(func() (Credentials, error) {
+		calls++
+		return Credentials{APIKey: "key", Expires: time.Now().Add(30 * time.Second)}, nil
+	}))
+	_, err := cached.Retrieve()
+	require.NoError(t, err)
+	// 在 ExpiryWindow 内即将过期，每次都会刷新
+	_, err = cached.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClientWithCredentialsProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "provided-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "provided-secret", r.Header.Get("X-API-Secret"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		Credentials: &StaticProvider{Credentials: Credentials{
+			APIKey:    "provided-key",
+			APISecret: "provided-secret",
+		}},
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+
+	failing := NewClient(&Config{
+		BaseURL: server.URL,
+		Credentials: CredentialsProviderFunc(func() (Credentials, error) {
+			return Credentials{}, ErrNoCredentials
+		}),
+	})
+	err := failing.DeleteFile("bucket", "key")
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
@@ -0,0 +1,459 @@
+package lingstorage
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// CryptoAlgorithm content encryption algorithm of CryptoClient
+	CryptoAlgorithm = "AES-256-GCM-CHUNKED"
+	// DefaultCryptoChunkSize plaintext bytes per encrypted chunk
+	DefaultCryptoChunkSize = 64 * 1024
+
+	metaCryptoAlgorithm     = "x-ling-cek-alg"
+	metaCryptoWrappedKey    = "x-ling-wrapped-key"
+	metaCryptoKeyID         = "x-ling-key-id"
+	metaCryptoIV            = "x-ling-iv"
+	metaCryptoChunkSize     = "x-ling-chunk-size"
+	metaCryptoPlaintextSize = "x-ling-unencrypted-size"
+
+	dataKeySize  = 32
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+var (
+	// ErrNotEncrypted object has no client side encryption metadata
+	ErrNotEncrypted = errors.New("lingstorage: object is not client side encrypted")
+	// ErrCiphertextCorrupted ciphertext failed authentication or was truncated
+	ErrCiphertextCorrupted = errors.New("lingstorage: ciphertext corrupted or truncated")
+)
+
+// KeyProvider generate and unwrap data keys for envelope encryption
+type KeyProvider interface {
+	// GenerateDataKey return a new plaintext data key, its wrapped form and the id of the wrapping key
+	GenerateDataKey() (plaintext, wrapped []byte, keyID string, err error)
+	// DecryptDataKey unwrap a data key
+	DecryptDataKey(wrapped []byte, keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider wrap data keys with a local 32 bytes master key
+type StaticKeyProvider struct {
+	KeyID     string
+	MasterKey []byte
+}
+
+// NewStaticKeyProvider create static key provider
+func NewStaticKeyProvider(keyID string, masterKey []byte) (*StaticKeyProvider, error) {
+	if len(masterKey) != dataKeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", dataKeySize, len(masterKey))
+	}
+	return &StaticKeyProvider{KeyID: keyID, MasterKey: masterKey}, nil
+}
+
+// GenerateDataKey generate random data key and wrap it with AES-GCM
+func (p *StaticKeyProvider) GenerateDataKey() ([]byte, []byte, string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newGCM(p.MasterKey)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	wrapped := aead.Seal(nonce, nonce, dataKey, []byte(p.KeyID))
+	return dataKey, wrapped, p.KeyID, nil
+}
+
+// DecryptDataKey unwrap data key with the master key
+func (p *StaticKeyProvider) DecryptDataKey(wrapped []byte, keyID string) ([]byte, error) {
+	if keyID != p.KeyID {
+		return nil, fmt.Errorf("data key wrapped by unknown key %q", keyID)
+	}
+	if len(wrapped) < gcmNonceSize {
+		return nil, ErrCiphertextCorrupted
+	}
+	aead, err := newGCM(p.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := aead.Open(nil, wrapped[:gcmNonceSize], wrapped[gcmNonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// KMSClient minimal key management service client
+type KMSClient interface {
+	GenerateDataKey(keyID string) (plaintext, ciphertext []byte, err error)
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSKeyProvider generate data keys with a KMS master key
+type KMSKeyProvider struct {
+	KeyID  string
+	Client KMSClient
+}
+
+// GenerateDataKey ask KMS for a new data key
+func (p *KMSKeyProvider) GenerateDataKey() ([]byte, []byte, string, error) {
+	plaintext, wrapped, err := p.Client.GenerateDataKey(p.KeyID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate kms data key: %w", err)
+	}
+	return plaintext, wrapped, p.KeyID, nil
+}
+
+// DecryptDataKey ask KMS to unwrap the data key
+func (p *KMSKeyProvider) DecryptDataKey(wrapped []byte, keyID string) ([]byte, error) {
+	dataKey, err := p.Client.Decrypt(keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kms data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// CryptoClient encrypt objects on the client before upload and decrypt them on download.
+// Data is encrypted with a per object AES-256-GCM data key in fixed size chunks,
+// the wrapped data key is stored in the object metadata
+type CryptoClient struct {
+	client    *Client
+	keys      KeyProvider
+	chunkSize int
+}
+
+// NewCryptoClient create crypto client
+func NewCryptoClient(client *Client, keys KeyProvider) *CryptoClient {
+	return &CryptoClient{
+		client:    client,
+		keys:      keys,
+		chunkSize: DefaultCryptoChunkSize,
+	}
+}
+
+// UploadFile encrypt and upload a local file
+func (cc *CryptoClient) UploadFile(req *UploadRequest) (*UploadResult, error) {
+	file, err := os.Open(req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return cc.UploadFromReader(&UploadFromReaderRequest{
+		Reader:          file,
+		Filename:        filepath.Base(req.FilePath),
+		Size:            fileInfo.Size(),
+		Bucket:          req.Bucket,
+		Key:             req.Key,
+		AllowedTypes:    req.AllowedTypes,
+		Metadata:        req.Metadata,
+		SSEAlgorithm:    req.SSEAlgorithm,
+		SSEKMSKeyID:     req.SSEKMSKeyID,
+		SSECustomerKey:  req.SSECustomerKey,
+		OnProgress:      req.OnProgress,
+		OnProgressCheck: req.OnProgressCheck,
+	})
+}
+
+// UploadFromReader encrypt and upload from io.Reader. Image processing options
+// are ignored because the server only sees ciphertext
+func (cc *CryptoClient) UploadFromReader(req *UploadFromReaderRequest) (*UploadResult, error) {
+	dataKey, wrapped, keyID, err := cc.keys.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to generate iv: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string, len(req.Metadata)+6)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[metaCryptoAlgorithm] = CryptoAlgorithm
+	metadata[metaCryptoWrappedKey] = base64.StdEncoding.EncodeToString(wrapped)
+	metadata[metaCryptoKeyID] = keyID
+	metadata[metaCryptoIV] = base64.StdEncoding.EncodeToString(iv)
+	metadata[metaCryptoChunkSize] = strconv.Itoa(cc.chunkSize)
+
+	var reader io.Reader = req.Reader
+	if (req.OnProgress != nil && req.Size > 0) || req.OnProgressCheck != nil {
+		reader = &progressReader{
+			reader:   req.Reader,
+			total:    req.Size,
+			callback: req.OnProgress,
+			check:    req.OnProgressCheck,
+		}
+	}
+	size := int64(-1)
+	if req.Size > 0 {
+		metadata[metaCryptoPlaintextSize] = strconv.FormatInt(req.Size, 10)
+		size = encryptedSize(req.Size, cc.chunkSize)
+	}
+
+	return cc.client.UploadFromReader(&UploadFromReaderRequest{
+		Reader:         newEncryptReader(reader, aead, iv, cc.chunkSize),
+		Filename:       req.Filename,
+		Size:           size,
+		Bucket:         req.Bucket,
+		Key:            req.Key,
+		AllowedTypes:   req.AllowedTypes,
+		Metadata:       metadata,
+		SSEAlgorithm:   req.SSEAlgorithm,
+		SSEKMSKeyID:    req.SSEKMSKeyID,
+		SSECustomerKey: req.SSECustomerKey,
+	})
+}
+
+// Download download and decrypt object, Body yields plaintext
+func (cc *CryptoClient) Download(req *DownloadRequest) (*DownloadResult, error) {
+	result, err := cc.client.Download(&DownloadRequest{
+		Bucket:         req.Bucket,
+		Key:            req.Key,
+		SSECustomerKey: req.SSECustomerKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := cc.decryptBody(result)
+	if err != nil {
+		result.Body.Close()
+		return nil, err
+	}
+
+	size := int64(-1)
+	if v, ok := result.Metadata[metaCryptoPlaintextSize]; ok {
+		size, _ = strconv.ParseInt(v, 10, 64)
+	}
+	result.Size = size
+	result.Body = &readCloser{Reader: body, Closer: result.Body}
+	if req.OnProgress != nil || req.OnProgressCheck != nil {
+		result.Body = &readCloser{
+			Reader: &progressReader{
+				reader:   body,
+				total:    size,
+				callback: req.OnProgress,
+				check:    req.OnProgressCheck,
+			},
+			Closer: result.Body,
+		}
+	}
+
+	return result, nil
+}
+
+// DownloadFile download, decrypt and save object to local path
+func (cc *CryptoClient) DownloadFile(bucket, key, filePath string) error {
+	result, err := cc.Download(&DownloadRequest{Bucket: bucket, Key: key})
+	if err != nil {
+		return err
+	}
+	defer result.Body.Close()
+
+	return writeFile(filePath, result.Body)
+}
+
+// decryptBody unwrap the data key from metadata and wrap body with decryption
+func (cc *CryptoClient) decryptBody(result *DownloadResult) (io.Reader, error) {
+	if result.Metadata[metaCryptoAlgorithm] != CryptoAlgorithm {
+		return nil, ErrNotEncrypted
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(result.Metadata[metaCryptoWrappedKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key metadata: %w", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(result.Metadata[metaCryptoIV])
+	if err != nil || len(iv) != gcmNonceSize {
+		return nil, errors.New("invalid iv metadata")
+	}
+	chunkSize, err := strconv.Atoi(result.Metadata[metaCryptoChunkSize])
+	if err != nil || chunkSize <= 0 {
+		return nil, errors.New("invalid chunk size metadata")
+	}
+	dataKey, err := cc.keys.DecryptDataKey(wrapped, result.Metadata[metaCryptoKeyID])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return newDecryptReader(result.Body, aead, iv, chunkSize), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedSize ciphertext size of a plaintext, there is always at least one chunk
+func encryptedSize(size int64, chunkSize int) int64 {
+	chunks := (size + int64(chunkSize) - 1) / int64(chunkSize)
+	if chunks == 0 {
+		chunks = 1
+	}
+	return size + chunks*gcmTagSize
+}
+
+// chunkNonceAndAAD derive per chunk nonce from iv and bind index and final flag to the chunk,
+// which prevents reordering and truncation
+func chunkNonceAndAAD(iv []byte, index uint32, final bool) ([]byte, []byte) {
+	nonce := make([]byte, gcmNonceSize)
+	copy(nonce, iv)
+	counter := binary.BigEndian.Uint32(nonce[gcmNonceSize-4:]) ^ index
+	binary.BigEndian.PutUint32(nonce[gcmNonceSize-4:], counter)
+
+	aad := make([]byte, 5)
+	binary.BigEndian.PutUint32(aad, index)
+	if final {
+		aad[4] = 1
+	}
+	return nonce, aad
+}
+
+// encryptReader streaming chunked encryption
+type encryptReader struct {
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	iv        []byte
+	chunkSize int
+	index     uint32
+	plain     []byte
+	out       []byte
+	done      bool
+}
+
+func newEncryptReader(r io.Reader, aead cipher.AEAD, iv []byte, chunkSize int) *encryptReader {
+	return &encryptReader{
+		src:       bufio.NewReaderSize(r, chunkSize+1),
+		aead:      aead,
+		iv:        iv,
+		chunkSize: chunkSize,
+		plain:     make([]byte, chunkSize),
+	}
+}
+
+func (er *encryptReader) Read(p []byte) (int, error) {
+	for len(er.out) == 0 {
+		if er.done {
+			return 0, io.EOF
+		}
+		if err := er.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, er.out)
+	er.out = er.out[n:]
+	return n, nil
+}
+
+func (er *encryptReader) nextChunk() error {
+	n, err := io.ReadFull(er.src, er.plain)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	final := n < er.chunkSize
+	if !final {
+		if _, err := er.src.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+	nonce, aad := chunkNonceAndAAD(er.iv, er.index, final)
+	er.out = er.aead.Seal(er.out[:0], nonce, er.plain[:n], aad)
+	er.index++
+	er.done = final
+	return nil
+}
+
+// decryptReader streaming chunked decryption
+type decryptReader struct {
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	iv        []byte
+	chunkSize int
+	index     uint32
+	sealed    []byte
+	out       []byte
+	done      bool
+}
+
+func newDecryptReader(r io.Reader, aead cipher.AEAD, iv []byte, chunkSize int) *decryptReader {
+	return &decryptReader{
+		src:       bufio.NewReaderSize(r, chunkSize+gcmTagSize+1),
+		aead:      aead,
+		iv:        iv,
+		chunkSize: chunkSize,
+		sealed:    make([]byte, chunkSize+gcmTagSize),
+	}
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.out) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.out)
+	dr.out = dr.out[n:]
+	return n, nil
+}
+
+func (dr *decryptReader) nextChunk() error {
+	n, err := io.ReadFull(dr.src, dr.sealed)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if n < gcmTagSize {
+		return ErrCiphertextCorrupted
+	}
+	final := n < len(dr.sealed)
+	if !final {
+		if _, err := dr.src.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+	nonce, aad := chunkNonceAndAAD(dr.iv, dr.index, final)
+	plain, err := dr.aead.Open(dr.out[:0], nonce, dr.sealed[:n], aad)
+	if err != nil {
+		return ErrCiphertextCorrupted
+	}
+	dr.out = plain
+	dr.index++
+	dr.done = final
+	return nil
+}
@@ -0,0 +1,116 @@
+package lingstorage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedEncryptionRoundTrip(t *testing.T) {
+	key := make([]byte, dataKeySize)
+	iv := make([]byte, gcmNonceSize)
+	_, _ = rand.Read(key)
+	_, _ = rand.Read(iv)
+	aead, err := newGCM(key)
+	require.NoError(t, err)
+
+	const chunkSize = 16
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 3*chunkSize + 7} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+
+		sealed, err := io.ReadAll(newEncryptReader(bytes.NewReader(plain), aead, iv, chunkSize))
+		require.NoError(t, err)
+		assert.Equal(t, encryptedSize(int64(size), chunkSize), int64(len(sealed)), "size %d", size)
+
+		opened, err := io.ReadAll(newDecryptReader(bytes.NewReader(sealed), aead, iv, chunkSize))
+		require.NoError(t, err)
+		assert.Equal(t, plain, opened, "size %d", size)
+
+		// 截断最后一个分块应被检测到
+		if size > chunkSize {
+			truncated := sealed[:chunkSize+gcmTagSize]
+			_, err = io.ReadAll(newDecryptReader(bytes.NewReader(truncated), aead, iv, chunkSize))
+			assert.ErrorIs(t, err, ErrCiphertextCorrupted)
+		}
+
+		// 篡改密文应被检测到
+		sealed[0] ^= 0xff
+		_, err = io.ReadAll(newDecryptReader(bytes.NewReader(sealed), aead, iv, chunkSize))
+		assert.ErrorIs(t, err, ErrCiphertextCorrupted)
+	}
+}
+
+func TestCryptoClientRoundTrip(t *testing.T) {
+	var stored []byte
+	var storedMeta map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/public/upload":
+			require.NoError(t, r.ParseMultipartForm(32<<20))
+			file, _, err := r.FormFile("file")
+			require.NoError(t, err)
+			defer file.Close()
+			stored, _ = io.ReadAll(file)
+			require.NoError(t, json.Unmarshal([]byte(r.FormValue("metadata")), &storedMeta))
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{"key": r.FormValue("key"), "size": len(stored)},
+			})
+		case strings.HasSuffix(r.URL.Path, "/download"):
+			for k, v := range storedMeta {
+				w.Header().Set("X-Meta-"+k, v)
+			}
+			w.Write(stored)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	masterKey := make([]byte, 32)
+	_, _ = rand.Read(masterKey)
+	keys, err := NewStaticKeyProvider("master-1", masterKey)
+	require.NoError(t, err)
+	cc := NewCryptoClient(client, keys)
+
+	plain := []byte(strings.Repeat("secret data ", 20000))
+	_, err = cc.UploadFromReader(&UploadFromReaderRequest{
+		Reader:   bytes.NewReader(plain),
+		Filename: "secret.txt",
+		Size:     int64(len(plain)),
+		Bucket:   "vault",
+		Key:      "secret.txt",
+		Metadata: map[string]string{"owner": "alice"},
+	})
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(stored, []byte("secret data")))
+	assert.Equal(t, "alice", storedMeta["owner"])
+	assert.Equal(t, CryptoAlgorithm, storedMeta[metaCryptoAlgorithm])
+
+	result, err := cc.Download(&DownloadRequest{Bucket: "vault", Key: "secret.txt"})
+	require.NoError(t, err)
+	defer result.Body.Close()
+	opened, err := io.ReadAll(result.Body)
+	require.NoError(t, err)
+	assert.Equal(t, plain, opened)
+	assert.Equal(t, int64(len(plain)), result.Size)
+
+	// 普通客户端下载到的是密文
+	raw, err := client.Download(&DownloadRequest{Bucket: "vault", Key: "secret.txt"})
+	require.NoError(t, err)
+	defer raw.Body.Close()
+	ciphertext, _ := io.ReadAll(raw.Body)
+	assert.Equal(t, stored, ciphertext)
+}
@@ -0,0 +1,37 @@
+package lingstorage
+
+import (
+	"context"
+	"io"
+)
+
+// DataTransport data plane carrying object content. Uploads and downloads use
+// the HTTP API unless Config.DataTransport is set, e.g. to a streaming gRPC
+// client of the deployment's data plane. Control plane calls (listing,
+// buckets, metadata, jobs) always use HTTP. Implementations report failures
+// as *APIError where possible, so callers can tell status codes apart
+type DataTransport interface {
+	Upload(ctx context.Context, upload *TransportUpload) (*UploadResult, error)
+	// Download OnProgress of req is applied by the client
+	Download(ctx context.Context, req *DownloadRequest) (*DownloadResult, error)
+}
+
+// TransportUpload validated upload handed to a DataTransport
+type TransportUpload struct {
+	Request  *UploadRequest // options of the upload, FilePath and OnProgress are already applied
+	Filename string
+	Size     int64 // -1 if unknown
+	Body     io.Reader
+}
+
+// uploadWithTransport upload through the configured data transport
+func (c *Client) uploadWithTransport(ctx context.Context, reader io.Reader, filename string, size int64, req *UploadRequest) (*UploadResult, error) {
+	defer c.metadata.invalidate(req.Bucket, req.Key)
+	counter := &progressReader{reader: reader, total: size}
+	result, err := c.config.DataTransport.Upload(ctx, &TransportUpload{Request: req, Filename: filename, Size: size, Body: counter})
+	if err != nil {
+		return nil, err
+	}
+	c.recordBytes(ctx, DirectionUpload, counter.read)
+	return result, nil
+}
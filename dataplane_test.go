@@ -0,0 +1,79 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTransport 内存数据通道
+type memoryTransport struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryTransport) Upload(ctx context.Context, upload *TransportUpload) (*UploadResult, error) {
+	data, err := io.ReadAll(upload.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[upload.Request.Bucket+"/"+upload.Request.Key] = data
+	return &UploadResult{Bucket: upload.Request.Bucket, Key: upload.Request.Key, Filename: upload.Filename, Size: int64(len(data))}, nil
+}
+
+func (m *memoryTransport) Download(ctx context.Context, req *DownloadRequest) (*DownloadResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[req.Bucket+"/"+req.Key]
+	if !ok {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+	}
+	return &DownloadResult{Body: io.NopCloser(bytes.NewReader(data)), Size: int64(len(data))}, nil
+}
+
+func TestDataTransport(t *testing.T) {
+	var httpCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpCalls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &memoryTransport{objects: make(map[string][]byte)}
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "k", DataTransport: transport})
+
+	result, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt", Bucket: "bkt", Key: "docs/a.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Size)
+	assert.Equal(t, []byte("hello"), transport.objects["bkt/docs/a.txt"])
+
+	var progress int64
+	download, err := client.Download(&DownloadRequest{Bucket: "bkt", Key: "docs/a.txt", OnProgress: func(downloaded, total int64) { progress = downloaded }})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(download.Body)
+	download.Body.Close()
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, int64(5), progress)
+
+	// 控制面请求仍走 HTTP
+	require.NoError(t, client.DeleteFile("bkt", "docs/a.txt"))
+	assert.Equal(t, 1, httpCalls)
+
+	stats := client.Stats()
+	assert.Equal(t, int64(5), stats.BytesUploaded)
+	assert.Equal(t, int64(5), stats.BytesDownloaded)
+
+	_, err = client.Download(&DownloadRequest{Bucket: "bkt", Key: "missing"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
@@ -0,0 +1,166 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// MaxDeleteKeysPerRequest keys removed by one bulk delete request, larger batches are split
+	MaxDeleteKeysPerRequest = 1000
+	// deleteFallbackConcurrency parallel DeleteFile calls against servers without the bulk API
+	deleteFallbackConcurrency = 8
+)
+
+// ErrEmptyPrefix DeletePrefix refused to delete a whole bucket without Force
+var ErrEmptyPrefix = errors.New("lingstorage: empty prefix matches the whole bucket")
+
+// DeleteError key that could not be deleted
+type DeleteError struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// DeleteFilesResult bulk delete result, missing keys count as deleted
+type DeleteFilesResult struct {
+	Deleted []string      `json:"deleted"`
+	Failed  []DeleteError `json:"failed"`
+}
+
+// DeletePrefixOptions options of DeletePrefix
+type DeletePrefixOptions struct {
+	Recursive  bool // also delete below nested "directories", else only the objects directly under prefix
+	MaxObjects int  // stop after this many objects, 0 means no limit
+	DryRun     bool // only list the keys that would be deleted
+	Force      bool // allow an empty prefix, which deletes the whole bucket
+}
+
+// DeletePrefixResult summary of DeletePrefix
+type DeletePrefixResult struct {
+	Deleted   []string      // deleted keys, or the keys that would be deleted with DryRun
+	Failed    []DeleteError // keys the server refused to delete
+	Truncated bool          // MaxObjects was reached before the listing ended
+}
+
+// DeleteFiles 批量删除文件, 每 MaxDeleteKeysPerRequest 个键一次请求;
+// 服务端不支持批量删除时逐个删除
+func (c *Client) DeleteFiles(bucket string, keys []string) (_ *DeleteFilesResult, err error) {
+	ctx, op := c.startOperation("DeleteFiles", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	return c.deleteKeys(ctx, bucket, keys)
+}
+
+// DeletePrefix 删除前缀下的所有对象, 按页列举并批量删除。
+// 空前缀会删除整个存储桶的对象, 需要设置 Force
+func (c *Client) DeletePrefix(bucket, prefix string, opts *DeletePrefixOptions) (_ *DeletePrefixResult, err error) {
+	ctx, op := c.startOperation("DeletePrefix", bucket, prefix)
+	defer func() { c.endOperation(op, err) }()
+	if opts == nil {
+		opts = &DeletePrefixOptions{}
+	}
+	if prefix == "" && !opts.Force {
+		return nil, fmt.Errorf("%w: set Force to empty bucket %s", ErrEmptyPrefix, bucket)
+	}
+
+	client := c.WithContext(ctx)
+	result := &DeletePrefixResult{}
+	req := &ListFilesRequest{Bucket: bucket, Prefix: prefix}
+	if !opts.Recursive {
+		req.Delimiter = "/"
+	}
+	for {
+		page, err := client.ListFiles(req)
+		if err != nil {
+			return result, fmt.Errorf("failed to list %s/%s: %w", bucket, prefix, err)
+		}
+		keys := make([]string, 0, len(page.Files))
+		for _, file := range page.Files {
+			if opts.MaxObjects > 0 && len(result.Deleted)+len(result.Failed)+len(keys) >= opts.MaxObjects {
+				result.Truncated = true
+				break
+			}
+			keys = append(keys, file.Key)
+		}
+		if opts.DryRun {
+			result.Deleted = append(result.Deleted, keys...)
+		} else if len(keys) > 0 {
+			deleted, err := c.deleteKeys(ctx, bucket, keys)
+			if err != nil {
+				return result, err
+			}
+			result.Deleted = append(result.Deleted, deleted.Deleted...)
+			result.Failed = append(result.Failed, deleted.Failed...)
+		}
+		if result.Truncated {
+			return result, nil
+		}
+		if !page.IsTruncated || page.NextMarker == "" || page.NextMarker == req.Marker {
+			return result, nil
+		}
+		if opts.MaxObjects > 0 && len(result.Deleted)+len(result.Failed) >= opts.MaxObjects {
+			result.Truncated = true
+			return result, nil
+		}
+		req.Marker = page.NextMarker
+	}
+}
+
+// deleteKeys delete keys in bulk requests, one by one against servers without the bulk API
+func (c *Client) deleteKeys(ctx context.Context, bucket string, keys []string) (*DeleteFilesResult, error) {
+	result := &DeleteFilesResult{}
+	defer func() {
+		for _, key := range keys {
+			c.metadata.invalidate(bucket, key)
+		}
+	}()
+	path := bucketPath(bucket) + "/delete"
+	for start := 0; start < len(keys); start += MaxDeleteKeysPerRequest {
+		end := start + MaxDeleteKeysPerRequest
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var data DeleteFilesResult
+		err := c.call(ctx, "POST", path, map[string]interface{}{"keys": keys[start:end]}, &data)
+		if isMissingEndpoint(err) {
+			c.deleteOneByOne(ctx, bucket, keys[start:], result)
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Deleted = append(result.Deleted, data.Deleted...)
+		result.Failed = append(result.Failed, data.Failed...)
+	}
+	return result, nil
+}
+
+// deleteOneByOne delete keys with one DeleteFile call per key
+func (c *Client) deleteOneByOne(ctx context.Context, bucket string, keys []string, result *DeleteFilesResult) {
+	client := c.WithContext(ctx)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, deleteFallbackConcurrency)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() { <-sem; wg.Done() }()
+			err := client.DeleteFile(bucket, key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && !isNotFound(err) {
+				result.Failed = append(result.Failed, DeleteError{Key: key, Error: err.Error()})
+				return
+			}
+			result.Deleted = append(result.Deleted, key)
+		}(key)
+	}
+	wg.Wait()
+	sort.Strings(result.Deleted)
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Key < result.Failed[j].Key })
+}
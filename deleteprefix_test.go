@@ -0,0 +1,82 @@
+package lingstorage_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletePrefix(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	bucket := lingstoragetest.DefaultBucket
+	for _, key := range []string{"logs/a.log", "logs/b.log", "logs/2024/c.log", "logs/2024/d.log", "keep.txt"} {
+		server.PutObject(bucket, key, []byte(key))
+	}
+
+	_, err := client.DeletePrefix(bucket, "", nil)
+	assert.ErrorIs(t, err, lingstorage.ErrEmptyPrefix)
+
+	result, err := client.DeletePrefix(bucket, "logs/", &lingstorage.DeletePrefixOptions{Recursive: true, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs/2024/c.log", "logs/2024/d.log", "logs/a.log", "logs/b.log"}, result.Deleted)
+	_, ok := server.Object(bucket, "logs/a.log")
+	assert.True(t, ok)
+
+	// 非递归只删除直接位于前缀下的对象
+	result, err = client.DeletePrefix(bucket, "logs/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs/a.log", "logs/b.log"}, result.Deleted)
+	_, ok = server.Object(bucket, "logs/2024/c.log")
+	assert.True(t, ok)
+
+	result, err = client.DeletePrefix(bucket, "logs/", &lingstorage.DeletePrefixOptions{Recursive: true, MaxObjects: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs/2024/c.log"}, result.Deleted)
+	assert.True(t, result.Truncated)
+
+	result, err = client.DeletePrefix(bucket, "", &lingstorage.DeletePrefixOptions{Recursive: true, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"keep.txt", "logs/2024/d.log"}, result.Deleted)
+	assert.False(t, result.Truncated)
+}
+
+func TestDeleteFilesFallback(t *testing.T) {
+	var single []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/public/buckets/bkt/delete":
+			// 旧版服务端没有批量删除接口
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "DELETE" && strings.HasSuffix(r.URL.Path, "/locked.txt"):
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "object locked"})
+		case r.Method == "DELETE" && strings.HasSuffix(r.URL.Path, "/gone.txt"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "DELETE":
+			single = append(single, r.URL.Path)
+			w.Write([]byte(`{"success":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+
+	result, err := client.DeleteFiles("bkt", []string{"a.txt", "gone.txt", "locked.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "gone.txt"}, result.Deleted)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "locked.txt", result.Failed[0].Key)
+	assert.Contains(t, result.Failed[0].Error, "object locked")
+	assert.Equal(t, []string{fmt.Sprintf("/api/public/files/%s/%s", "bkt", "a.txt")}, single)
+}
@@ -0,0 +1,302 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+const (
+	// DefaultDeltaBlockSize bytes per block compared by DeltaUpload
+	DefaultDeltaBlockSize int64 = 8 << 20
+	// maxDeltaPartSize bytes of changed blocks sent by one part upload
+	maxDeltaPartSize int64 = 64 << 20
+)
+
+// BlockChecksums md5 of the fixed size blocks of an object
+type BlockChecksums struct {
+	BlockSize int64    `json:"blockSize"`
+	Size      int64    `json:"size"`
+	ETag      string   `json:"etag"`
+	Blocks    []string `json:"blocks"` // hex md5 per block, the last one may be short
+}
+
+// DeltaUploadRequest upload a local file over an existing object
+type DeltaUploadRequest struct {
+	Bucket   string
+	Key      string
+	FilePath string
+	// BlockSize bytes per compared block, default 8MB. It is raised to the
+	// server minimum part size, and the server may answer with another size
+	BlockSize int64
+}
+
+// DeltaUploadResult outcome of a delta upload
+type DeltaUploadResult struct {
+	Key           string
+	Size          int64
+	ETag          string
+	Full          bool  // the whole file was uploaded: a new object, or the server lacks block checksums
+	UploadedBytes int64 // bytes sent to the server
+	CopiedBytes   int64 // bytes reused from the existing object
+}
+
+// deltaPart part of a delta upload, a range of the local file that is either
+// uploaded or copied from the same range of the existing object
+type deltaPart struct {
+	number     int
+	start, end int64 // both ends inclusive
+	copy       bool
+}
+
+// GetBlockChecksums 获取对象按 blockSize 分块的 md5, 服务端可能使用不同的分块大小
+func (c *Client) GetBlockChecksums(bucket, key string, blockSize int64) (_ *BlockChecksums, err error) {
+	ctx, op := c.startOperation("GetBlockChecksums", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	q := url.Values{}
+	if blockSize > 0 {
+		q.Set("blockSize", strconv.FormatInt(blockSize, 10))
+	}
+	var result BlockChecksums
+	if err := c.call(ctx, "GET", withQuery(objectPath(bucket, key)+"/blocks", q), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeltaUpload 以 rsync 的方式更新大文件: 比较本地与远端的分块 md5, 只上传变化的块,
+// 未变化的块通过分片拷贝从原对象复用. 分块按固定偏移对齐, 适合原地修改的
+// 虚拟机镜像和数据库转储; 对象不存在或服务端不支持时整体上传
+func (c *Client) DeltaUpload(req *DeltaUploadRequest) (_ *DeltaUploadResult, err error) {
+	ctx, op := c.startOperation("DeltaUpload", req.Bucket, req.Key)
+	defer func() { c.endOperation(op, err) }()
+	file, err := os.Open(req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	size := stat.Size()
+	client := c.WithContext(ctx)
+	full := func() (*DeltaUploadResult, error) {
+		result, err := client.UploadFile(&UploadRequest{FilePath: req.FilePath, Bucket: req.Bucket, Key: req.Key})
+		if err != nil {
+			return nil, err
+		}
+		return &DeltaUploadResult{Key: result.Key, Size: size, ETag: result.ETag, Full: true, UploadedBytes: size}, nil
+	}
+	if !c.config.SkipCapabilityCheck && !c.Supports(FeatureMultipart) {
+		return full()
+	}
+
+	info, err := client.GetFileInfo(req.Bucket, req.Key)
+	if isNotFound(err) {
+		return full()
+	}
+	if err != nil {
+		return nil, err
+	}
+	blockSize := req.BlockSize
+	if blockSize <= 0 {
+		blockSize = DefaultDeltaBlockSize
+	}
+	if caps := c.capabilities.cached(); caps != nil && blockSize < caps.Limits.MinPartSize {
+		blockSize = caps.Limits.MinPartSize
+	}
+	remote, err := client.GetBlockChecksums(req.Bucket, req.Key, blockSize)
+	if isMissingEndpoint(err) {
+		return full()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if remote.BlockSize <= 0 {
+		return nil, fmt.Errorf("invalid block size %d of %s/%s", remote.BlockSize, req.Bucket, req.Key)
+	}
+	local, err := blockMD5s(file, size, remote.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, copied := c.planDelta(local, remote, size)
+	result := &DeltaUploadResult{Key: req.Key, Size: size, CopiedBytes: copied, UploadedBytes: size - copied}
+	if size == remote.Size && copied == size {
+		result.ETag = remote.ETag
+		return result, nil
+	}
+	maxParts := maxCopyParts
+	if caps := c.capabilities.cached(); caps != nil && caps.Limits.MaxParts > 0 {
+		maxParts = caps.Limits.MaxParts
+	}
+	if len(parts) > maxParts || size == 0 {
+		return full()
+	}
+	if result.ETag, err = c.patchObject(ctx, req, info, remote.ETag, file, parts); err != nil {
+		return nil, err
+	}
+	c.metadata.invalidate(req.Bucket, req.Key)
+	return result, nil
+}
+
+// blockMD5s hex md5 of every blockSize bytes of the file
+func blockMD5s(file io.ReaderAt, size, blockSize int64) ([]string, error) {
+	var sums []string
+	for start := int64(0); start < size; start += blockSize {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(file, start, blockSize)); err != nil {
+			return nil, fmt.Errorf("failed to hash file: %w", err)
+		}
+		sums = append(sums, hex.EncodeToString(h.Sum(nil)))
+	}
+	return sums, nil
+}
+
+// planDelta parts rebuilding the local file from changed blocks and ranges of
+// the remote object, returns them with the bytes copied from the remote
+func (c *Client) planDelta(local []string, remote *BlockChecksums, size int64) ([]deltaPart, int64) {
+	copyLimit := c.config.CopyPartSize
+	if copyLimit <= 0 {
+		copyLimit = DefaultCopyPartSize
+	}
+	var parts []deltaPart
+	var copied int64
+	for i, sum := range local {
+		start := int64(i) * remote.BlockSize
+		end := start + remote.BlockSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		same := i < len(remote.Blocks) && end < remote.Size && sum == remote.Blocks[i]
+		if same {
+			copied += end - start + 1
+		}
+		limit := maxDeltaPartSize
+		if same {
+			limit = copyLimit
+		}
+		if n := len(parts); n > 0 && parts[n-1].copy == same && end-parts[n-1].start < limit {
+			parts[n-1].end = end
+			continue
+		}
+		parts = append(parts, deltaPart{number: len(parts) + 1, start: start, end: end, copy: same})
+	}
+	return parts, copied
+}
+
+// patchObject replace the object with a multipart upload of parts, copied
+// parts are pinned to etag so a concurrent overwrite fails the upload
+func (c *Client) patchObject(ctx context.Context, req *DeltaUploadRequest, info *FileInfo, etag string, file io.ReaderAt, parts []deltaPart) (string, error) {
+	uploadPath := objectPath(req.Bucket, req.Key) + "/uploads"
+	initiate := map[string]interface{}{
+		"contentType": info.ContentType,
+		"metadata":    info.Metadata,
+	}
+	if info.StorageClass != "" {
+		initiate["storageClass"] = info.StorageClass
+	}
+	var upload struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := c.call(ctx, "POST", uploadPath, initiate, &upload); err != nil {
+		return "", fmt.Errorf("failed to start delta upload: %w", err)
+	}
+	uploadPath += "/" + upload.UploadID
+
+	var completed struct {
+		ETag string `json:"etag"`
+	}
+	done := make([]CopyPart, 0, len(parts))
+	var err error
+	for _, part := range parts {
+		var partETag string
+		if part.copy {
+			partETag, err = c.copyDeltaPart(ctx, uploadPath, req, etag, part)
+		} else {
+			partETag, err = c.uploadDeltaPart(ctx, uploadPath, file, part)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to upload part %d: %w", part.number, err)
+			break
+		}
+		done = append(done, CopyPart{PartNumber: part.number, ETag: partETag})
+	}
+	if err == nil {
+		if err = c.call(ctx, "POST", uploadPath+"/complete", map[string]interface{}{"parts": done}, &completed); err != nil {
+			err = fmt.Errorf("failed to complete delta upload: %w", err)
+		}
+	}
+	if err != nil {
+		if abortErr := c.call(context.WithoutCancel(ctx), "DELETE", uploadPath, nil, nil); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to abort delta upload: %w", abortErr))
+		}
+		return "", err
+	}
+	return completed.ETag, nil
+}
+
+func (c *Client) copyDeltaPart(ctx context.Context, uploadPath string, req *DeltaUploadRequest, etag string, part deltaPart) (string, error) {
+	body := map[string]interface{}{
+		"srcBucket": req.Bucket,
+		"srcKey":    req.Key,
+		"range":     fmt.Sprintf("bytes=%d-%d", part.start, part.end),
+	}
+	if etag != "" {
+		body["ifMatch"] = etag
+	}
+	var result struct {
+		ETag string `json:"etag"`
+	}
+	if err := c.call(ctx, "PUT", fmt.Sprintf("%s/parts/%d/copy", uploadPath, part.number), body, &result); err != nil {
+		return "", err
+	}
+	return result.ETag, nil
+}
+
+func (c *Client) uploadDeltaPart(ctx context.Context, uploadPath string, file io.ReaderAt, part deltaPart) (string, error) {
+	data := make([]byte, part.end-part.start+1)
+	if _, err := file.ReadAt(data, part.start); err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return c.uploadPart(ctx, uploadPath, part.number, data)
+}
+
+// uploadPart send data as part number of the multipart upload at uploadPath, returns the part ETag
+func (c *Client) uploadPart(ctx context.Context, uploadPath string, number int, data []byte) (string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "PUT", c.apiURL(fmt.Sprintf("%s/parts/%d", uploadPath, number)), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set(constants.CONTENT_TYPE, "application/octet-stream")
+	if err := c.setHeaders(httpReq, data); err != nil {
+		return "", err
+	}
+	resp, err := c.doRequestWithRetry(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", c.handleErrorResponse(resp)
+	}
+	c.recordBytes(ctx, DirectionUpload, int64(len(data)))
+	var result struct {
+		ETag string `json:"etag"`
+	}
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return "", err
+	}
+	return result.ETag, nil
+}
@@ -0,0 +1,164 @@
+package lingstorage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deltaServer single object store with block checksums and multipart uploads
+type deltaServer struct {
+	mu       sync.Mutex
+	data     []byte // nil when the object does not exist
+	etag     string
+	noBlocks bool
+	parts    map[int][]byte
+	received int64 // bytes of uploads and uploaded parts
+}
+
+func (s *deltaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	const object = "/api/public/files/bkt/disk.img"
+	const upload = object + "/uploads/u1"
+	reply := func(data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": data})
+	}
+	switch {
+	case r.URL.Path == "/api/public/info":
+		reply(map[string]interface{}{"features": []string{FeatureMultipart}})
+	case r.URL.Path == "/api/public/upload":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.data, _ = io.ReadAll(file)
+		s.received += int64(len(s.data))
+		s.etag = fmt.Sprintf("%x", md5.Sum(s.data))
+		reply(map[string]interface{}{"key": "disk.img", "etag": s.etag})
+	case s.data == nil && strings.HasPrefix(r.URL.Path, object):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"success":false,"message":"file not found"}`))
+	case r.URL.Path == object+"/info":
+		reply(map[string]interface{}{"key": "disk.img", "size": len(s.data), "etag": s.etag, "contentType": "application/octet-stream"})
+	case r.URL.Path == object+"/blocks" && !s.noBlocks:
+		blockSize, _ := strconv.Atoi(r.URL.Query().Get("blockSize"))
+		var blocks []string
+		for start := 0; start < len(s.data); start += blockSize {
+			end := start + blockSize
+			if end > len(s.data) {
+				end = len(s.data)
+			}
+			sum := md5.Sum(s.data[start:end])
+			blocks = append(blocks, hex.EncodeToString(sum[:]))
+		}
+		reply(BlockChecksums{BlockSize: int64(blockSize), Size: int64(len(s.data)), ETag: s.etag, Blocks: blocks})
+	case r.URL.Path == object+"/uploads" && r.Method == "POST":
+		s.parts = map[int][]byte{}
+		reply(map[string]string{"uploadId": "u1"})
+	case strings.HasPrefix(r.URL.Path, upload+"/parts/") && strings.HasSuffix(r.URL.Path, "/copy"):
+		number, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, upload+"/parts/"), "/copy"))
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["ifMatch"] != s.etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var start, end int
+		fmt.Sscanf(body["range"], "bytes=%d-%d", &start, &end)
+		s.parts[number] = append([]byte(nil), s.data[start:end+1]...)
+		reply(map[string]string{"etag": "p" + strconv.Itoa(number)})
+	case strings.HasPrefix(r.URL.Path, upload+"/parts/"):
+		number, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, upload+"/parts/"))
+		s.parts[number], _ = io.ReadAll(r.Body)
+		s.received += int64(len(s.parts[number]))
+		reply(map[string]string{"etag": "p" + strconv.Itoa(number)})
+	case r.URL.Path == upload+"/complete":
+		numbers := make([]int, 0, len(s.parts))
+		for number := range s.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, s.parts[number]...)
+		}
+		s.data = data
+		s.etag = fmt.Sprintf("%x-%d", md5.Sum(data), len(numbers))
+		reply(map[string]string{"etag": s.etag})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDeltaUpload(t *testing.T) {
+	srv := &deltaServer{}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	path := filepath.Join(t.TempDir(), "disk.img")
+	original := []byte("aaaabbbbccccddddeeeeff")
+	require.NoError(t, os.WriteFile(path, original, 0o644))
+	req := &DeltaUploadRequest{Bucket: "bkt", Key: "disk.img", FilePath: path, BlockSize: 4}
+
+	// 对象不存在时整体上传
+	result, err := client.DeltaUpload(req)
+	require.NoError(t, err)
+	assert.True(t, result.Full)
+	assert.EqualValues(t, len(original), srv.received)
+
+	result, err = client.DeltaUpload(req)
+	require.NoError(t, err)
+	assert.False(t, result.Full)
+	assert.Zero(t, result.UploadedBytes)
+	assert.EqualValues(t, len(original), srv.received)
+
+	// 修改中间一个块并在末尾追加
+	modified := []byte("aaaabbbbCCCCddddeeeeffgg")
+	require.NoError(t, os.WriteFile(path, modified, 0o644))
+	result, err = client.DeltaUpload(req)
+	require.NoError(t, err)
+	assert.False(t, result.Full)
+	assert.EqualValues(t, 8, result.UploadedBytes)
+	assert.EqualValues(t, 16, result.CopiedBytes)
+	assert.Equal(t, modified, srv.data)
+	assert.Equal(t, srv.etag, result.ETag)
+	assert.EqualValues(t, len(original)+8, srv.received)
+
+	// 服务端不支持分块校验时整体上传
+	srv.noBlocks = true
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), 24), 0o644))
+	result, err = client.DeltaUpload(req)
+	require.NoError(t, err)
+	assert.True(t, result.Full)
+	assert.Equal(t, bytes.Repeat([]byte("x"), 24), srv.data)
+}
+
+func TestPlanDelta(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "http://localhost", CopyPartSize: 8})
+	remote := &BlockChecksums{BlockSize: 4, Size: 20, Blocks: []string{"a", "b", "c", "d", "e"}}
+	parts, copied := client.planDelta([]string{"a", "b", "c", "X", "Y", "e", "f"}, remote, 26)
+	assert.Equal(t, []deltaPart{
+		{number: 1, start: 0, end: 7, copy: true},
+		{number: 2, start: 8, end: 11, copy: true}, // 拷贝分片不超过 CopyPartSize
+		{number: 3, start: 12, end: 25},            // 块按偏移比较, 移位后的 "e" 视为变化
+	}, parts)
+	assert.EqualValues(t, 12, copied)
+}
@@ -0,0 +1,134 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// DialContextFunc dial function of the transport, same signature as net.Dialer.DialContext
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// HostResolver looks up the addresses of a host, *net.Resolver implements it
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// CachingResolver HostResolver caching lookups for a TTL. When a refresh
+// fails the expired addresses keep being served, so a flaky resolver does not
+// fail requests to an endpoint whose addresses rarely change
+type CachingResolver struct {
+	resolver HostResolver
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+	now     func() time.Time
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+	next    int // rotates the first address tried between dials
+}
+
+// NewCachingResolver cache lookups of resolver for ttl, nil resolver uses net.DefaultResolver
+func NewCachingResolver(resolver HostResolver, ttl time.Duration) *CachingResolver {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &CachingResolver{resolver: resolver, ttl: ttl, entries: make(map[string]*dnsEntry), now: time.Now}
+}
+
+// LookupHost cached addresses of host, IP literals are returned as is
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	r.mu.Lock()
+	entry := r.entries[host]
+	if entry != nil && r.now().Before(entry.expires) {
+		addrs := entry.addrs
+		r.mu.Unlock()
+		return addrs, nil
+	}
+	r.mu.Unlock()
+
+	addrs, err := r.resolver.LookupHost(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if entry != nil {
+			return entry.addrs, nil
+		}
+		return nil, err
+	}
+	r.entries[host] = &dnsEntry{addrs: addrs, expires: r.now().Add(r.ttl)}
+	return addrs, nil
+}
+
+// Flush drop cached addresses of hosts, all hosts when none is given
+func (r *CachingResolver) Flush(hosts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(hosts) == 0 {
+		r.entries = make(map[string]*dnsEntry)
+		return
+	}
+	for _, host := range hosts {
+		delete(r.entries, host)
+	}
+}
+
+// DialContext dial function resolving hosts through the cache, then dialing
+// the addresses with dial in turn until one connects. Nil dial uses a net.Dialer
+func (r *CachingResolver) DialContext(dial DialContextFunc) DialContextFunc {
+	if dial == nil {
+		dial = defaultDialer().DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		for _, ip := range r.rotate(host, addrs) {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// rotate addrs starting at the next address of host, spreading new connections
+func (r *CachingResolver) rotate(host string, addrs []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := r.entries[host]
+	if entry == nil || len(addrs) < 2 {
+		return addrs
+	}
+	start := entry.next % len(addrs)
+	entry.next++
+	return append(append([]string{}, addrs[start:]...), addrs[:start]...)
+}
+
+// defaultDialer dialer with the settings of http.DefaultTransport
+func defaultDialer() *net.Dialer {
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+}
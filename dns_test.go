@@ -0,0 +1,130 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	mu      sync.Mutex
+	addrs   map[string][]string
+	err     error
+	lookups int
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.addrs[host], nil
+}
+
+func TestCachingResolver(t *testing.T) {
+	fake := &fakeResolver{addrs: map[string][]string{"storage.test": {"10.0.0.1", "10.0.0.2"}}}
+	resolver := NewCachingResolver(fake, time.Minute)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		addrs, err := resolver.LookupHost(context.Background(), "storage.test")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	}
+	assert.Equal(t, 1, fake.lookups)
+
+	// IP 地址不查询
+	addrs, err := resolver.LookupHost(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+	assert.Equal(t, 1, fake.lookups)
+
+	// 过期后重新查询，查询失败时继续使用过期地址
+	now = now.Add(2 * time.Minute)
+	fake.err = errors.New("timeout")
+	addrs, err = resolver.LookupHost(context.Background(), "storage.test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	assert.Equal(t, 2, fake.lookups)
+
+	_, err = resolver.LookupHost(context.Background(), "other.test")
+	assert.Error(t, err)
+
+	// 清除缓存后无法回退
+	resolver.Flush("storage.test")
+	_, err = resolver.LookupHost(context.Background(), "storage.test")
+	assert.Error(t, err)
+
+	fake.err = nil
+	_, err = resolver.LookupHost(context.Background(), "missing.test")
+	assert.Error(t, err)
+}
+
+func TestCachingResolverDialFallback(t *testing.T) {
+	fake := &fakeResolver{addrs: map[string][]string{"storage.test": {"10.0.0.1", "10.0.0.2"}}}
+	resolver := NewCachingResolver(fake, time.Minute)
+	var dialed []string
+	dial := resolver.DialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr == "10.0.0.2:443" {
+			client, server := net.Pipe()
+			server.Close()
+			return client, nil
+		}
+		return nil, errors.New("unreachable")
+	})
+
+	conn, err := dial(context.Background(), "tcp", "storage.test:443")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"10.0.0.1:443", "10.0.0.2:443"}, dialed)
+
+	// 下一次从另一个地址开始
+	dialed = nil
+	conn, err = dial(context.Background(), "tcp", "storage.test:443")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"10.0.0.2:443"}, dialed)
+}
+
+func TestClientDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.Write([]byte(`{"success":true,"data":{"version":"1"}}`))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+
+	fake := &fakeResolver{addrs: map[string][]string{"storage.test": {"127.0.0.1"}}}
+	var dials int
+	client := NewClient(&Config{
+		BaseURL:     "http://storage.test:" + port,
+		RetryCount:  -1,
+		Resolver:    fake,
+		DNSCacheTTL: time.Minute,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	})
+	for i := 0; i < 3; i++ {
+		info, err := client.ServerInfo()
+		require.NoError(t, err)
+		assert.Equal(t, "1", info.Version)
+	}
+	assert.Equal(t, 1, fake.lookups)
+	assert.Equal(t, 3, dials)
+}
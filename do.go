@@ -0,0 +1,57 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// Do send a request to a server endpoint the SDK has no method for yet, with
+// the client's auth, headers, hooks, retries and failover applied. path is
+// relative to BaseURL, e.g. "/api/public/buckets"; a non-nil body is sent as
+// JSON. The response is returned whatever its status and the caller must close
+// its body. Bodies other than *bytes.Buffer, *bytes.Reader and *strings.Reader
+// are not retried unless SignRequests is set, which buffers them to sign
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body io.Reader) (_ *http.Response, err error) {
+	if ctx == nil {
+		ctx = c.context()
+	}
+	ctx, op := c.WithContext(ctx).startOperation("Do", "", "")
+	defer func() { c.endOperation(op, err) }()
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + query.Encode()
+	}
+
+	var signed []byte
+	if body != nil && c.config.SignRequests {
+		if signed, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = bytes.NewReader(signed)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.apiURL(path), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set(constants.CONTENT_TYPE, "application/json")
+	}
+	if err := c.setHeaders(httpReq, signed); err != nil {
+		return nil, err
+	}
+	return c.doRequestWithRetry(httpReq)
+}
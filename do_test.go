@@ -0,0 +1,81 @@
+package lingstorage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get(constants.XAPIKEY))
+		switch r.URL.Path {
+		case "/api/public/custom/report":
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "7", r.URL.Query().Get("days"))
+			assert.Equal(t, "1", r.URL.Query().Get("v"))
+			w.Write([]byte(`{"success":true,"data":{"rows":3}}`))
+		case "/api/public/custom/action":
+			attempts++
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, `{"run":true}`, string(body))
+			assert.Equal(t, "application/json", r.Header.Get(constants.CONTENT_TYPE))
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: 1})
+	ctx := context.Background()
+
+	resp, err := client.Do(ctx, "GET", "/api/public/custom/report?v=1", url.Values{"days": {"7"}}, nil)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.JSONEq(t, `{"success":true,"data":{"rows":3}}`, string(data))
+
+	// 可重放的 body 在重试时重新发送
+	resp, err = client.Do(ctx, "POST", "api/public/custom/action", nil, strings.NewReader(`{"run":true}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+
+	// 非 2xx 响应原样返回
+	resp, err = client.Do(ctx, "DELETE", "/api/public/custom/unknown", nil, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.EqualValues(t, 3, client.Stats().Operations)
+}
+
+func TestDoSigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"a":1}`, string(body))
+		expected := Sign("secret", r.Method, signedPath(r.URL), body, r.Header.Get(constants.XTIMESTAMP), r.Header.Get(constants.XNONCE))
+		assert.Equal(t, expected, r.Header.Get(constants.XSIGNATURE))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "key", APISecret: "secret", SignRequests: true, RetryCount: -1})
+
+	resp, err := client.Do(context.Background(), "PUT", "/api/public/custom", nil, io.NopCloser(strings.NewReader(`{"a":1}`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
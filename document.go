@@ -0,0 +1,52 @@
+package lingstorage
+
+import "fmt"
+
+// FormatPDF pdf output of document conversion
+const FormatPDF = "pdf"
+
+// ConvertedArtifact object generated by a document conversion
+type ConvertedArtifact struct {
+	Key  string `json:"key"`
+	URL  string `json:"url"`
+	Page int    `json:"page,omitempty"` // 1 based page number of image outputs
+	Size int64  `json:"size"`
+}
+
+// DocumentConversion result of ConvertDocument
+type DocumentConversion struct {
+	JobID     string              `json:"jobId,omitempty"` // set when the server converted asynchronously
+	Format    string              `json:"format"`
+	Artifacts []ConvertedArtifact `json:"artifacts"`
+}
+
+// Keys keys of the generated artifacts, in page order
+func (d *DocumentConversion) Keys() []string {
+	keys := make([]string, len(d.Artifacts))
+	for i, a := range d.Artifacts {
+		keys[i] = a.Key
+	}
+	return keys
+}
+
+// ConvertDocument convert an office document to pdf, or a pdf to page images
+// (png, jpeg or webp). Large documents are converted by a job, which is waited for
+func (c *Client) ConvertDocument(bucket, key, targetFormat string) (_ *DocumentConversion, err error) {
+	ctx, op := c.startOperation("ConvertDocument", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	switch targetFormat {
+	case FormatPDF, FormatPNG, FormatJPEG, FormatWebP:
+	default:
+		return nil, fmt.Errorf("unsupported document target format: %s", targetFormat)
+	}
+	body := map[string]string{"targetFormat": targetFormat}
+	result := &DocumentConversion{Format: targetFormat}
+	jobID, err := c.callJob(ctx, "POST", objectPath(bucket, key)+"/convert", body, result)
+	if err != nil {
+		return nil, err
+	}
+	if jobID != "" {
+		result.JobID = jobID
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/docs/a.docx/convert":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "pdf", body["targetFormat"])
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"format":    "pdf",
+				"artifacts": []map[string]interface{}{{"key": "a.pdf"}},
+			}})
+		case "/api/public/files/docs/big.pdf/convert":
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"jobId": "job-1"}})
+		case "/api/public/jobs/job-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"id": "job-1", "status": "succeeded",
+				"result": map[string]interface{}{"artifacts": []map[string]interface{}{{"key": "big-1.png", "page": 1}, {"key": "big-2.png", "page": 2}}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	conversion, err := client.ConvertDocument("docs", "a.docx", FormatPDF)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf"}, conversion.Keys())
+
+	conversion, err = client.ConvertDocument("docs", "big.pdf", FormatPNG)
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", conversion.JobID)
+	assert.Equal(t, []string{"big-1.png", "big-2.png"}, conversion.Keys())
+
+	_, err = client.ConvertDocument("docs", "a.docx", "odt")
+	assert.Error(t, err)
+}
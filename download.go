@@ -0,0 +1,179 @@
+package lingstorage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// DownloadRequest download request
+type DownloadRequest struct {
+	Bucket          string                        // bucket name
+	Key             string                        // file key
+	SSECustomerKey  []byte                        // customer provided key the object was uploaded with
+	Range           string                        // byte range such as "bytes=0-1023", the result is partial
+	OnProgress      func(downloaded, total int64) // download progress callback
+	OnProgressCheck ProgressFunc                  // like OnProgress, returning an error aborts reading Body
+}
+
+// DownloadResult download result, caller must close Body
+type DownloadResult struct {
+	Body         io.ReadCloser
+	Size         int64 // -1 if unknown
+	ContentType  string
+	ETag         string
+	LastModified time.Time // zero if unknown
+	ContentRange string    // set when a Range was served, e.g. "bytes 0-1023/4096"
+	Metadata     map[string]string
+
+	ServerSideEncryption string // AES256, kms or empty if not encrypted
+	SSEKMSKeyID          string
+
+	Timing *Timing // set when Config.CollectTiming is enabled, complete once Body is closed
+}
+
+// Download download file content
+func (c *Client) Download(req *DownloadRequest) (_ *DownloadResult, err error) {
+	ctx, op := c.startOperation("Download", req.Bucket, req.Key)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateSSE("", req.SSECustomerKey); err != nil {
+		return nil, err
+	}
+	if c.config.DataTransport != nil {
+		if err := c.validateNames(op); err != nil {
+			return nil, err
+		}
+		result, err := c.config.DataTransport.Download(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		c.recordBytes(ctx, DirectionDownload, result.Size)
+		if req.OnProgress != nil || req.OnProgressCheck != nil {
+			result.Body = &readCloser{
+				Reader: &progressReader{reader: result.Body, total: result.Size, callback: req.OnProgress, check: req.OnProgressCheck},
+				Closer: result.Body,
+			}
+		}
+		return result, nil
+	}
+	httpReq, err := c.newRequest(ctx, "GET", objectPath(req.Bucket, req.Key)+"/download", nil)
+	if err != nil {
+		return nil, err
+	}
+	setSSEHeaders(httpReq.Header, "", "", req.SSECustomerKey)
+	if req.Range != "" {
+		httpReq.Header.Set(constants.RANGE, req.Range)
+	}
+
+	resp, err := c.doRequestWithRetry(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		if err := quarantineError(resp, req.Bucket, req.Key); err != nil {
+			return nil, err
+		}
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	c.recordBytes(ctx, DirectionDownload, resp.ContentLength)
+	result := &DownloadResult{
+		Body:        resp.Body,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get(constants.CONTENT_TYPE),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+		Metadata:    metadataFromHeader(resp.Header),
+
+		ServerSideEncryption: resp.Header.Get(constants.XSSE),
+		SSEKMSKeyID:          resp.Header.Get(constants.XSSEKMSKEYID),
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		result.ContentRange = resp.Header.Get(constants.CONTENT_RANGE)
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get(constants.LAST_MODIFIED)); err == nil {
+		result.LastModified = lastModified
+	}
+	if req.OnProgress != nil || req.OnProgressCheck != nil {
+		result.Body = &readCloser{
+			Reader: &progressReader{
+				reader:   resp.Body,
+				total:    resp.ContentLength,
+				callback: req.OnProgress,
+				check:    req.OnProgressCheck,
+			},
+			Closer: resp.Body,
+		}
+	}
+
+	if timing := operationTiming(ctx); timing != nil {
+		result.Timing = timing
+		result.Body = &timedBody{ReadCloser: result.Body, timing: timing, opStart: op.start, start: time.Now()}
+	}
+
+	return result, nil
+}
+
+// DownloadFile download file to local path
+func (c *Client) DownloadFile(bucket, key, filePath string) error {
+	result, err := c.Download(&DownloadRequest{Bucket: bucket, Key: key})
+	if err != nil {
+		return err
+	}
+	defer result.Body.Close()
+
+	return writeFile(filePath, result.Body)
+}
+
+// writeFile write reader to path through a temp file, so a failed download never leaves a partial file
+func writeFile(filePath string, r io.Reader) error {
+	if dir := filepath.Dir(filePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	return nil
+}
+
+// metadataFromHeader collect X-Meta-* headers, keys are lower cased
+func metadataFromHeader(header http.Header) map[string]string {
+	var metadata map[string]string
+	for name, values := range header {
+		if !strings.HasPrefix(name, constants.XMETAPREFIX) || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(strings.TrimPrefix(name, constants.XMETAPREFIX))] = values[0]
+	}
+	return metadata
+}
+
+// readCloser combine reader and closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
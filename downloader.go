@@ -0,0 +1,199 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// DefaultDownloadPartSize bytes fetched by one ranged request of the Downloader
+	DefaultDownloadPartSize = 5 * 1024 * 1024
+	// DefaultDownloadConcurrency parallel ranged requests of the Downloader
+	DefaultDownloadConcurrency = 5
+)
+
+// Downloader parallel ranged downloader into an io.WriterAt, modeled on the
+// AWS s3manager download manager
+type Downloader struct {
+	Client      *Client
+	PartSize    int64 // default 5MB
+	Concurrency int   // default 5
+
+	// Adaptive measures the throughput of completed parts and tunes part size
+	// and concurrency within the bounds below; PartSize and Concurrency are the
+	// starting point
+	Adaptive       bool
+	MinPartSize    int64 // default 1MB
+	MaxPartSize    int64 // default 64MB
+	MaxConcurrency int   // default 16
+	// OnTune called after each adjustment of the adaptive mode
+	OnTune func(partSize int64, concurrency int)
+}
+
+// NewDownloader downloader with defaults, options may override them
+func NewDownloader(client *Client, options ...func(d *Downloader)) *Downloader {
+	d := &Downloader{Client: client, PartSize: DefaultDownloadPartSize, Concurrency: DefaultDownloadConcurrency}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Download download an object into w, returns the number of bytes written
+func (d *Downloader) Download(w io.WriterAt, bucket, key string, options ...func(d *Downloader)) (int64, error) {
+	return d.DownloadWithContext(context.Background(), w, bucket, key, options...)
+}
+
+// DownloadWithContext download an object into w, parts are fetched concurrently
+// and written at their offsets. The download fails if the object changes meanwhile
+func (d *Downloader) DownloadWithContext(ctx context.Context, w io.WriterAt, bucket, key string, options ...func(d *Downloader)) (int64, error) {
+	cfg := *d
+	for _, option := range options {
+		option(&cfg)
+	}
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = DefaultDownloadPartSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultDownloadConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := cfg.Client.WithContext(ctx)
+
+	info, err := client.GetFileInfo(bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	if info.Size == 0 {
+		return 0, nil
+	}
+
+	tuner := cfg.tuner()
+	var (
+		mu       sync.Mutex
+		cond     = sync.NewCond(&mu)
+		inflight int
+		firstErr error
+		written  int64
+		wg       sync.WaitGroup
+	)
+	for offset := int64(0); offset < info.Size; {
+		partSize, concurrency := tuner.settings()
+		mu.Lock()
+		for inflight >= concurrency && firstErr == nil && ctx.Err() == nil {
+			cond.Wait()
+			// the tuner may have changed the limit while waiting
+			partSize, concurrency = tuner.settings()
+		}
+		if firstErr != nil || ctx.Err() != nil {
+			mu.Unlock()
+			break
+		}
+		inflight++
+		mu.Unlock()
+
+		end := offset + partSize - 1
+		if end >= info.Size {
+			end = info.Size - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			n, err := downloadPart(client, w, bucket, key, info.ETag, start, end)
+			if err == nil {
+				tuner.record(n)
+			}
+			mu.Lock()
+			inflight--
+			written += n
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			cond.Broadcast()
+			mu.Unlock()
+		}(offset, end)
+		offset = end + 1
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return written, firstErr
+	}
+	return written, ctx.Err()
+}
+
+// tuner part settings of a download, fixed unless Adaptive is set
+func (d *Downloader) tuner() partTuner {
+	if !d.Adaptive {
+		return fixedTuner{partSize: d.PartSize, concurrency: d.Concurrency}
+	}
+	minPart, maxPart, maxCon := d.MinPartSize, d.MaxPartSize, d.MaxConcurrency
+	if minPart <= 0 {
+		minPart = DefaultAdaptiveMinPartSize
+	}
+	if maxPart <= 0 {
+		maxPart = DefaultAdaptiveMaxPartSize
+	}
+	if maxPart < minPart {
+		maxPart = minPart
+	}
+	if maxCon <= 0 {
+		maxCon = DefaultAdaptiveMaxConcurrency
+	}
+	return newAdaptiveTuner(d.PartSize, minPart, maxPart, d.Concurrency, maxCon, d.OnTune)
+}
+
+// downloadPart fetch bytes [start, end] and write them at start
+func downloadPart(client *Client, w io.WriterAt, bucket, key, etag string, start, end int64) (int64, error) {
+	result, err := client.Download(&DownloadRequest{Bucket: bucket, Key: key, Range: fmt.Sprintf("bytes=%d-%d", start, end)})
+	if err != nil {
+		return 0, err
+	}
+	defer result.Body.Close()
+	if etag != "" && result.ETag != "" && result.ETag != etag {
+		return 0, fmt.Errorf("object %s/%s changed during download", bucket, key)
+	}
+	if result.ContentRange == "" && start > 0 {
+		return 0, fmt.Errorf("server ignored range request for %s/%s", bucket, key)
+	}
+	buf := make([]byte, end-start+1)
+	n, err := io.ReadFull(result.Body, buf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read part at %d: %w", start, err)
+	}
+	if _, err := w.WriteAt(buf[:n], start); err != nil {
+		return 0, fmt.Errorf("failed to write part at %d: %w", start, err)
+	}
+	return int64(n), nil
+}
+
+// WriteAtBuffer in-memory io.WriterAt, growing as needed
+type WriteAtBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewWriteAtBuffer buffer starting with buf
+func NewWriteAtBuffer(buf []byte) *WriteAtBuffer {
+	return &WriteAtBuffer{buf: buf}
+}
+
+// WriteAt write p at off, safe for concurrent use
+func (b *WriteAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if end := off + int64(len(p)); end > int64(len(b.buf)) {
+		b.buf = append(b.buf, make([]byte, end-int64(len(b.buf)))...)
+	}
+	return copy(b.buf[off:], p), nil
+}
+
+// Bytes buffer content
+func (b *WriteAtBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf
+}
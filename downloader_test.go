@@ -0,0 +1,84 @@
+package lingstorage_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloader(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1000) // 16000 字节
+	server.PutObject(lingstoragetest.DefaultBucket, "big.bin", data)
+	server.PutObject(lingstoragetest.DefaultBucket, "empty.bin", nil)
+
+	downloader := lingstorage.NewDownloader(server.Client(), func(d *lingstorage.Downloader) {
+		d.PartSize = 1000
+		d.Concurrency = 3
+	})
+
+	buf := lingstorage.NewWriteAtBuffer(nil)
+	n, err := downloader.Download(buf, lingstoragetest.DefaultBucket, "big.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, data, buf.Bytes())
+
+	// 写入文件，单次调用覆盖分片大小
+	file, err := os.Create(filepath.Join(t.TempDir(), "big.bin"))
+	require.NoError(t, err)
+	defer file.Close()
+	n, err = downloader.Download(file, lingstoragetest.DefaultBucket, "big.bin", func(d *lingstorage.Downloader) { d.PartSize = 7000 })
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	written, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, data, written)
+
+	n, err = downloader.Download(lingstorage.NewWriteAtBuffer(nil), lingstoragetest.DefaultBucket, "empty.bin")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	_, err = downloader.Download(lingstorage.NewWriteAtBuffer(nil), lingstoragetest.DefaultBucket, "missing.bin")
+	assert.Error(t, err)
+}
+
+func TestDownloaderAdaptive(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4000) // 64000 字节
+	server.PutObject(lingstoragetest.DefaultBucket, "big.bin", data)
+
+	var tunes [][2]int64
+	downloader := lingstorage.NewDownloader(server.Client(), func(d *lingstorage.Downloader) {
+		d.Adaptive = true
+		d.PartSize = 1000
+		d.Concurrency = 2
+		d.MinPartSize = 500
+		d.MaxPartSize = 4000
+		d.MaxConcurrency = 4
+		d.OnTune = func(partSize int64, concurrency int) {
+			tunes = append(tunes, [2]int64{partSize, int64(concurrency)})
+		}
+	})
+	buf := lingstorage.NewWriteAtBuffer(nil)
+	n, err := downloader.Download(buf, lingstoragetest.DefaultBucket, "big.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, data, buf.Bytes())
+
+	// 调整始终在配置范围内
+	require.NotEmpty(t, tunes)
+	for _, tune := range tunes {
+		assert.GreaterOrEqual(t, tune[0], int64(500))
+		assert.LessOrEqual(t, tune[0], int64(4000))
+		assert.GreaterOrEqual(t, tune[1], int64(1))
+		assert.LessOrEqual(t, tune[1], int64(4))
+	}
+}
@@ -0,0 +1,262 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckInterval how long a failed endpoint is skipped, and the period of StartHealthChecks
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// EndpointStatus health of one configured endpoint
+type EndpointStatus struct {
+	URL       string
+	Primary   bool
+	Healthy   bool
+	Latency   time.Duration // round trip of the last probe, 0 when never probed
+	LastError error
+	CheckedAt time.Time
+}
+
+type endpoint struct {
+	base      string
+	healthy   bool
+	downUntil time.Time
+	latency   time.Duration
+	lastErr   error
+	checkedAt time.Time
+}
+
+// endpointPool primary and replica endpoints shared by client copies
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	cooldown  time.Duration
+	nearest   bool
+}
+
+func newEndpointPool(config *Config) *endpointPool {
+	pool := &endpointPool{
+		cooldown: config.HealthCheckInterval,
+		nearest:  config.ReadFromNearest,
+	}
+	if pool.cooldown <= 0 {
+		pool.cooldown = DefaultHealthCheckInterval
+	}
+	for _, base := range append([]string{config.BaseURL}, config.Replicas...) {
+		pool.endpoints = append(pool.endpoints, &endpoint{base: strings.TrimRight(base, "/"), healthy: true})
+	}
+	return pool
+}
+
+func (p *endpointPool) usable(ep *endpoint, now time.Time) bool {
+	return ep.healthy || !now.Before(ep.downUntil)
+}
+
+// pick endpoint of the next attempt. Writes go to the first usable endpoint in
+// configuration order, reads to the fastest probed one when routing to the
+// nearest replica. When every endpoint is down the one recovering first is used
+func (p *endpointPool) pick(method string) *endpoint {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	nearest := p.nearest && (method == http.MethodGet || method == http.MethodHead)
+	var best *endpoint
+	for _, ep := range p.endpoints {
+		if !p.usable(ep, now) {
+			continue
+		}
+		if best == nil {
+			best = ep
+			if !nearest {
+				break
+			}
+			continue
+		}
+		if ep.latency > 0 && (best.latency == 0 || ep.latency < best.latency) {
+			best = ep
+		}
+	}
+	if best != nil {
+		return best
+	}
+	best = p.endpoints[0]
+	for _, ep := range p.endpoints[1:] {
+		if ep.downUntil.Before(best.downUntil) {
+			best = ep
+		}
+	}
+	return best
+}
+
+// markUp record a successful round trip to ep
+func (p *endpointPool) markUp(ep *endpoint) {
+	if p == nil || ep == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.healthy = true
+	ep.lastErr = nil
+}
+
+// markDown record a connection failure of ep, report whether another endpoint is usable
+func (p *endpointPool) markDown(ep *endpoint, err error) bool {
+	if p == nil || ep == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	ep.healthy = false
+	ep.lastErr = err
+	ep.downUntil = now.Add(p.cooldown)
+	for _, other := range p.endpoints {
+		if other != ep && p.usable(other, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// record result of a health probe
+func (p *endpointPool) record(ep *endpoint, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.checkedAt = time.Now()
+	ep.lastErr = err
+	if err != nil {
+		ep.healthy = false
+		ep.downUntil = ep.checkedAt.Add(p.cooldown)
+		return
+	}
+	ep.healthy = true
+	ep.latency = latency
+}
+
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]EndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		statuses[i] = EndpointStatus{
+			URL:       ep.base,
+			Primary:   i == 0,
+			Healthy:   ep.healthy,
+			Latency:   ep.latency,
+			LastError: ep.lastErr,
+			CheckedAt: ep.checkedAt,
+		}
+	}
+	return statuses
+}
+
+// routeRequest request of one attempt, sent to the pinned acceleration endpoint
+// if any, otherwise to ep. The regional endpoint takes the place of the primary
+func (c *Client) routeRequest(req *http.Request, ep *endpoint, accelerated, regional string) (*http.Request, error) {
+	base := accelerated
+	if base == "" && ep != nil && ep != c.endpoints.endpoints[0] {
+		base = ep.base
+	}
+	if base == "" {
+		base = regional
+	}
+	if base == "" {
+		return req, nil
+	}
+	return c.rebaseRequest(req, base)
+}
+
+// rebaseRequest copy of req sent to base instead of BaseURL, sharing its body.
+// Requests whose URL is not under BaseURL are returned unchanged
+func (c *Client) rebaseRequest(req *http.Request, base string) (*http.Request, error) {
+	primary := strings.TrimRight(c.config.BaseURL, "/")
+	rawURL := req.URL.String()
+	if base == primary || !strings.HasPrefix(rawURL, primary) {
+		return req, nil
+	}
+	u, err := url.Parse(base + strings.TrimPrefix(rawURL, primary))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %s: %w", c.RedactURL(base), err)
+	}
+	routed := req.Clone(req.Context())
+	routed.URL = u
+	routed.Host = ""
+	return routed, nil
+}
+
+// Endpoints health of the primary and replica endpoints, nil without replicas
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return nil
+	}
+	return c.endpoints.status()
+}
+
+// CheckEndpoints probe every endpoint once, updating health and latency used for routing
+func (c *Client) CheckEndpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return nil
+	}
+	var wg sync.WaitGroup
+	for _, ep := range c.endpoints.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			latency, err := c.probe(ep.base)
+			c.endpoints.record(ep, latency, err)
+		}(ep)
+	}
+	wg.Wait()
+	return c.endpoints.status()
+}
+
+// StartHealthChecks probe endpoints every HealthCheckInterval until ctx is done
+func (c *Client) StartHealthChecks(ctx context.Context) {
+	if c.endpoints == nil {
+		return
+	}
+	probe := c.WithContext(ctx)
+	go func() {
+		ticker := time.NewTicker(c.endpoints.cooldown)
+		defer ticker.Stop()
+		for {
+			probe.CheckEndpoints()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// probe HEAD the endpoint root without retry or failover, returns the round trip
+func (c *Client) probe(base string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(c.context(), "HEAD", base, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create probe request: %w", err)
+	}
+	if err := c.setHeaders(req, nil); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, c.redactError(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return 0, fmt.Errorf("probe failed with status code: %d", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,138 @@
+package lingstorage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverToReplica(t *testing.T) {
+	// 主节点不可达
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	var hits int32
+	var body string
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer replica.Close()
+
+	client := NewClient(&Config{
+		BaseURL:    downURL,
+		Replicas:   []string{replica.URL},
+		APIKey:     "test-key",
+		RetryCount: -1,
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	assert.EqualValues(t, 1, hits)
+
+	statuses := client.Endpoints()
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Primary)
+	assert.False(t, statuses[0].Healthy)
+	assert.Error(t, statuses[0].LastError)
+	assert.True(t, statuses[1].Healthy)
+
+	// 请求体在切换节点时可以重放
+	require.NoError(t, client.CopyFile(&CopyFileRequest{SrcBucket: "src", SrcKey: "x", DestBucket: "dst", DestKey: "y"}))
+	assert.Contains(t, body, `"destKey":"y"`)
+}
+
+func TestSignedFailoverToReplicaPath(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	var paths []string
+	replica := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, nonce := r.Header.Get("X-Timestamp"), r.Header.Get("X-Nonce")
+		// 签名覆盖副本实际收到的路径
+		assert.Equal(t, Sign("test-secret", r.Method, signedPath(r.URL), body, timestamp, nonce), r.Header.Get("X-Signature"))
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer replica.Close()
+
+	client := NewClient(&Config{
+		BaseURL:      downURL,
+		Replicas:     []string{replica.URL + "/mirror"},
+		APIKey:       "test-key",
+		APISecret:    "test-secret",
+		SignRequests: true,
+		RetryCount:   -1,
+	})
+	require.NoError(t, client.CopyFile(&CopyFileRequest{SrcBucket: "src", SrcKey: "x", DestBucket: "dst", DestKey: "y"}))
+	assert.Equal(t, []string{"/mirror/api/public/files/src/x/copy"}, paths)
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	client := NewClient(&Config{
+		BaseURL:    down.URL,
+		Replicas:   []string{down.URL + "/replica"},
+		RetryCount: -1,
+	})
+	assert.Error(t, client.DeleteFile("bucket", "key"))
+	for _, status := range client.Endpoints() {
+		assert.False(t, status.Healthy)
+	}
+}
+
+func TestReadFromNearest(t *testing.T) {
+	var primaryReads, replicaReads int32
+	handler := func(counter *int32, delay time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			if r.Method == http.MethodGet {
+				atomic.AddInt32(counter, 1)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success":true,"data":{"files":[]}}`))
+		}
+	}
+	primary := httptest.NewServer(handler(&primaryReads, 50*time.Millisecond))
+	defer primary.Close()
+	replica := httptest.NewServer(handler(&replicaReads, 0))
+	defer replica.Close()
+
+	client := NewClient(&Config{
+		BaseURL:         primary.URL,
+		Replicas:        []string{replica.URL},
+		ReadFromNearest: true,
+		RetryCount:      -1,
+	})
+	statuses := client.CheckEndpoints()
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Healthy)
+	assert.Greater(t, statuses[0].Latency, statuses[1].Latency)
+
+	_, err := client.ListFiles(&ListFilesRequest{Bucket: "bucket"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, primaryReads)
+	assert.EqualValues(t, 1, replicaReads)
+
+	// 写请求仍然发往主节点
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	assert.EqualValues(t, 1, replicaReads)
+}
+
+func TestNoReplicas(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://example.com"})
+	assert.Nil(t, client.Endpoints())
+	assert.Nil(t, client.CheckEndpoints())
+}
@@ -0,0 +1,151 @@
+package lingstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ResponseFormat envelope format of API responses
+type ResponseFormat int32
+
+const (
+	// ResponseFormatUnknown no response seen yet, or a response without envelope fields
+	ResponseFormatUnknown ResponseFormat = iota
+	// ResponseFormatSuccess {"success": true, "message": "...", "data": ...} of older servers
+	ResponseFormatSuccess
+	// ResponseFormatCode {"code": 200, "msg": "...", "data": ...} of newer servers
+	ResponseFormatCode
+)
+
+func (f ResponseFormat) String() string {
+	switch f {
+	case ResponseFormatSuccess:
+		return "success/message/data"
+	case ResponseFormatCode:
+		return "code/msg/data"
+	default:
+		return "unknown"
+	}
+}
+
+// envelope API response of either server format
+type envelope struct {
+	Success *bool           `json:"success"`
+	Message string          `json:"message"`
+	Code    *responseCode   `json:"code"`
+	Msg     string          `json:"msg"`
+	Details string          `json:"details"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// responseCode application code of code/msg responses, sent by some servers
+// as a JSON number and by others as a string such as "200" or "NoSuchKey"
+type responseCode string
+
+func (r *responseCode) UnmarshalJSON(data []byte) error {
+	var code json.Number
+	if err := json.Unmarshal(data, &code); err == nil {
+		*r = responseCode(code)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid response code %s", data)
+	}
+	*r = responseCode(text)
+	return nil
+}
+
+// number numeric value of the code, 0 for non numeric codes
+func (r responseCode) number() int {
+	n, _ := strconv.Atoi(string(r))
+	return n
+}
+
+// format server format detected from the fields present
+func (e *envelope) format() ResponseFormat {
+	switch {
+	case e.Code != nil:
+		return ResponseFormatCode
+	case e.Success != nil:
+		return ResponseFormatSuccess
+	default:
+		return ResponseFormatUnknown
+	}
+}
+
+// ok whether the envelope reports success, 0 and 200 are success codes.
+// A body without envelope fields is taken as success
+func (e *envelope) ok() bool {
+	if e.Success != nil && *e.Success {
+		return true
+	}
+	if e.Code != nil {
+		return *e.Code == "0" || *e.Code == responseCode(strconv.Itoa(http.StatusOK))
+	}
+	return e.Success == nil
+}
+
+func (e *envelope) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Msg
+}
+
+// apiError error reported by the envelope of a response with statusCode
+func (e *envelope) apiError(statusCode int) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Message: e.message(), Details: e.Details}
+	if e.Code != nil {
+		apiErr.Code = e.Code.number()
+	}
+	return apiErr
+}
+
+// decodeResponse decode the envelope of a 2xx response and its data into out,
+// which may be nil. An envelope reporting failure is returned as *APIError
+func (c *Client) decodeResponse(resp *http.Response, out interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		if out == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	c.recordFormat(env.format())
+	if !env.ok() {
+		return c.redactAPIError(env.apiError(resp.StatusCode))
+	}
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) recordFormat(format ResponseFormat) {
+	if format != ResponseFormatUnknown && c.responseFormat != nil {
+		c.responseFormat.Store(int32(format))
+	}
+}
+
+// ResponseFormat envelope format of the last response, ResponseFormatUnknown before the first one
+func (c *Client) ResponseFormat() ResponseFormat {
+	if c.responseFormat == nil {
+		return ResponseFormatUnknown
+	}
+	return ResponseFormat(c.responseFormat.Load())
+}
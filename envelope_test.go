@@ -0,0 +1,93 @@
+package lingstorage
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseFormats(t *testing.T) {
+	format := "code"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/bkt/a.txt/info":
+			if format == "code" {
+				w.Write([]byte(`{"code":200,"msg":"ok","data":{"key":"a.txt","size":3}}`))
+				return
+			}
+			w.Write([]byte(`{"success":true,"message":"ok","data":{"key":"a.txt","size":3}}`))
+		case "/api/public/buckets/bkt/files":
+			w.Write([]byte(`{"code":0,"data":{"files":[{"key":"a.txt"}],"total":1}}`))
+		case "/api/public/files/bkt/denied.txt/info":
+			w.Write([]byte(`{"code":40301,"msg":"access denied"}`))
+		case "/api/public/files/bkt/failed.txt/info":
+			w.Write([]byte(`{"success":false,"message":"not ready"}`))
+		case "/api/public/files/bkt/quoted.txt/info":
+			w.Write([]byte(`{"code":"200","msg":"ok","data":{"key":"quoted.txt"}}`))
+		case "/api/public/files/bkt/gone.txt/info":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"40401","msg":"object gone","details":"deleted by lifecycle"}`))
+		case "/api/public/files/bkt/named.txt/info":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"code":"AccessDenied","msg":"bucket is private"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"msg":"no such key"}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	assert.Equal(t, ResponseFormatUnknown, client.ResponseFormat())
+
+	info, err := client.GetFileInfo("bkt", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", info.Key)
+	assert.Equal(t, ResponseFormatCode, client.ResponseFormat())
+
+	result, err := client.ListFiles(&ListFilesRequest{Bucket: "bkt"})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 1)
+
+	format = "success"
+	info, err = client.GetFileInfo("bkt", "a.txt")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, info.Size)
+	assert.Equal(t, ResponseFormatSuccess, client.ResponseFormat())
+
+	// 状态码 200 但业务失败
+	var apiErr *APIError
+	_, err = client.GetFileInfo("bkt", "denied.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 40301, apiErr.Code)
+	assert.Equal(t, "access denied", apiErr.Message)
+
+	_, err = client.GetFileInfo("bkt", "failed.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "not ready", apiErr.Message)
+
+	// 错误响应中的 msg
+	_, err = client.GetFileInfo("bkt", "missing.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "no such key", apiErr.Message)
+
+	// 部分服务端以字符串返回 code
+	info, err = client.GetFileInfo("bkt", "quoted.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "quoted.txt", info.Key)
+
+	_, err = client.GetFileInfo("bkt", "gone.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 40401, apiErr.Code)
+	assert.Equal(t, "object gone", apiErr.Message)
+	assert.Equal(t, "deleted by lifecycle", apiErr.Details)
+
+	_, err = client.GetFileInfo("bkt", "named.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(t, "bucket is private", apiErr.Message)
+}
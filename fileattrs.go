@@ -0,0 +1,76 @@
+package lingstorage
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// metadata keys of the attributes stored by UploadRequest.PreserveAttributes
+const (
+	MetaFileMTime = "x-ling-mtime" // modification time, RFC 3339 with nanoseconds
+	MetaFileMode  = "x-ling-mode"  // permission bits in octal, e.g. 0644
+)
+
+// withFileAttributes copy of req whose metadata carries the modification time
+// and permissions of info
+func withFileAttributes(req *UploadRequest, info os.FileInfo) *UploadRequest {
+	metadata := make(map[string]string, len(req.Metadata)+2)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetaFileMTime] = info.ModTime().UTC().Format(time.RFC3339Nano)
+	metadata[MetaFileMode] = fmt.Sprintf("%04o", info.Mode().Perm())
+	attributed := *req
+	attributed.Metadata = metadata
+	return &attributed
+}
+
+// RestoreFileAttributes apply the modification time and permissions stored by
+// UploadRequest.PreserveAttributes to the file at filePath. Attributes missing
+// from metadata are left alone
+func RestoreFileAttributes(filePath string, metadata map[string]string) error {
+	if mode, ok := metadata[MetaFileMode]; ok {
+		perm, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid %s metadata %q: %w", MetaFileMode, mode, err)
+		}
+		if err := os.Chmod(filePath, os.FileMode(perm).Perm()); err != nil {
+			return fmt.Errorf("failed to set file mode: %w", err)
+		}
+	}
+	if mtime, ok := metadata[MetaFileMTime]; ok {
+		modTime, err := time.Parse(time.RFC3339Nano, mtime)
+		if err != nil {
+			return fmt.Errorf("invalid %s metadata %q: %w", MetaFileMTime, mtime, err)
+		}
+		if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+			return fmt.Errorf("failed to set modification time: %w", err)
+		}
+	}
+	return nil
+}
+
+// DownloadFileWithAttributes download file to local path like DownloadFile,
+// then restore the modification time and permissions stored at upload. Objects
+// uploaded without PreserveAttributes get their last modified time
+func (c *Client) DownloadFileWithAttributes(bucket, key, filePath string) error {
+	result, err := c.Download(&DownloadRequest{Bucket: bucket, Key: key})
+	if err != nil {
+		return err
+	}
+	defer result.Body.Close()
+
+	if err := writeFile(filePath, result.Body); err != nil {
+		return err
+	}
+	metadata := result.Metadata
+	if _, ok := metadata[MetaFileMTime]; !ok && !result.LastModified.IsZero() {
+		metadata = map[string]string{MetaFileMTime: result.LastModified.Format(time.RFC3339Nano)}
+		if mode, ok := result.Metadata[MetaFileMode]; ok {
+			metadata[MetaFileMode] = mode
+		}
+	}
+	return RestoreFileAttributes(filePath, metadata)
+}
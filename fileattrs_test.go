@@ -0,0 +1,68 @@
+package lingstorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreserveAttributes(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	bucket := lingstoragetest.DefaultBucket
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "backup.txt")
+	require.NoError(t, os.WriteFile(src, []byte("data"), 0o640))
+	mtime := time.Date(2020, 5, 17, 8, 30, 0, 123456789, time.UTC)
+	require.NoError(t, os.Chtimes(src, mtime, mtime))
+
+	_, err := client.UploadFile(&lingstorage.UploadRequest{
+		FilePath:           src,
+		Bucket:             bucket,
+		Key:                "backup.txt",
+		Metadata:           map[string]string{"owner": "alice"},
+		PreserveAttributes: true,
+	})
+	require.NoError(t, err)
+	obj, ok := server.Object(bucket, "backup.txt")
+	require.True(t, ok)
+	assert.Equal(t, "alice", obj.Metadata["owner"])
+	assert.Equal(t, "2020-05-17T08:30:00.123456789Z", obj.Metadata[lingstorage.MetaFileMTime])
+	assert.Equal(t, "0640", obj.Metadata[lingstorage.MetaFileMode])
+
+	dst := filepath.Join(dir, "restore", "backup.txt")
+	require.NoError(t, client.DownloadFileWithAttributes(bucket, "backup.txt", dst))
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(mtime), info.ModTime())
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	}
+
+	// 没有保存属性的对象使用服务端的修改时间
+	server.PutObject(bucket, "plain.txt", []byte("x"))
+	plain := filepath.Join(dir, "plain.txt")
+	require.NoError(t, client.DownloadFileWithAttributes(bucket, "plain.txt", plain))
+	remote, err := client.GetFileInfo(bucket, "plain.txt")
+	require.NoError(t, err)
+	info, err = os.Stat(plain)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(remote.LastModified.Truncate(time.Second)), info.ModTime())
+}
+
+func TestRestoreFileAttributesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	assert.Error(t, lingstorage.RestoreFileAttributes(path, map[string]string{lingstorage.MetaFileMode: "rw-r--r--"}))
+	assert.Error(t, lingstorage.RestoreFileAttributes(path, map[string]string{lingstorage.MetaFileMTime: "yesterday"}))
+	assert.NoError(t, lingstorage.RestoreFileAttributes(path, nil))
+}
@@ -0,0 +1,164 @@
+package lingstorage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// FileServerOptions options of FileServer
+type FileServerOptions struct {
+	// CacheControl value of the Cache-Control header, e.g. "public, max-age=3600"
+	CacheControl string
+	// IndexFile object served for paths ending in "/", e.g. index.html. Empty answers 404
+	IndexFile string
+	// Authorize called before an object is served, a non-nil error answers 403
+	Authorize func(r *http.Request, key string) error
+}
+
+// FileServer http.Handler serving the objects below prefix of a bucket, so
+// private content can be served through the application's own domain. The
+// request path is appended to prefix. Range requests, ETag and Last-Modified
+// based conditional requests are supported
+func FileServer(client *Client, bucket, prefix string, opts *FileServerOptions) http.Handler {
+	if opts == nil {
+		opts = &FileServerOptions{}
+	}
+	return &fileServer{client: client, bucket: bucket, prefix: normalizePrefix(prefix), opts: opts}
+}
+
+type fileServer struct {
+	client *Client
+	bucket string
+	prefix string
+	opts   *FileServerOptions
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+		if s.opts.IndexFile == "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = path.Join(name, s.opts.IndexFile)
+	}
+	key := s.prefix + name
+	if s.opts.Authorize != nil {
+		if err := s.opts.Authorize(r, key); err != nil {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
+	client := s.client.WithContext(r.Context())
+	if r.Method == http.MethodHead {
+		info, err := client.GetFileInfo(s.bucket, key)
+		if err != nil {
+			s.serveError(w, err)
+			return
+		}
+		s.setHeaders(w, info.ContentType, info.ETag, info.LastModified)
+		if notModified(r, info.ETag, info.LastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+		return
+	}
+
+	result, err := client.Download(&DownloadRequest{Bucket: s.bucket, Key: key, Range: r.Header.Get(constants.RANGE)})
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	defer result.Body.Close()
+	s.setHeaders(w, result.ContentType, result.ETag, result.LastModified)
+	if notModified(r, result.ETag, result.LastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if result.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(result.Size, 10))
+	}
+	status := http.StatusOK
+	if result.ContentRange != "" {
+		w.Header().Set(constants.CONTENT_RANGE, result.ContentRange)
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+	io.Copy(w, result.Body)
+}
+
+func (s *fileServer) setHeaders(w http.ResponseWriter, contentType, etag string, lastModified time.Time) {
+	h := w.Header()
+	h.Set("Accept-Ranges", "bytes")
+	if contentType != "" {
+		h.Set(constants.CONTENT_TYPE, contentType)
+	}
+	if etag != "" {
+		h.Set("ETag", `"`+etag+`"`)
+	}
+	if !lastModified.IsZero() {
+		h.Set(constants.LAST_MODIFIED, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if s.opts.CacheControl != "" {
+		h.Set("Cache-Control", s.opts.CacheControl)
+	}
+}
+
+// notModified evaluate If-None-Match, then If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || strings.Trim(candidate, `"`) == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// serveError status of a failed object request, server errors are hidden behind 502
+func (s *fileServer) serveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrQuarantined) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			http.Error(w, "404 page not found", http.StatusNotFound)
+			return
+		case http.StatusForbidden, http.StatusUnauthorized:
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		case http.StatusRequestedRangeNotSatisfiable:
+			http.Error(w, "requested range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+	}
+	http.Error(w, "bad gateway", http.StatusBadGateway)
+}
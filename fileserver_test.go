@@ -0,0 +1,100 @@
+package lingstorage_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileServer(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	server.PutObject(lingstoragetest.DefaultBucket, "site/index.html", []byte("<h1>home</h1>"))
+	server.PutObject(lingstoragetest.DefaultBucket, "site/private/report.txt", []byte("0123456789"))
+
+	handler := lingstorage.FileServer(server.Client(), lingstoragetest.DefaultBucket, "site", &lingstorage.FileServerOptions{
+		CacheControl: "private, max-age=60",
+		IndexFile:    "index.html",
+		Authorize: func(r *http.Request, key string) error {
+			if key == "site/private/report.txt" && r.Header.Get("Authorization") != "Bearer ok" {
+				return errors.New("denied")
+			}
+			return nil
+		},
+	})
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	get := func(path string, header map[string]string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, proxy.URL+path, nil)
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := get("/", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<h1>home</h1>", body)
+	assert.Equal(t, "private, max-age=60", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+	resp, _ = get("/index.html", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, _ = get("/private/report.txt", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 范围请求
+	resp, body = get("/private/report.txt", map[string]string{"Authorization": "Bearer ok", "Range": "bytes=2-5"})
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "2345", body)
+	assert.Equal(t, "bytes 2-5/10", resp.Header.Get("Content-Range"))
+
+	resp, _ = get("/private/report.txt", map[string]string{"Authorization": "Bearer ok", "Range": "bytes=50-60"})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+
+	resp, _ = get("/missing.txt", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	head, err := http.Head(proxy.URL + "/index.html")
+	require.NoError(t, err)
+	head.Body.Close()
+	assert.Equal(t, http.StatusOK, head.StatusCode)
+	assert.Equal(t, "13", head.Header.Get("Content-Length"))
+
+	post, err := http.Post(proxy.URL+"/index.html", "text/plain", nil)
+	require.NoError(t, err)
+	post.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, post.StatusCode)
+}
+
+func TestDownloadRange(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	server.PutObject(lingstoragetest.DefaultBucket, "a.txt", []byte("hello world"))
+
+	result, err := server.Client().Download(&lingstorage.DownloadRequest{Bucket: lingstoragetest.DefaultBucket, Key: "a.txt", Range: "bytes=6-"})
+	require.NoError(t, err)
+	defer result.Body.Close()
+	data, _ := io.ReadAll(result.Body)
+	assert.Equal(t, "world", string(data))
+	assert.Equal(t, "bytes 6-10/11", result.ContentRange)
+	assert.Equal(t, int64(5), result.Size)
+	assert.False(t, result.LastModified.IsZero())
+}
@@ -1,62 +1,198 @@
 package lingstorage
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
 	"time"
 )
 
+// DefaultSpeedWindow time constant of the averaged transfer speed
+const DefaultSpeedWindow = 5 * time.Second
+
+const (
+	etaCalculating = "Calculating..."
+	etaStalled     = "Stalled"
+)
+
+// ProgressMode how a ProgressMonitor without callback reports progress
+type ProgressMode int
+
+const (
+	ProgressModeBar   ProgressMode = iota // single line progress bar, the default
+	ProgressModeJSON                      // one JSON ProgressEvent per line
+	ProgressModeQuiet                     // no output
+)
+
+// ProgressEvent machine readable progress, emitted in ProgressModeJSON
+type ProgressEvent struct {
+	Time       time.Time `json:"time"`
+	Uploaded   int64     `json:"uploaded"`
+	Total      int64     `json:"total"`
+	Percentage float64   `json:"percentage"`
+	Speed      float64   `json:"speed"` // KB/s
+	ETA        string    `json:"eta"`
+	Elapsed    float64   `json:"elapsed"` // seconds since the monitor was created
+	Done       bool      `json:"done"`
+}
+
 type ProgressMonitor struct {
+	mu           sync.Mutex
 	startTime    time.Time
 	lastTime     time.Time
 	lastUploaded int64
+	total        int64
+	speed        float64 // exponentially weighted bytes per second
+	sampled      bool    // speed has been measured at least once
+	window       time.Duration
 	callback     func(uploaded, total int64, percentage float64, speed float64, eta string)
+	output       io.Writer
+	mode         ProgressMode
 }
 
 func NewProgressMonitor() *ProgressMonitor {
 	return &ProgressMonitor{
 		startTime: time.Now(),
+		output:    os.Stdout,
+		window:    DefaultSpeedWindow,
+	}
+}
+
+// SetSpeedWindow averaging window of the speed, longer windows give a steadier ETA
+func (pm *ProgressMonitor) SetSpeedWindow(window time.Duration) *ProgressMonitor {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if window > 0 {
+		pm.window = window
 	}
+	return pm
+}
+
+// SetCallback custom rendering, replaces the built in output
+func (pm *ProgressMonitor) SetCallback(callback func(uploaded, total int64, percentage float64, speed float64, eta string)) *ProgressMonitor {
+	pm.callback = callback
+	return pm
+}
+
+// SetOutput writer of the built in output, default os.Stdout
+func (pm *ProgressMonitor) SetOutput(w io.Writer) *ProgressMonitor {
+	pm.output = w
+	return pm
+}
+
+// SetMode output mode used when no callback is set
+func (pm *ProgressMonitor) SetMode(mode ProgressMode) *ProgressMonitor {
+	pm.mode = mode
+	return pm
 }
 
 func (pm *ProgressMonitor) OnProgress(uploaded, total int64) {
+	pm.mu.Lock()
 	now := time.Now()
 
 	// 计算进度百分比
-	percentage := float64(uploaded) / float64(total) * 100
-
-	// 计算上传速度
-	var speed float64
-	if !pm.lastTime.IsZero() {
-		duration := now.Sub(pm.lastTime).Seconds()
-		if duration > 0 {
-			bytesPerSecond := float64(uploaded-pm.lastUploaded) / duration
-			speed = bytesPerSecond / 1024 // KB/s
+	var percentage float64
+	if total > 0 {
+		percentage = float64(uploaded) / float64(total) * 100
+	}
+
+	// 计算上传速度，按时间加权的指数移动平均
+	if duration := now.Sub(pm.lastTime); !pm.lastTime.IsZero() && duration > 0 {
+		instant := float64(uploaded-pm.lastUploaded) / duration.Seconds()
+		if !pm.sampled {
+			pm.sampled = true
+			pm.speed = instant
+		} else {
+			alpha := 1 - math.Exp(-duration.Seconds()/pm.window.Seconds())
+			pm.speed += alpha * (instant - pm.speed)
 		}
 	}
+	pm.lastTime = now
+	pm.lastUploaded = uploaded
+	pm.total = total
+	speed := pm.speed / 1024 // KB/s
 
 	// 计算剩余时间
-	var eta string
-	if speed > 0 && uploaded > 0 {
-		remainingBytes := total - uploaded
-		remainingSeconds := float64(remainingBytes) / (speed * 1024)
-		eta = FormatDuration(time.Duration(remainingSeconds) * time.Second)
-	} else {
-		eta = "Calculating..."
-	}
-	if pm.callback != nil {
+	eta := pm.formatETA(now)
+	// the callback may call the accessors
+	pm.mu.Unlock()
+
+	switch {
+	case pm.callback != nil:
 		pm.callback(uploaded, total, percentage, speed, eta)
-	} else {
-		func(uploaded, total int64, percentage float64, speed float64, eta string) {
-			progressBar := CreateProgressBar(int(percentage), 50)
-			uploadedStr := FormatBytes(uploaded)
-			totalStr := FormatBytes(total)
-			speedStr := fmt.Sprintf("%.1f KB/s", speed)
-			fmt.Printf("\r%s %.1f%% (%s/%s) %s ETA: %s",
-				progressBar, percentage, uploadedStr, totalStr, speedStr, eta)
-		}(uploaded, total, percentage, speed, eta)
+	case pm.mode == ProgressModeJSON:
+		data, _ := json.Marshal(ProgressEvent{
+			Time:       now,
+			Uploaded:   uploaded,
+			Total:      total,
+			Percentage: percentage,
+			Speed:      speed,
+			ETA:        eta,
+			Elapsed:    now.Sub(pm.startTime).Seconds(),
+			Done:       total > 0 && uploaded >= total,
+		})
+		fmt.Fprintf(pm.output, "%s\n", data)
+	case pm.mode == ProgressModeBar:
+		progressBar := CreateProgressBar(int(percentage), 50)
+		uploadedStr := FormatBytes(uploaded)
+		totalStr := FormatBytes(total)
+		speedStr := fmt.Sprintf("%.1f KB/s", speed)
+		fmt.Fprintf(pm.output, "\r%s %.1f%% (%s/%s) %s ETA: %s",
+			progressBar, percentage, uploadedStr, totalStr, speedStr, eta)
 	}
-	pm.lastTime = now
-	pm.lastUploaded = uploaded
+}
+
+// currentSpeed averaged bytes per second at now. Without progress the speed
+// decays, so a stalled transfer drops towards 0
+func (pm *ProgressMonitor) currentSpeed(now time.Time) float64 {
+	if pm.lastTime.IsZero() {
+		return 0
+	}
+	idle := now.Sub(pm.lastTime)
+	if idle <= pm.window {
+		return pm.speed
+	}
+	return pm.speed * math.Exp(-(idle-pm.window).Seconds()/pm.window.Seconds())
+}
+
+// eta remaining time at now, false while unknown
+func (pm *ProgressMonitor) eta(now time.Time) (time.Duration, bool) {
+	speed := pm.currentSpeed(now)
+	if pm.total <= 0 || speed < 1 {
+		return 0, false
+	}
+	remaining := float64(pm.total - pm.lastUploaded)
+	if remaining <= 0 {
+		return 0, true
+	}
+	return time.Duration(remaining / speed * float64(time.Second)), true
+}
+
+func (pm *ProgressMonitor) formatETA(now time.Time) string {
+	if eta, ok := pm.eta(now); ok {
+		return FormatDuration(eta)
+	}
+	if pm.sampled && pm.lastUploaded > 0 {
+		return etaStalled
+	}
+	return etaCalculating
+}
+
+// Speed averaged transfer speed in KB/s, same unit as the callback
+func (pm *ProgressMonitor) Speed() float64 {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.currentSpeed(time.Now()) / 1024
+}
+
+// ETA estimated remaining time, false while unknown or stalled
+func (pm *ProgressMonitor) ETA() (time.Duration, bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.eta(time.Now())
 }
 
 func (pm *ProgressMonitor) GetTotalDuration() time.Duration {
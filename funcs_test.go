@@ -0,0 +1,93 @@
+package lingstorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressMonitorOutput(t *testing.T) {
+	var buf bytes.Buffer
+	pm := NewProgressMonitor().SetOutput(&buf)
+	pm.OnProgress(512, 1024)
+	assert.Contains(t, buf.String(), "50.0%")
+	assert.True(t, strings.HasPrefix(buf.String(), "\r["))
+
+	buf.Reset()
+	pm.SetMode(ProgressModeQuiet).OnProgress(1024, 1024)
+	assert.Empty(t, buf.String())
+}
+
+func TestProgressMonitorJSON(t *testing.T) {
+	var buf bytes.Buffer
+	pm := NewProgressMonitor().SetOutput(&buf).SetMode(ProgressModeJSON)
+	pm.OnProgress(0, 0)
+	pm.OnProgress(256, 1024)
+	pm.OnProgress(1024, 1024)
+
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event ProgressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 3)
+	assert.Zero(t, events[0].Percentage)
+	assert.Equal(t, 25.0, events[1].Percentage)
+	assert.False(t, events[1].Done)
+	assert.True(t, events[2].Done)
+	assert.EqualValues(t, 1024, events[2].Uploaded)
+}
+
+func TestProgressMonitorCallback(t *testing.T) {
+	var buf bytes.Buffer
+	var got float64
+	pm := NewProgressMonitor().SetOutput(&buf).SetCallback(func(uploaded, total int64, percentage float64, speed float64, eta string) {
+		got = percentage
+	})
+	pm.OnProgress(3, 4)
+	assert.Equal(t, 75.0, got)
+	// 设置回调后不再输出
+	assert.Empty(t, buf.String())
+}
+
+func TestProgressMonitorSpeed(t *testing.T) {
+	pm := NewProgressMonitor().SetMode(ProgressModeQuiet).SetSpeedWindow(time.Second)
+	_, ok := pm.ETA()
+	assert.False(t, ok)
+
+	base := time.Now()
+	pm.lastTime = base.Add(-time.Second)
+	pm.OnProgress(1024, 10240)
+	assert.InDelta(t, 1.0, pm.Speed(), 0.1)
+
+	// 单次突发不会让速度剧烈跳变
+	pm.lastTime = time.Now().Add(-100 * time.Millisecond)
+	pm.OnProgress(1024+10240, 1024*100)
+	assert.Less(t, pm.Speed(), 20.0)
+	eta, ok := pm.ETA()
+	assert.True(t, ok)
+	assert.Greater(t, eta, time.Second)
+
+	// 长时间无进度时速度衰减，ETA 未知
+	pm.lastTime = time.Now().Add(-time.Minute)
+	assert.Less(t, pm.Speed(), 0.01)
+	_, ok = pm.ETA()
+	assert.False(t, ok)
+
+	var eta2 string
+	pm.SetCallback(func(uploaded, total int64, percentage float64, speed float64, eta string) {
+		eta2 = eta
+		// 回调中可以读取速度
+		pm.Speed()
+	})
+	pm.OnProgress(1024+10240, 1024*100)
+	assert.Equal(t, etaStalled, eta2)
+}
@@ -0,0 +1,164 @@
+package lingstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// hashCacheEntry md5 of a file as it was at size and mtime
+type hashCacheEntry struct {
+	Size  int64  `json:"size"`
+	MTime int64  `json:"mtime"` // unix nanoseconds
+	MD5   string `json:"md5"`
+}
+
+// hashCache on-disk cache of file md5 sums keyed by absolute path. An entry is
+// only used while the file keeps its size and modification time
+type hashCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]hashCacheEntry
+	dirty   bool
+}
+
+// loadHashCache read the cache at path, a missing file is an empty cache.
+// nil is returned for an empty path, disabling the cache
+func loadHashCache(path string) (*hashCache, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cache := &hashCache{path: path, entries: make(map[string]hashCacheEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("invalid hash cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+func (h *hashCache) get(p string, info fs.FileInfo) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.entries[p]
+	if !ok || entry.Size != info.Size() || entry.MTime != info.ModTime().UnixNano() {
+		return "", false
+	}
+	return entry.MD5, true
+}
+
+func (h *hashCache) put(p string, info fs.FileInfo, sum string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[p] = hashCacheEntry{Size: info.Size(), MTime: info.ModTime().UnixNano(), MD5: sum}
+	h.dirty = true
+}
+
+// save write the cache back when it changed
+func (h *hashCache) save() error {
+	if h == nil || !h.dirty {
+		return nil
+	}
+	h.mu.Lock()
+	data, err := json.Marshal(h.entries)
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode hash cache: %w", err)
+	}
+	if err := writeFile(h.path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	return nil
+}
+
+// hashForCompare md5 of the local files whose hash decides the comparison with
+// their remote object, hashed in parallel. nil unless compare is CompareHash
+func hashForCompare(compare string, locals []localFile, remoteOf func(localFile) (FileInfo, bool), workers int, cache *hashCache) map[string]hashResult {
+	if compare != CompareHash {
+		return nil
+	}
+	var candidates []localFile
+	for _, local := range locals {
+		if remote, ok := remoteOf(local); ok && local.link == "" && needsHash(local.info, remote) {
+			candidates = append(candidates, local)
+		}
+	}
+	return hashFiles(candidates, workers, cache)
+}
+
+// hashResult md5 of a local file or the error hashing it
+type hashResult struct {
+	sum string
+	err error
+}
+
+// hashFiles md5 of files keyed by path, computed by workers in parallel, default
+// the number of CPUs. Sums cached for the same size and mtime are reused
+func hashFiles(files []localFile, workers int, cache *hashCache) map[string]hashResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make(map[string]hashResult, len(files))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan localFile)
+	for i := 0; i < workers && i < len(files); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range work {
+				result := hashLocal(file, cache)
+				mu.Lock()
+				results[file.path] = result
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		work <- file
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+func hashLocal(file localFile, cache *hashCache) hashResult {
+	key, err := filepath.Abs(file.path)
+	if err != nil {
+		key = file.path
+	}
+	if sum, ok := cache.get(key, file.info); ok {
+		return hashResult{sum: sum}
+	}
+	sum, err := fileMD5(file.path)
+	if err != nil {
+		return hashResult{err: err}
+	}
+	cache.put(key, file.info, sum)
+	return hashResult{sum: sum}
+}
+
+// needsHash the md5 of local decides whether it matches remote: sizes are
+// equal and the ETag is a plain md5, not the one of a multipart upload
+func needsHash(local fs.FileInfo, remote FileInfo) bool {
+	etag := strings.Trim(remote.ETag, `"`)
+	return local.Size() == remote.Size && etag != "" && !strings.Contains(etag, "-")
+}
@@ -0,0 +1,81 @@
+package lingstorage_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncHashCache(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	files := map[string]string{}
+	for _, name := range []string{"a.txt", "b.txt", "sub/c.txt", "sub/d.txt", "sub/e.txt"} {
+		files[name] = "content of " + name
+	}
+	writeTree(t, dir, files)
+	cachePath := filepath.Join(t.TempDir(), "hashes.json")
+	req := &lingstorage.SyncRequest{
+		LocalDir:    dir,
+		Bucket:      lingstoragetest.DefaultBucket,
+		Compare:     lingstorage.CompareHash,
+		HashWorkers: 3,
+		HashCache:   cachePath,
+	}
+	report, err := client.Sync(req)
+	require.NoError(t, err)
+	assert.Len(t, report.Created, 5)
+	_, err = os.Stat(cachePath)
+	assert.True(t, os.IsNotExist(err), "nothing hashed, nothing cached")
+
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Unchanged)
+	entries := readHashCache(t, cachePath)
+	assert.Len(t, entries, 5)
+
+	// 缓存命中时不重新计算, 篡改缓存可以观察到
+	aPath, err := filepath.Abs(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	entry := entries[aPath]
+	entry["md5"] = "00000000000000000000000000000000"
+	data, err := json.Marshal(entries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cachePath, data, 0o644))
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, keys(report.Updated))
+
+	// 修改时间变化后缓存失效, 被篡改的条目也随之更新
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "a.txt"), later, later))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "b.txt"), later, later))
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Unchanged)
+	mtime := readHashCache(t, cachePath)[filepath.Join(filepath.Dir(aPath), "b.txt")]["mtime"]
+	assert.Equal(t, json.Number(strconv.FormatInt(later.UnixNano(), 10)), mtime)
+}
+
+func readHashCache(t *testing.T, path string) map[string]map[string]interface{} {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// UseNumber 保留纳秒级 mtime 的精度
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var entries map[string]map[string]interface{}
+	require.NoError(t, decoder.Decode(&entries))
+	return entries
+}
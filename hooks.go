@@ -0,0 +1,46 @@
+package lingstorage
+
+import "net/http"
+
+// ClientOption optional client setting applied by NewClient
+type ClientOption func(*Client)
+
+// RequestHook called before every attempt, after auth headers are set
+type RequestHook func(req *http.Request)
+
+// ResponseHook called after every attempt with the response or the transport error
+type ResponseHook func(resp *http.Response, err error)
+
+// WithRequestHook append a request hook, hooks run in the order they were added
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(c *Client) {
+		c.requestHooks = append(c.requestHooks, hook)
+	}
+}
+
+// WithResponseHook append a response hook, hooks run in the order they were added
+func WithResponseHook(hook ResponseHook) ClientOption {
+	return func(c *Client) {
+		c.responseHooks = append(c.responseHooks, hook)
+	}
+}
+
+func (c *Client) runRequestHooks(req *http.Request) {
+	for _, hook := range c.requestHooks {
+		hook(req)
+	}
+}
+
+func (c *Client) runResponseHooks(resp *http.Response, err error) {
+	for _, hook := range c.responseHooks {
+		hook(resp, err)
+	}
+}
+
+// WithTransport replace the http transport of the client, e.g. with a vcr.Recorder.
+// TLS and connection pool options of Config are not applied to a custom transport
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
@@ -0,0 +1,39 @@
+package lingstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAndResponseHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Tenant"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var order []string
+	var statuses []int
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"},
+		WithRequestHook(func(req *http.Request) {
+			order = append(order, "first")
+			req.Header.Set("X-Tenant", "tenant-1")
+		}),
+		WithRequestHook(func(req *http.Request) {
+			order = append(order, "second")
+		}),
+		WithResponseHook(func(resp *http.Response, err error) {
+			require.NoError(t, err)
+			statuses = append(statuses, resp.StatusCode)
+		}),
+	)
+
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	require.NoError(t, client.Ping())
+	assert.Equal(t, []string{"first", "second", "first", "second"}, order)
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses)
+}
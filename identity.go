@@ -0,0 +1,67 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidCredentials configured credentials were rejected by the server
+var ErrInvalidCredentials = errors.New("lingstorage: invalid credentials")
+
+// Identity identity associated with the configured credentials
+type Identity struct {
+	UserID    string    `json:"userId"`
+	Name      string    `json:"name"`
+	APIKey    string    `json:"apiKey"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"` // zero means never expires
+}
+
+// HasScope check identity is granted scope
+func (i *Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WhoAmI 获取当前凭据对应的身份
+func (c *Client) WhoAmI() (_ *Identity, err error) {
+	ctx, op := c.startOperation("WhoAmI", "", "")
+	defer func() { c.endOperation(op, err) }()
+	resp, err := c.send(ctx, "GET", "/api/public/whoami", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, c.handleErrorResponse(resp))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var result Identity
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ValidateCredentials 校验凭据是否有效且未过期, 适合在启动时调用
+func (c *Client) ValidateCredentials() error {
+	identity, err := c.WhoAmI()
+	if err != nil {
+		return err
+	}
+	if !identity.ExpiresAt.IsZero() && !time.Now().Before(identity.ExpiresAt) {
+		return fmt.Errorf("%w: credentials expired at %s", ErrInvalidCredentials, identity.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
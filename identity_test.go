@@ -0,0 +1,51 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhoAmI(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/whoami", r.URL.Path)
+		if r.Header.Get("X-API-Key") != "good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "invalid api key"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"userId":    "u-1",
+				"apiKey":    "good-key",
+				"scopes":    []string{"files:read", "files:write"},
+				"expiresAt": expiresAt,
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "good-key"})
+	identity, err := client.WhoAmI()
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", identity.UserID)
+	assert.True(t, identity.HasScope("files:write"))
+	assert.False(t, identity.HasScope("buckets:delete"))
+	assert.True(t, expiresAt.Equal(identity.ExpiresAt))
+	assert.NoError(t, client.ValidateCredentials())
+
+	bad := NewClient(&Config{BaseURL: server.URL, APIKey: "bad-key"})
+	err = bad.ValidateCredentials()
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
@@ -0,0 +1,306 @@
+package lingstorage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultIgnoreFile ignore file read from the root of uploaded and synced directories
+const DefaultIgnoreFile = ".lingignore"
+
+// IgnoreRules gitignore style exclusion rules: # comments, ! negation, a
+// trailing / matches directories only, patterns containing a / are anchored to
+// the root, others match a name at any depth, ** matches any number of directories
+type IgnoreRules struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnore compile ignore patterns, blank lines and comments are skipped
+func ParseIgnore(patterns []string) (*IgnoreRules, error) {
+	r := &IgnoreRules{}
+	for _, line := range patterns {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		if err := validateGlob(rule.segments); err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", line, err)
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return r, nil
+}
+
+// LoadIgnoreFile read ignore rules from a file
+func LoadIgnoreFile(filePath string) (*IgnoreRules, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	return ParseIgnore(lines)
+}
+
+// Ignored report whether a slash separated path relative to the root is excluded,
+// everything below an excluded directory is excluded too
+func (r *IgnoreRules) Ignored(rel string, isDir bool) bool {
+	if r == nil || len(r.rules) == 0 {
+		return false
+	}
+	parts := strings.Split(strings.Trim(rel, "/"), "/")
+	for i := range parts {
+		if r.match(parts[:i+1], i < len(parts)-1 || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// match last matching rule decides
+func (r *IgnoreRules) match(parts []string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if rule.anchored {
+			ok = matchSegments(rule.segments, parts)
+		} else {
+			ok = matchSegments(rule.segments, parts[len(parts)-1:])
+		}
+		if ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// MatchGlob report whether a slash separated name matches pattern, ** matches
+// any number of path segments, other segments follow path.Match
+func MatchGlob(pattern, name string) (bool, error) {
+	segments := strings.Split(pattern, "/")
+	if err := validateGlob(segments); err != nil {
+		return false, err
+	}
+	return matchSegments(segments, strings.Split(name, "/")), nil
+}
+
+// Glob local files matching pattern, like filepath.Glob with ** support
+func Glob(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	if err := validateGlob(segments); err != nil {
+		return nil, err
+	}
+	// walk from the longest directory without wildcards
+	i := 0
+	for i < len(segments)-1 && !hasMeta(segments[i]) {
+		i++
+	}
+	root := strings.Join(segments[:i], "/")
+	switch {
+	case i == 0:
+		root = "."
+	case root == "":
+		root = "/"
+	}
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == filepath.FromSlash(root) && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(root), p)
+		if err != nil {
+			return err
+		}
+		if matchSegments(segments[i:], strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %q: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func validateGlob(segments []string) error {
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
+
+// pathFilter include and exclude rules of a directory walk
+type pathFilter struct {
+	include    []string
+	ignore     *IgnoreRules
+	ignoreFile string // relative path of the ignore file, never transferred
+}
+
+// newPathFilter include globs, exclude patterns and the ignore file below root.
+// A missing ignore file is fine, nil is returned when nothing filters
+func newPathFilter(root string, include, exclude []string, ignoreFile string) (*pathFilter, error) {
+	for _, pattern := range include {
+		if err := validateGlob(strings.Split(pattern, "/")); err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+	if ignoreFile == "" {
+		ignoreFile = DefaultIgnoreFile
+	}
+	patterns := append([]string(nil), exclude...)
+	if root != "" {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(ignoreFile)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read ignore file: %w", err)
+		}
+		if err == nil {
+			patterns = append(patterns, strings.Split(string(data), "\n")...)
+		}
+	}
+	ignore, err := ParseIgnore(patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(include) == 0 && len(ignore.rules) == 0 {
+		return &pathFilter{ignoreFile: ignoreFile}, nil
+	}
+	return &pathFilter{include: include, ignore: ignore, ignoreFile: ignoreFile}, nil
+}
+
+// skipDir directory excluded together with its content
+func (f *pathFilter) skipDir(rel string) bool {
+	return f != nil && f.ignore.Ignored(rel, true)
+}
+
+// keep file is transferred. Include patterns without a / match the base name
+func (f *pathFilter) keep(rel string) bool {
+	if f == nil {
+		return true
+	}
+	if rel == f.ignoreFile || f.ignore.Ignored(rel, false) {
+		return false
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	parts := strings.Split(rel, "/")
+	for _, pattern := range f.include {
+		segments, name := strings.Split(pattern, "/"), parts
+		if len(segments) == 1 {
+			name = parts[len(parts)-1:]
+		}
+		if matchSegments(segments, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandFiles files plus the matches of patterns, without duplicates and excluded paths
+func expandFiles(files, patterns, exclude []string) ([]string, error) {
+	if len(patterns) == 0 && len(exclude) == 0 {
+		return files, nil
+	}
+	ignore, err := ParseIgnore(exclude)
+	if err != nil {
+		return nil, err
+	}
+	all := append([]string(nil), files...)
+	for _, pattern := range patterns {
+		matches, err := Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, matches...)
+	}
+	seen := make(map[string]bool, len(all))
+	out := make([]string, 0, len(all))
+	for _, file := range all {
+		clean := filepath.Clean(file)
+		if seen[clean] || ignore.Ignored(strings.TrimPrefix(filepath.ToSlash(clean), "/"), false) {
+			continue
+		}
+		seen[clean] = true
+		out = append(out, file)
+	}
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package lingstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := ParseIgnore([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"node_modules/",
+		"/build",
+		"docs/**/*.tmp",
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"sub/app.log", false, true},
+		{"sub/keep.log", false, false},
+		{"node_modules", true, true},
+		{"web/node_modules/x/index.js", false, true},
+		{"node_modules", false, false}, // 仅匹配目录
+		{"build/out.bin", false, true},
+		{"src/build/out.bin", false, false}, // 锚定到根目录
+		{"docs/a/b/c.tmp", false, true},
+		{"docs/c.tmp", false, true},
+		{"src/c.tmp", false, false},
+		{"main.go", false, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.ignored, rules.Ignored(tc.path, tc.isDir), tc.path)
+	}
+
+	_, err = ParseIgnore([]string{"[abc"})
+	assert.Error(t, err)
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"**/*.jpg", "a.jpg", true},
+		{"**/*.jpg", "x/y/a.jpg", true},
+		{"**/*.jpg", "x/y/a.png", false},
+		{"photos/**", "photos/2024/a.jpg", true},
+		{"photos/*/a.jpg", "photos/2024/a.jpg", true},
+		{"photos/*/a.jpg", "photos/2024/05/a.jpg", false},
+		{"a/**/b/*.txt", "a/b/c.txt", true},
+	}
+	for _, tc := range cases {
+		ok, err := MatchGlob(tc.pattern, tc.name)
+		require.NoError(t, err)
+		assert.Equal(t, tc.match, ok, "%s %s", tc.pattern, tc.name)
+	}
+}
+
+func TestGlobAndExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.jpg", "x/b.jpg", "x/y/c.jpg", "x/d.png", "x/y/skip.jpg"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(name), 0644))
+	}
+
+	matches, err := Glob(filepath.Join(dir, "**", "*.jpg"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 4)
+
+	matches, err = Glob(filepath.Join(dir, "missing", "**", "*.jpg"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	files, err := expandFiles(
+		[]string{filepath.Join(dir, "a.jpg")},
+		[]string{filepath.Join(dir, "**", "*.jpg")},
+		[]string{"skip.jpg"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.jpg"),
+		filepath.Join(dir, "x", "b.jpg"),
+		filepath.Join(dir, "x", "y", "c.jpg"),
+	}, files)
+}
@@ -0,0 +1,218 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FitCover fill the box and crop the overflow
+	FitCover = "cover"
+	// FitContain fit inside the box, keeping aspect ratio
+	FitContain = "contain"
+	// FitFill stretch to the box, ignoring aspect ratio
+	FitFill = "fill"
+	// FitInside like contain but never enlarge
+	FitInside = "inside"
+)
+
+const (
+	// FormatJPEG jpeg output
+	FormatJPEG = "jpeg"
+	// FormatPNG png output
+	FormatPNG = "png"
+	// FormatWebP webp output
+	FormatWebP = "webp"
+	// FormatAVIF avif output
+	FormatAVIF = "avif"
+)
+
+// ResizeOptions image resize, a zero width or height keeps aspect ratio
+type ResizeOptions struct {
+	Width  int
+	Height int
+	Fit    string // cover, contain, fill or inside, default contain
+}
+
+// validateImageOptions check image processing options of an upload
+func validateImageOptions(resize *ResizeOptions, format string) error {
+	if resize != nil {
+		if resize.Width < 0 || resize.Height < 0 || (resize.Width == 0 && resize.Height == 0) {
+			return fmt.Errorf("invalid resize dimensions %dx%d", resize.Width, resize.Height)
+		}
+		switch resize.Fit {
+		case "", FitCover, FitContain, FitFill, FitInside:
+		default:
+			return fmt.Errorf("unsupported resize fit: %s", resize.Fit)
+		}
+	}
+	switch format {
+	case "", FormatJPEG, FormatPNG, FormatWebP, FormatAVIF:
+	default:
+		return fmt.Errorf("unsupported image format: %s", format)
+	}
+	return nil
+}
+
+// validateWatermark check watermark overlay options, zero values mean server default
+func validateWatermark(opacity int, scale float64, margin int) error {
+	if opacity < 0 || opacity > 100 {
+		return fmt.Errorf("watermark opacity must be between 1 and 100, got %d", opacity)
+	}
+	if scale < 0 || scale > 1 {
+		return fmt.Errorf("watermark scale must be between 0 and 1, got %g", scale)
+	}
+	if margin < 0 {
+		return fmt.Errorf("watermark margin must not be negative, got %d", margin)
+	}
+	return nil
+}
+
+// writeImageFields write image processing form fields
+func writeImageFields(writer *multipart.Writer, req *UploadRequest) {
+	if req.Resize != nil {
+		if req.Resize.Width > 0 {
+			writer.WriteField("resizeWidth", strconv.Itoa(req.Resize.Width))
+		}
+		if req.Resize.Height > 0 {
+			writer.WriteField("resizeHeight", strconv.Itoa(req.Resize.Height))
+		}
+		if req.Resize.Fit != "" {
+			writer.WriteField("resizeFit", req.Resize.Fit)
+		}
+	}
+	if req.Format != "" {
+		writer.WriteField("format", req.Format)
+	}
+	if req.AutoOrient {
+		writer.WriteField("autoOrient", "true")
+	}
+	if req.StripEXIF {
+		writer.WriteField("stripExif", "true")
+	}
+}
+
+// ThumbnailSpec thumbnail to derive from an existing image
+type ThumbnailSpec struct {
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Fit    string `json:"fit,omitempty"`    // cover, contain, fill or inside
+	Format string `json:"format,omitempty"` // jpeg, png, webp or avif, default source format
+	Key    string `json:"key,omitempty"`    // thumbnail key, default chosen by the server
+}
+
+// Thumbnail generated thumbnail
+type Thumbnail struct {
+	SourceKey string `json:"sourceKey"`
+	Key       string `json:"key"`
+	URL       string `json:"url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Format    string `json:"format"`
+	Size      int64  `json:"size"`
+}
+
+// ThumbnailError failed thumbnail of a batch
+type ThumbnailError struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// BatchThumbnailResult batch thumbnail result
+type BatchThumbnailResult struct {
+	Success []Thumbnail      `json:"success"`
+	Failed  []ThumbnailError `json:"failed"`
+	Total   int              `json:"total"`
+}
+
+// validateThumbnailSpec check a thumbnail spec is given and its options are valid
+func validateThumbnailSpec(spec *ThumbnailSpec) error {
+	if spec == nil {
+		return errors.New("thumbnail spec is required")
+	}
+	return validateImageOptions(&ResizeOptions{Width: spec.Width, Height: spec.Height, Fit: spec.Fit}, spec.Format)
+}
+
+// GenerateThumbnail derive a thumbnail of an existing image
+func (c *Client) GenerateThumbnail(bucket, key string, spec *ThumbnailSpec) (_ *Thumbnail, err error) {
+	ctx, op := c.startOperation("GenerateThumbnail", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateThumbnailSpec(spec); err != nil {
+		return nil, err
+	}
+	var result Thumbnail
+	if err := c.call(ctx, "POST", objectPath(bucket, key)+"/thumbnail", spec, &result); err != nil {
+		return nil, err
+	}
+	if result.SourceKey == "" {
+		result.SourceKey = key
+	}
+
+	return &result, nil
+}
+
+// BatchGenerateThumbnails derive thumbnails of several images with the same spec,
+// spec.Key is ignored so every thumbnail gets a server chosen key
+func (c *Client) BatchGenerateThumbnails(bucket string, keys []string, spec *ThumbnailSpec) (_ *BatchThumbnailResult, err error) {
+	ctx, op := c.startOperation("BatchGenerateThumbnails", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := validateThumbnailSpec(spec); err != nil {
+		return nil, err
+	}
+	result := &BatchThumbnailResult{
+		Success: make([]Thumbnail, 0),
+		Failed:  make([]ThumbnailError, 0),
+		Total:   len(keys),
+	}
+
+	itemSpec := *spec
+	itemSpec.Key = ""
+	for _, key := range keys {
+		thumbnail, err := c.WithContext(ctx).GenerateThumbnail(bucket, key, &itemSpec)
+		if err != nil {
+			result.Failed = append(result.Failed, ThumbnailError{
+				Key:   key,
+				Error: err.Error(),
+			})
+		} else {
+			result.Success = append(result.Success, *thumbnail)
+		}
+	}
+
+	return result, nil
+}
+
+// ImageInfo image properties of an object
+type ImageInfo struct {
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Format      string            `json:"format"`
+	Size        int64             `json:"size"`
+	ColorSpace  string            `json:"colorSpace,omitempty"`
+	Orientation int               `json:"orientation,omitempty"` // EXIF orientation 1-8, 0 if absent
+	EXIF        map[string]string `json:"exif,omitempty"`        // EXIF tags by name, e.g. Make, DateTimeOriginal
+}
+
+// HasLocation image carries GPS location EXIF tags
+func (i *ImageInfo) HasLocation() bool {
+	for tag := range i.EXIF {
+		if strings.HasPrefix(tag, "GPS") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetImageInfo get dimensions, format and EXIF data of an image
+func (c *Client) GetImageInfo(bucket, key string) (_ *ImageInfo, err error) {
+	ctx, op := c.startOperation("GetImageInfo", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	var result ImageInfo
+	if err := c.call(ctx, "GET", objectPath(bucket, key)+"/imageinfo", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
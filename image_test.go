@@ -0,0 +1,153 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadWithResizeAndFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(32<<20))
+		assert.Equal(t, "800", r.FormValue("resizeWidth"))
+		assert.Empty(t, r.FormValue("resizeHeight"))
+		assert.Equal(t, "cover", r.FormValue("resizeFit"))
+		assert.Equal(t, "webp", r.FormValue("format"))
+		assert.Equal(t, "true", r.FormValue("autoOrient"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 200,
+			"data": map[string]interface{}{"key": "a.webp", "width": 800, "height": 600, "format": "webp"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.UploadBytes(&UploadBytesRequest{
+		Data:       []byte("image"),
+		Filename:   "a.jpg",
+		Resize:     &ResizeOptions{Width: 800, Fit: FitCover},
+		Format:     FormatWebP,
+		AutoOrient: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 800, result.Width)
+	assert.Equal(t, 600, result.Height)
+	assert.Equal(t, "webp", result.Format)
+}
+
+func TestValidateImageOptions(t *testing.T) {
+	assert.NoError(t, validateImageOptions(&ResizeOptions{Height: 100}, FormatAVIF))
+	assert.Error(t, validateImageOptions(&ResizeOptions{}, ""))
+	assert.Error(t, validateImageOptions(&ResizeOptions{Width: -1, Height: 10}, ""))
+	assert.Error(t, validateImageOptions(&ResizeOptions{Width: 10, Fit: "stretch"}, ""))
+	assert.Error(t, validateImageOptions(nil, "bmp"))
+}
+
+func TestGenerateThumbnail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		if r.URL.Path == "/api/public/files/photos/broken.jpg/thumbnail" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"not an image"}`))
+			return
+		}
+		assert.Equal(t, "/api/public/files/photos/a.jpg/thumbnail", r.URL.Path)
+		var spec ThumbnailSpec
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&spec))
+		assert.Equal(t, 200, spec.Width)
+		assert.Equal(t, FormatWebP, spec.Format)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"key": "a_200.webp", "url": "https://cdn/a_200.webp", "width": 200, "height": 150, "format": "webp"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	thumbnail, err := client.GenerateThumbnail("photos", "a.jpg", &ThumbnailSpec{Width: 200, Format: FormatWebP})
+	require.NoError(t, err)
+	assert.Equal(t, "a.jpg", thumbnail.SourceKey)
+	assert.Equal(t, "a_200.webp", thumbnail.Key)
+	assert.Equal(t, 150, thumbnail.Height)
+
+	batch, err := client.BatchGenerateThumbnails("photos", []string{"a.jpg", "broken.jpg"}, &ThumbnailSpec{Width: 200, Format: FormatWebP, Key: "ignored"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Total)
+	assert.Len(t, batch.Success, 1)
+	require.Len(t, batch.Failed, 1)
+	assert.Equal(t, "broken.jpg", batch.Failed[0].Key)
+
+	_, err = client.GenerateThumbnail("photos", "a.jpg", &ThumbnailSpec{})
+	assert.Error(t, err)
+
+	// 缺少 spec 时返回错误而不是 panic
+	_, err = client.GenerateThumbnail("photos", "a.jpg", nil)
+	assert.ErrorContains(t, err, "thumbnail spec is required")
+	_, err = client.BatchGenerateThumbnails("photos", []string{"a.jpg"}, nil)
+	assert.ErrorContains(t, err, "thumbnail spec is required")
+}
+
+func TestUploadWithImageWatermark(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(32<<20))
+		assert.Equal(t, "true", r.FormValue("watermark"))
+		assert.Equal(t, "brand/logo.png", r.FormValue("watermarkImageKey"))
+		assert.Equal(t, "60", r.FormValue("watermarkOpacity"))
+		assert.Equal(t, "0.2", r.FormValue("watermarkScale"))
+		assert.Equal(t, "16", r.FormValue("watermarkMargin"))
+		assert.Equal(t, "bottom-right", r.FormValue("watermarkPosition"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.jpg", "watermarked": true}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.UploadBytes(&UploadBytesRequest{
+		Data:              []byte("image"),
+		Filename:          "a.jpg",
+		WatermarkImageKey: "brand/logo.png",
+		WatermarkPosition: "bottom-right",
+		WatermarkOpacity:  60,
+		WatermarkScale:    0.2,
+		WatermarkMargin:   16,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Watermarked)
+
+	_, err = client.UploadBytes(&UploadBytesRequest{Data: []byte("image"), Filename: "a.jpg", WatermarkImageKey: "logo.png", WatermarkOpacity: 150})
+	assert.Error(t, err)
+}
+
+func TestGetImageInfoAndStripEXIF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/public/upload" {
+			require.NoError(t, r.ParseMultipartForm(32<<20))
+			assert.Equal(t, "true", r.FormValue("stripExif"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.jpg"}})
+			return
+		}
+		assert.Equal(t, "/api/public/files/photos/a.jpg/imageinfo", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"width": 4032, "height": 3024, "format": "jpeg", "orientation": 6,
+				"exif": map[string]string{"Make": "Apple", "GPSLatitude": "31.23"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	info, err := client.GetImageInfo("photos", "a.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, 4032, info.Width)
+	assert.Equal(t, 6, info.Orientation)
+	assert.Equal(t, "Apple", info.EXIF["Make"])
+	assert.True(t, info.HasLocation())
+
+	_, err = client.UploadBytes(&UploadBytesRequest{Data: []byte("image"), Filename: "a.jpg", StripEXIF: true})
+	require.NoError(t, err)
+}
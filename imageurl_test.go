@@ -0,0 +1,69 @@
+package lingstorage
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageURLBuilder(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://storage.example.com/", APIKey: "test-key", APISecret: "test-secret"})
+
+	raw, err := client.ImageURL("photos", "2024/a b.jpg").
+		Resize(800, 0).
+		Quality(80).
+		Format(FormatWebP).
+		Watermark("© Ling", "bottom-right").
+		URL()
+	require.NoError(t, err)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/public/images/photos/2024/a%20b.jpg", u.EscapedPath())
+	assert.Equal(t, "resize,w_800/quality,q_80/format,webp/watermark,t_wqkgTGluZw,g_bottom-right", u.Query().Get("x-process"))
+	assert.Empty(t, u.Query().Get("signature"))
+
+	signed, err := client.ImageURL("photos", "a.jpg").Resize(100, 100).Sign(time.Hour)
+	require.NoError(t, err)
+	u, err = url.Parse(signed)
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, "test-key", q.Get("keyId"))
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), expires, 5)
+
+	// 服务端按相同规则验签
+	signature := q.Get("signature")
+	q.Del("signature")
+	assert.Equal(t, Sign("test-secret", "GET", u.EscapedPath()+"?"+q.Encode(), nil, q.Get("expires"), ""), signature)
+	assert.NotContains(t, signed, "test-secret")
+}
+
+func TestImageURLBuilderErrors(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://storage.example.com", APIKey: "test-key"})
+
+	_, err := client.ImageURL("photos", "a.jpg").Quality(0).Format(FormatWebP).URL()
+	assert.Error(t, err)
+	_, err = client.ImageURL("photos", "a.jpg").Format("gif").URL()
+	assert.Error(t, err)
+	_, err = client.ImageURL("photos", "a.jpg").Resize(100, 0).Sign(time.Hour)
+	assert.Error(t, err, "no secret to sign with")
+}
+
+func TestImageURLWatermarkImage(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://storage.example.com", APIKey: "test-key"})
+	raw, err := client.ImageURL("photos", "a.jpg").
+		WatermarkImage("brand/logo.png", WatermarkOptions{Position: "top-left", Opacity: 50, Scale: 0.25, Margin: 10}).
+		URL()
+	require.NoError(t, err)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "watermark,i_YnJhbmQvbG9nby5wbmc,g_top-left,o_50,s_0.25,x_10", u.Query().Get("x-process"))
+
+	_, err = client.ImageURL("photos", "a.jpg").WatermarkImage("logo.png", WatermarkOptions{Scale: 2}).URL()
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// InventoryFormat file format of inventory reports
+type InventoryFormat string
+
+const (
+	InventoryCSV     InventoryFormat = "csv"
+	InventoryParquet InventoryFormat = "parquet"
+)
+
+// InventoryReport result of an inventory report job. Every row holds key,
+// size, checksum, storage class and last modified time of one object
+type InventoryReport struct {
+	JobID       string          `json:"jobId"`
+	Bucket      string          `json:"bucket"`
+	Format      InventoryFormat `json:"format"`
+	DestBucket  string          `json:"destBucket"`
+	Keys        []string        `json:"keys"` // report files in DestBucket, large inventories are split
+	ObjectCount int64           `json:"objectCount"`
+	TotalSize   int64           `json:"totalSize"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+}
+
+// CreateInventoryReport start a server side inventory of every object in the
+// bucket, written to destBucket. Poll the job with WaitForInventoryReport
+func (c *Client) CreateInventoryReport(bucket string, format InventoryFormat, destBucket string) (_ *Job, err error) {
+	ctx, op := c.startOperation("CreateInventoryReport", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	switch format {
+	case InventoryCSV, InventoryParquet:
+	default:
+		return nil, fmt.Errorf("unsupported inventory format: %s", format)
+	}
+	if destBucket == "" {
+		return nil, fmt.Errorf("inventory destination bucket is empty")
+	}
+	if !c.config.SkipNameValidation {
+		if err := ValidateBucketName(destBucket); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.requireFeature(FeatureInventory); err != nil {
+		return nil, err
+	}
+	body := map[string]string{
+		"format":     string(format),
+		"destBucket": destBucket,
+	}
+	var result Job
+	if err := c.call(ctx, "POST", bucketPath(bucket)+"/inventory", body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// WaitForInventoryReport poll an inventory job until it is done or ctx ends.
+// A failed or canceled job is returned as a *JobError
+func (c *Client) WaitForInventoryReport(ctx context.Context, jobID string, pollInterval time.Duration) (*InventoryReport, error) {
+	job, err := c.WaitForJob(ctx, jobID, pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	report := &InventoryReport{JobID: job.ID, Bucket: job.Bucket}
+	if err := job.DecodeResult(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,68 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryReport(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/buckets/media/inventory":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "parquet", body["format"])
+			assert.Equal(t, "reports", body["destBucket"])
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "inv-1", "type": "inventory", "status": "pending", "bucket": "media"}})
+		case "/api/public/jobs/inv-1":
+			polls++
+			job := map[string]interface{}{"id": "inv-1", "type": "inventory", "status": "running", "bucket": "media"}
+			if polls == 2 {
+				job["status"] = "succeeded"
+				job["result"] = map[string]interface{}{
+					"format": "parquet", "destBucket": "reports",
+					"keys":        []string{"inventory/media/part-0.parquet", "inventory/media/part-1.parquet"},
+					"objectCount": 50000000, "totalSize": 1 << 40,
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": job})
+		case "/api/public/jobs/inv-2":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "inv-2", "status": "failed", "error": "destination not writable"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	job, err := client.CreateInventoryReport("media", InventoryParquet, "reports")
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", job.ID)
+
+	report, err := client.WaitForInventoryReport(context.Background(), job.ID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "media", report.Bucket)
+	assert.Equal(t, InventoryParquet, report.Format)
+	assert.Len(t, report.Keys, 2)
+	assert.EqualValues(t, 50000000, report.ObjectCount)
+
+	_, err = client.WaitForInventoryReport(context.Background(), "inv-2", time.Millisecond)
+	var jobErr *JobError
+	require.True(t, errors.As(err, &jobErr))
+	assert.Equal(t, JobFailed, jobErr.Status)
+
+	_, err = client.CreateInventoryReport("media", "json", "reports")
+	assert.Error(t, err)
+	_, err = client.CreateInventoryReport("media", InventoryCSV, "Reports")
+	assert.ErrorIs(t, err, ErrInvalidBucketName)
+}
@@ -0,0 +1,200 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultJobPollInterval poll interval of job waiters
+const DefaultJobPollInterval = 2 * time.Second
+
+// JobStatus state of an asynchronous server job
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Done job reached a final state
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// Job asynchronous server job, e.g. transcode, archive restore or batch operation
+type Job struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Status    JobStatus       `json:"status"`
+	Progress  int             `json:"progress"` // 0-100
+	Bucket    string          `json:"bucket,omitempty"`
+	Key       string          `json:"key,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"` // job type specific result
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// DecodeResult decode the job type specific result into v
+func (j *Job) DecodeResult(v interface{}) error {
+	if len(j.Result) == 0 {
+		return fmt.Errorf("job %s has no result", j.ID)
+	}
+	if err := json.Unmarshal(j.Result, v); err != nil {
+		return fmt.Errorf("failed to parse job result: %w", err)
+	}
+	return nil
+}
+
+// JobError job ended failed or canceled
+type JobError struct {
+	ID      string
+	Status  JobStatus
+	Message string
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("job %s %s: %s", e.ID, e.Status, e.Message)
+}
+
+// ListJobsRequest 列举任务请求
+type ListJobsRequest struct {
+	Type   string    // filter by job type
+	Status JobStatus // filter by status
+	Marker string
+	Limit  int
+}
+
+// ListJobsResult 列举任务结果
+type ListJobsResult struct {
+	Jobs        []Job  `json:"jobs"`
+	NextMarker  string `json:"nextMarker"`
+	IsTruncated bool   `json:"isTruncated"`
+}
+
+// GetJob get the current state of a job
+func (c *Client) GetJob(jobID string) (_ *Job, err error) {
+	ctx, op := c.startOperation("GetJob", "", jobID)
+	defer func() { c.endOperation(op, err) }()
+	var result Job
+	if err := c.fetchJob(ctx, jobID, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// fetchJob get a job into out, shared by the typed job getters
+func (c *Client) fetchJob(ctx context.Context, jobID string, out interface{}) error {
+	if jobID == "" {
+		return fmt.Errorf("job id is required")
+	}
+	return c.call(ctx, "GET", jobPath(jobID), nil, out)
+}
+
+// ListJobs list jobs, newest first
+func (c *Client) ListJobs(req *ListJobsRequest) (_ *ListJobsResult, err error) {
+	ctx, op := c.startOperation("ListJobs", "", "")
+	defer func() { c.endOperation(op, err) }()
+	q := url.Values{}
+	if req.Type != "" {
+		q.Set("type", req.Type)
+	}
+	if req.Status != "" {
+		q.Set("status", string(req.Status))
+	}
+	if req.Marker != "" {
+		q.Set("marker", req.Marker)
+	}
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+
+	var result ListJobsResult
+	if err := c.call(ctx, "GET", withQuery("/api/public/jobs", q), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// WaitForJob poll a job until it is done or ctx ends.
+// A failed or canceled job is returned together with a *JobError
+func (c *Client) WaitForJob(ctx context.Context, jobID string, pollInterval time.Duration) (*Job, error) {
+	var job *Job
+	err := pollJob(ctx, jobID, pollInterval, func() (JobStatus, string, error) {
+		var err error
+		job, err = c.WithContext(ctx).GetJob(jobID)
+		if err != nil {
+			return "", "", err
+		}
+		return job.Status, job.Error, nil
+	})
+	return job, err
+}
+
+// callJob send an API request the server may run as a job. A 200 response
+// carries the result, decoded into out; a 202 response announces a job, which
+// is polled until done or ctx ends and its result decoded into out. Returns the
+// job id, empty when the server answered directly
+func (c *Client) callJob(ctx context.Context, method, path string, body, out interface{}) (string, error) {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return "", c.handleErrorResponse(resp)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return "", c.decodeResponse(resp, out)
+	}
+
+	var accepted struct {
+		JobID string `json:"jobId"`
+	}
+	if err := c.decodeResponse(resp, &accepted); err != nil {
+		return "", err
+	}
+	if accepted.JobID == "" {
+		return "", fmt.Errorf("server accepted the request without a job id")
+	}
+	job, err := c.WaitForJob(ctx, accepted.JobID, 0)
+	if err != nil {
+		return "", err
+	}
+	return job.ID, job.DecodeResult(out)
+}
+
+// pollJob call fetch every pollInterval until the job is done or ctx ends
+func pollJob(ctx context.Context, jobID string, pollInterval time.Duration, fetch func() (status JobStatus, message string, err error)) error {
+	if pollInterval <= 0 {
+		pollInterval = DefaultJobPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		status, message, err := fetch()
+		if err != nil {
+			return err
+		}
+		if status.Done() {
+			if status != JobSucceeded {
+				return &JobError{ID: jobID, Status: status, Message: message}
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
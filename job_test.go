@@ -0,0 +1,103 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/jobs":
+			assert.Equal(t, "restore", r.URL.Query().Get("type"))
+			assert.Equal(t, "running", r.URL.Query().Get("status"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"jobs": []map[string]interface{}{{"id": "job-1", "type": "restore", "status": "running"}},
+			}})
+		case "/api/public/jobs/job-1":
+			polls++
+			job := map[string]interface{}{"id": "job-1", "type": "restore", "status": "running", "progress": polls * 50}
+			if polls == 2 {
+				job["status"] = "succeeded"
+				job["result"] = map[string]interface{}{"restoredKeys": []string{"a.txt"}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": job})
+		case "/api/public/jobs/job-2":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-2", "status": "canceled", "error": "canceled by user"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	list, err := client.ListJobs(&ListJobsRequest{Type: "restore", Status: JobRunning})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, "job-1", list.Jobs[0].ID)
+
+	job, err := client.WaitForJob(context.Background(), "job-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, JobSucceeded, job.Status)
+	assert.Equal(t, 100, job.Progress)
+	var result struct {
+		RestoredKeys []string `json:"restoredKeys"`
+	}
+	require.NoError(t, job.DecodeResult(&result))
+	assert.Equal(t, []string{"a.txt"}, result.RestoredKeys)
+
+	job, err = client.WaitForJob(context.Background(), "job-2", time.Millisecond)
+	var jobErr *JobError
+	require.True(t, errors.As(err, &jobErr))
+	assert.Equal(t, "job-2", jobErr.ID)
+	assert.Equal(t, JobCanceled, jobErr.Status)
+	assert.Equal(t, JobCanceled, job.Status)
+}
+
+func TestJobStatusDone(t *testing.T) {
+	assert.False(t, JobPending.Done())
+	assert.False(t, JobRunning.Done())
+	assert.True(t, JobSucceeded.Done())
+	assert.True(t, JobFailed.Done())
+	assert.True(t, JobCanceled.Done())
+}
+
+func TestJobIDs(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		if r.Method == "POST" {
+			// 202 响应缺少任务 ID
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "a/b#c", "status": "running"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	_, err := client.GetJob("")
+	assert.ErrorContains(t, err, "job id is required")
+	_, err = client.GetTranscodeJob("")
+	assert.ErrorContains(t, err, "job id is required")
+	assert.Empty(t, paths)
+
+	_, err = client.GetJob("a/b#c")
+	require.NoError(t, err)
+	_, err = client.GetTranscodeJob("a/b#c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/public/jobs/a%2Fb%23c", "/api/public/jobs/a%2Fb%23c"}, paths)
+
+	_, err = client.callJob(context.Background(), "POST", "/api/public/archive", nil, nil)
+	assert.ErrorContains(t, err, "without a job id")
+}
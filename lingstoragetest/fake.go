@@ -0,0 +1,535 @@
+// Package lingstoragetest provides an in-memory fake LingStorage server for
+// end-to-end tests of code built on the SDK.
+//
+//	server := lingstoragetest.NewFakeServer()
+//	defer server.Close()
+//	client := server.Client()
+package lingstoragetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+)
+
+// DefaultBucket bucket created by NewFakeServer and used by uploads without bucket
+const DefaultBucket = "default"
+
+// Object stored object
+type Object struct {
+	Data         []byte
+	ContentType  string
+	Metadata     map[string]string
+	LastModified time.Time
+}
+
+// ETag md5 of the object data
+func (o *Object) ETag() string {
+	sum := md5.Sum(o.Data)
+	return hex.EncodeToString(sum[:])
+}
+
+type bucket struct {
+	private  bool
+	settings lingstorage.CreateBucketRequest
+	objects  map[string]*Object
+}
+
+// FakeServer httptest server implementing the LingStorage public API with in-memory state:
+// upload, download, info, url, list, delete, bulk delete, copy, move, buckets, whoami and server info
+type FakeServer struct {
+	*httptest.Server
+
+	// APIKey when set, requests with another X-API-Key are rejected with 401
+	APIKey string
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewFakeServer start a fake server with an empty DefaultBucket
+func NewFakeServer() *FakeServer {
+	f := &FakeServer{buckets: map[string]*bucket{
+		DefaultBucket: {objects: make(map[string]*Object)},
+	}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Client client of the fake server, RetryCount defaults to no retries
+func (f *FakeServer) Client(opts ...lingstorage.ClientOption) *lingstorage.Client {
+	return lingstorage.NewClient(&lingstorage.Config{
+		BaseURL:    f.URL,
+		APIKey:     f.APIKey,
+		APISecret:  "fake-secret",
+		RetryCount: -1,
+	}, opts...)
+}
+
+// CreateBucket create bucket directly, no-op if it exists
+func (f *FakeServer) CreateBucket(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.buckets[name]; !ok {
+		f.buckets[name] = &bucket{objects: make(map[string]*Object)}
+	}
+}
+
+// PutObject store object directly, creating the bucket if needed
+func (f *FakeServer) PutObject(bucketName, key string, data []byte) {
+	f.CreateBucket(bucketName)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.buckets[bucketName].objects[key] = &Object{
+		Data:         append([]byte(nil), data...),
+		ContentType:  contentType(key),
+		LastModified: time.Now(),
+	}
+}
+
+// Object stored object, false if missing
+func (f *FakeServer) Object(bucketName, key string) (*Object, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := b.objects[key]
+	return obj, ok
+}
+
+// BucketSettings settings the bucket was created with, false if missing
+func (f *FakeServer) BucketSettings(name string) (lingstorage.CreateBucketRequest, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.buckets[name]
+	if !ok {
+		return lingstorage.CreateBucketRequest{}, false
+	}
+	settings := b.settings
+	settings.BucketName = name
+	settings.IsPrivate = b.private
+	return settings, true
+}
+
+// Buckets sorted bucket names
+func (f *FakeServer) Buckets() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.buckets))
+	for name := range f.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.APIKey != "" && r.Header.Get("X-API-Key") != f.APIKey {
+		writeError(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/public")
+	if path == r.URL.Path {
+		// Ping
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case path == "/upload" && r.Method == http.MethodPost:
+		f.upload(w, r)
+	case path == "/whoami" && r.Method == http.MethodGet:
+		writeData(w, lingstorage.Identity{UserID: "fake-user", Name: "fake", APIKey: r.Header.Get("X-API-Key")})
+	case path == "/info" && r.Method == http.MethodGet:
+		writeData(w, lingstorage.ServerInfo{Version: "fake", APIVersion: "v1", Features: []string{lingstorage.FeatureTags}, ServerTime: time.Now()})
+	case path == "/buckets" && r.Method == http.MethodGet:
+		f.listBuckets(w, r)
+	case path == "/buckets" && r.Method == http.MethodPost:
+		f.createBucket(w, r)
+	case strings.HasPrefix(path, "/buckets/"):
+		f.bucketRoute(w, r, strings.TrimPrefix(path, "/buckets/"))
+	case strings.HasPrefix(path, "/files/"):
+		f.fileRoute(w, r, strings.TrimPrefix(path, "/files/"))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *FakeServer) bucketRoute(w http.ResponseWriter, r *http.Request, rest string) {
+	name, action, _ := strings.Cut(rest, "/")
+	b, ok := f.buckets[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bucket not found")
+		return
+	}
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		if len(b.objects) > 0 {
+			writeError(w, http.StatusConflict, "bucket not empty")
+			return
+		}
+		delete(f.buckets, name)
+		writeData(w, nil)
+	case action == "files" && r.Method == http.MethodGet:
+		f.listFiles(w, r, b)
+	case action == "delete" && r.Method == http.MethodPost:
+		var body struct {
+			Keys []string `json:"keys"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, key := range body.Keys {
+			delete(b.objects, key)
+		}
+		writeData(w, lingstorage.DeleteFilesResult{Deleted: body.Keys})
+	case action == "domains" && r.Method == http.MethodGet:
+		writeData(w, map[string]interface{}{"domains": []string{name + ".fake.lingstorage.local"}, "isPrivate": b.private})
+	case action == "private" && r.Method == http.MethodPut:
+		var body struct {
+			IsPrivate bool `json:"isPrivate"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		b.private = body.IsPrivate
+		writeData(w, nil)
+	case action == "tags" && r.Method == http.MethodPut:
+		var body struct {
+			Tags map[string]string `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		b.settings.Tags = body.Tags
+		writeData(w, nil)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *FakeServer) fileRoute(w http.ResponseWriter, r *http.Request, rest string) {
+	bucketName, key, _ := strings.Cut(rest, "/")
+	action := ""
+	if r.Method != http.MethodDelete {
+		if i := strings.LastIndex(key, "/"); i >= 0 {
+			key, action = key[:i], key[i+1:]
+		}
+	}
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bucket not found")
+		return
+	}
+	obj, ok := b.objects[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		if !conditionsHold(r, obj) {
+			writeError(w, http.StatusPreconditionFailed, "precondition failed")
+			return
+		}
+		delete(b.objects, key)
+		writeData(w, nil)
+	case action == "info" && r.Method == http.MethodGet:
+		writeData(w, fileInfo(key, obj))
+	case action == "url" && r.Method == http.MethodGet:
+		url := fmt.Sprintf("%s/api/public/files/%s/%s/download", f.URL, bucketName, key)
+		if expires := r.URL.Query().Get("expires"); expires != "" {
+			url += "?expires=" + expires
+		}
+		writeData(w, map[string]string{"url": url})
+	case action == "download" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("ETag", `"`+obj.ETag()+`"`)
+		for k, v := range obj.Metadata {
+			w.Header().Set("X-Meta-"+k, v)
+		}
+		// handles Range requests
+		http.ServeContent(w, r, key, obj.LastModified, bytes.NewReader(obj.Data))
+	case (action == "copy" || action == "move") && r.Method == http.MethodPost:
+		var body struct {
+			DestBucket        string            `json:"destBucket"`
+			DestKey           string            `json:"destKey"`
+			MetadataDirective string            `json:"metadataDirective"`
+			Metadata          map[string]string `json:"metadata"`
+			ContentType       string            `json:"contentType"`
+			FailIfDestExists  bool              `json:"failIfDestExists"`
+			IfSourceMatch     string            `json:"ifSourceMatch"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		dest, ok := f.buckets[body.DestBucket]
+		if !ok {
+			writeError(w, http.StatusNotFound, "destination bucket not found")
+			return
+		}
+		if _, exists := dest.objects[body.DestKey]; exists && body.FailIfDestExists {
+			writeError(w, http.StatusConflict, "destination exists")
+			return
+		}
+		if body.IfSourceMatch != "" && strings.Trim(body.IfSourceMatch, `"`) != obj.ETag() {
+			writeError(w, http.StatusPreconditionFailed, "source etag does not match")
+			return
+		}
+		copied := *obj
+		copied.LastModified = time.Now()
+		if body.MetadataDirective == "REPLACE" {
+			copied.Metadata = body.Metadata
+			if body.ContentType != "" {
+				copied.ContentType = body.ContentType
+			}
+		}
+		dest.objects[body.DestKey] = &copied
+		if action == "move" && (body.DestBucket != bucketName || body.DestKey != key) {
+			delete(b.objects, key)
+		}
+		writeData(w, nil)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *FakeServer) upload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "missing file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bucketName := r.FormValue("bucket")
+	if bucketName == "" {
+		bucketName = DefaultBucket
+	}
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bucket not found")
+		return
+	}
+	key := r.FormValue("key")
+	if key == "" {
+		key = header.Filename
+	}
+	obj := &Object{Data: data, ContentType: contentType(header.Filename), LastModified: time.Now()}
+	if allowed := r.URL.Query()["allowedTypes"]; len(allowed) > 0 && !typeAllowed(obj.ContentType, header.Filename, allowed) {
+		writeError(w, http.StatusBadRequest, "file type not allowed")
+		return
+	}
+	if metadata := r.FormValue("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &obj.Metadata); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid metadata")
+			return
+		}
+	}
+	if !conditionsHold(r, b.objects[key]) {
+		writeError(w, http.StatusPreconditionFailed, "precondition failed")
+		return
+	}
+	b.objects[key] = obj
+
+	checksum := sha256.Sum256(data)
+	writeData(w, lingstorage.UploadResult{
+		Key:          key,
+		Bucket:       bucketName,
+		Filename:     header.Filename,
+		Size:         int64(len(data)),
+		OriginalSize: int64(len(data)),
+		URL:          fmt.Sprintf("%s/api/public/files/%s/%s/download", f.URL, bucketName, key),
+
+		ETag:           obj.ETag(),
+		ChecksumSHA256: hex.EncodeToString(checksum[:]),
+		StorageClass:   "STANDARD",
+		ContentType:    obj.ContentType,
+	})
+}
+
+func (f *FakeServer) listBuckets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	marker := q.Get("marker")
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 1000
+	}
+	names := make([]string, 0, len(f.buckets))
+	for name := range f.buckets {
+		if name > marker {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := lingstorage.ListBucketsResult{Buckets: names}
+	if len(names) > limit {
+		result.Buckets = names[:limit]
+		result.NextMarker = names[limit-1]
+		result.IsTruncated = true
+	}
+	writeData(w, result)
+}
+
+func (f *FakeServer) createBucket(w http.ResponseWriter, r *http.Request) {
+	var req lingstorage.CreateBucketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BucketName == "" {
+		writeError(w, http.StatusBadRequest, "invalid bucket name")
+		return
+	}
+	if _, ok := f.buckets[req.BucketName]; ok {
+		writeError(w, http.StatusConflict, "bucket already exists")
+		return
+	}
+	f.buckets[req.BucketName] = &bucket{private: req.IsPrivate, settings: req, objects: make(map[string]*Object)}
+	writeData(w, nil)
+}
+
+func (f *FakeServer) listFiles(w http.ResponseWriter, r *http.Request, b *bucket) {
+	q := r.URL.Query()
+	prefix, marker, delimiter := q.Get("prefix"), q.Get("marker"), q.Get("delimiter")
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) && key > marker {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := lingstorage.ListFilesResult{Files: []lingstorage.FileInfo{}, Directories: []string{}}
+	seen := make(map[string]bool)
+	count := 0
+	for _, key := range keys {
+		if count == limit {
+			result.IsTruncated = true
+			break
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				dir := key[:len(prefix)+i+len(delimiter)]
+				if !seen[dir] {
+					seen[dir] = true
+					result.Directories = append(result.Directories, dir)
+					count++
+				}
+				result.NextMarker = key
+				continue
+			}
+		}
+		result.Files = append(result.Files, fileInfo(key, b.objects[key]))
+		result.NextMarker = key
+		count++
+	}
+	if !result.IsTruncated {
+		result.NextMarker = ""
+	}
+	writeData(w, result)
+}
+
+func fileInfo(key string, obj *Object) lingstorage.FileInfo {
+	return lingstorage.FileInfo{
+		Key:          key,
+		Size:         int64(len(obj.Data)),
+		LastModified: obj.LastModified,
+		ETag:         obj.ETag(),
+		ContentType:  obj.ContentType,
+		Metadata:     obj.Metadata,
+		StorageClass: "STANDARD",
+	}
+}
+
+func contentType(name string) string {
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// typeAllowed match allowed types by mime type, mime prefix (image/*) or extension
+func typeAllowed(contentType, filename string, allowed []string) bool {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	for _, t := range allowed {
+		t = strings.ToLower(t)
+		switch {
+		case strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(t, "*")):
+			return true
+		case strings.HasPrefix(contentType, t), strings.TrimPrefix(t, ".") == ext:
+			return true
+		}
+	}
+	return false
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "code": 200, "data": data})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(lingstorage.APIError{StatusCode: status, Message: message})
+}
+
+// conditionsHold evaluate If-Match and If-None-Match against the current object, nil if missing
+func conditionsHold(r *http.Request, current *Object) bool {
+	matches := func(header string) bool {
+		if header == "*" {
+			return current != nil
+		}
+		if current == nil {
+			return false
+		}
+		for _, tag := range strings.Split(header, ",") {
+			if strings.Trim(strings.TrimSpace(tag), `"`) == current.ETag() {
+				return true
+			}
+		}
+		return false
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !matches(ifMatch) {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matches(ifNoneMatch) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package lingstoragetest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeServerEndToEnd(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	require.NoError(t, client.Ping())
+	require.NoError(t, client.CreateBucket(&lingstorage.CreateBucketRequest{BucketName: "photos"}))
+	buckets, err := client.ListBuckets("", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultBucket, "photos"}, buckets)
+
+	result, err := client.UploadBytes(&lingstorage.UploadBytesRequest{
+		Data:     []byte("hello"),
+		Filename: "hello.txt",
+		Bucket:   "photos",
+		Key:      "docs/hello.txt",
+		Metadata: map[string]string{"author": "ling"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Size)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", result.ETag)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", result.ChecksumSHA256)
+	assert.Equal(t, "text/plain; charset=utf-8", result.ContentType)
+
+	info, err := client.GetFileInfo("photos", "docs/hello.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.Size)
+	assert.Equal(t, "ling", info.Metadata["author"])
+
+	download, err := client.Download(&lingstorage.DownloadRequest{Bucket: "photos", Key: "docs/hello.txt"})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(download.Body)
+	download.Body.Close()
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "ling", download.Metadata["author"])
+
+	require.NoError(t, client.CopyFile(&lingstorage.CopyFileRequest{SrcBucket: "photos", SrcKey: "docs/hello.txt", DestBucket: "photos", DestKey: "docs/copy.txt"}))
+	require.NoError(t, client.CopyFile(&lingstorage.CopyFileRequest{
+		SrcBucket: "photos", SrcKey: "docs/hello.txt", DestBucket: "photos", DestKey: "docs/hello.md",
+		MetadataDirective: lingstorage.MetadataDirectiveReplace,
+		Metadata:          map[string]string{"reviewed": "yes"},
+		ContentType:       "text/markdown",
+	}))
+	replaced, ok := server.Object("photos", "docs/hello.md")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"reviewed": "yes"}, replaced.Metadata)
+	assert.Equal(t, "text/markdown", replaced.ContentType)
+	require.NoError(t, client.DeleteFile("photos", "docs/hello.md"))
+	require.NoError(t, client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: "photos", SrcKey: "docs/copy.txt", DestBucket: DefaultBucket, DestKey: "moved.txt"}))
+	_, ok = server.Object(DefaultBucket, "moved.txt")
+	assert.True(t, ok)
+	_, ok = server.Object("photos", "docs/copy.txt")
+	assert.False(t, ok)
+
+	server.PutObject("photos", "top.txt", []byte("top"))
+	list, err := client.ListFiles(&lingstorage.ListFilesRequest{Bucket: "photos", Delimiter: "/"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/"}, list.Directories)
+	require.Len(t, list.Files, 1)
+	assert.Equal(t, "top.txt", list.Files[0].Key)
+
+	// 非空存储桶不能删除
+	var apiErr *lingstorage.APIError
+	err = client.DeleteBucket("photos")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+	require.NoError(t, client.DeleteFile("photos", "docs/hello.txt"))
+	require.NoError(t, client.DeleteFile("photos", "top.txt"))
+	require.NoError(t, client.DeleteBucket("photos"))
+
+	_, err = client.GetFileInfo(DefaultBucket, "missing.txt")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestFakeServerPagination(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	for _, key := range []string{"a", "b", "c"} {
+		server.PutObject(DefaultBucket, key, []byte(key))
+	}
+
+	client := server.Client()
+	page, err := client.ListFiles(&lingstorage.ListFilesRequest{Bucket: DefaultBucket, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Files, 2)
+	assert.True(t, page.IsTruncated)
+
+	page, err = client.ListFiles(&lingstorage.ListFilesRequest{Bucket: DefaultBucket, Limit: 2, Marker: page.NextMarker})
+	require.NoError(t, err)
+	require.Len(t, page.Files, 1)
+	assert.Equal(t, "c", page.Files[0].Key)
+	assert.False(t, page.IsTruncated)
+}
+
+func TestFakeServerAPIKey(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	server.APIKey = "expected"
+
+	assert.NoError(t, server.Client().ValidateCredentials())
+	other := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, APIKey: "wrong", RetryCount: -1})
+	_, err := other.ListBuckets("", false)
+	assert.Error(t, err)
+}
+
+func TestFakeServerBucketSettings(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+
+	require.NoError(t, server.Client().CreateBucket(&lingstorage.CreateBucketRequest{BucketName: "tenant-1", IsPrivate: true, Quota: 1 << 20, StorageClass: "COLD"}))
+	settings, ok := server.BucketSettings("tenant-1")
+	require.True(t, ok)
+	assert.True(t, settings.IsPrivate)
+	assert.EqualValues(t, 1<<20, settings.Quota)
+	assert.Equal(t, "COLD", settings.StorageClass)
+	_, ok = server.BucketSettings("missing")
+	assert.False(t, ok)
+}
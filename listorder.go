@@ -0,0 +1,71 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ListSortKey attribute ListFiles sorts by
+type ListSortKey string
+
+// ListFiles sort keys
+const (
+	SortByName     ListSortKey = "name"
+	SortBySize     ListSortKey = "size"
+	SortByModified ListSortKey = "modified"
+)
+
+// FileInfo attributes selectable with ListFilesRequest.Fields
+const (
+	FieldSize         = "size"
+	FieldLastModified = "lastModified"
+	FieldETag         = "etag"
+	FieldContentType  = "contentType"
+	FieldMetadata     = "metadata"
+	FieldStorageClass = "storageClass"
+	FieldOwner        = "owner"
+)
+
+// setListOrder add the sort and field mask parameters of req to q
+func setListOrder(q url.Values, req *ListFilesRequest) error {
+	switch req.SortBy {
+	case "", SortByName, SortBySize, SortByModified:
+	default:
+		return fmt.Errorf("unsupported list sort key %q", req.SortBy)
+	}
+	if req.SortBy != "" {
+		q.Set("sort", string(req.SortBy))
+	}
+	if req.Descending {
+		q.Set("order", "desc")
+	}
+	if len(req.Fields) > 0 {
+		q.Set("fields", strings.Join(append([]string{"key"}, req.Fields...), ","))
+	}
+	return nil
+}
+
+// sortFiles order files by key, ties of size and modification time are broken by name
+func sortFiles(files []FileInfo, by ListSortKey, descending bool) {
+	less := func(a, b *FileInfo) bool {
+		switch by {
+		case SortBySize:
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case SortByModified:
+			if !a.LastModified.Equal(b.LastModified) {
+				return a.LastModified.Before(b.LastModified)
+			}
+		}
+		return a.Key < b.Key
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if descending {
+			return less(&files[j], &files[i])
+		}
+		return less(&files[i], &files[j])
+	})
+}
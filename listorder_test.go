@@ -0,0 +1,62 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFilesSortAndFields(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "size", q.Get("sort"))
+		assert.Equal(t, "desc", q.Get("order"))
+		assert.Equal(t, "key,size,lastModified", q.Get("fields"))
+		// 服务端忽略排序参数, 按名称返回
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"files": []FileInfo{
+				{Key: "a", Size: 10, LastModified: now},
+				{Key: "b", Size: 30, LastModified: now},
+				{Key: "c", Size: 10, LastModified: now},
+			},
+		}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	result, err := client.ListFiles(&ListFilesRequest{
+		Bucket:     "bkt",
+		SortBy:     SortBySize,
+		Descending: true,
+		Fields:     []string{FieldSize, FieldLastModified},
+	})
+	require.NoError(t, err)
+	var keys []string
+	for _, f := range result.Files {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"b", "c", "a"}, keys)
+
+	_, err = client.ListFiles(&ListFilesRequest{Bucket: "bkt", SortBy: "color"})
+	assert.Error(t, err)
+}
+
+func TestSortFiles(t *testing.T) {
+	now := time.Now()
+	files := []FileInfo{
+		{Key: "new", LastModified: now},
+		{Key: "old", LastModified: now.Add(-time.Hour)},
+		{Key: "mid", LastModified: now.Add(-time.Minute)},
+	}
+	sortFiles(files, SortByModified, false)
+	assert.Equal(t, "old", files[0].Key)
+	assert.Equal(t, "new", files[2].Key)
+	sortFiles(files, SortByName, true)
+	assert.Equal(t, []string{"old", "new", "mid"}, []string{files[0].Key, files[1].Key, files[2].Key})
+}
@@ -0,0 +1,152 @@
+package lingstorage
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// DefaultDebugBodyLimit max body bytes dumped in debug mode
+const DefaultDebugBodyLimit = 1024
+
+// logAttempt log one http attempt. Failed attempts are logged at warn level,
+// others at debug level. Debug mode adds redacted headers and size-capped bodies
+func (c *Client) logAttempt(req *http.Request, attempt int, resp *http.Response, err error, duration time.Duration) {
+	logger := c.config.Logger
+	if logger == nil {
+		return
+	}
+	ctx := req.Context()
+	level := slog.LevelDebug
+	if err != nil || resp.StatusCode >= 500 {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", c.RedactURL(req.URL.String())),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if op := operationFromContext(ctx); op != nil {
+		attrs = append(attrs, slog.String("operation", op.name))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", c.redactError(err).Error()))
+	}
+	if c.config.Debug {
+		attrs = append(attrs, slog.Any("requestHeaders", c.RedactHeader(req.Header)))
+		if body := c.dumpRequestBody(req); body != "" {
+			attrs = append(attrs, slog.String("requestBody", body))
+		}
+		if resp != nil {
+			attrs = append(attrs, slog.Any("responseHeaders", c.RedactHeader(resp.Header)))
+			if body := c.dumpResponseBody(resp); body != "" {
+				attrs = append(attrs, slog.String("responseBody", body))
+			}
+		}
+	}
+	logger.LogAttrs(ctx, level, "lingstorage request", attrs...)
+}
+
+// debugBodyLimit body bytes to dump, 0 disables body dumps
+func (c *Client) debugBodyLimit() int {
+	switch {
+	case c.config.DebugBodyLimit < 0:
+		return 0
+	case c.config.DebugBodyLimit == 0:
+		return DefaultDebugBodyLimit
+	default:
+		return c.config.DebugBodyLimit
+	}
+}
+
+// dumpRequestBody capped, redacted copy of the request body
+func (c *Client) dumpRequestBody(req *http.Request) string {
+	limit := c.debugBodyLimit()
+	if limit == 0 || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	return c.formatBody(data, limit)
+}
+
+// dumpResponseBody capped, redacted copy of a text response body, the body
+// stays readable. Binary bodies such as downloads are not dumped
+func (c *Client) dumpResponseBody(resp *http.Response) string {
+	limit := c.debugBodyLimit()
+	if limit == 0 || resp.Body == nil || !textContent(resp.Header.Get(constants.CONTENT_TYPE)) {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	resp.Body = &readCloser{
+		Reader: io.MultiReader(bytes.NewReader(data), resp.Body),
+		Closer: resp.Body,
+	}
+	if !validText(data) {
+		return ""
+	}
+	return c.formatBody(data, limit)
+}
+
+// validText data is UTF-8, ignoring a rune cut off by the dump limit
+func validText(data []byte) bool {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				data = data[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(data)
+}
+
+// textContent whether a body of contentType is readable text, an unknown type counts as text
+func textContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+func (c *Client) formatBody(data []byte, limit int) string {
+	truncated := len(data) > limit
+	if truncated {
+		data = data[:limit]
+	}
+	body := c.scrub(string(data))
+	if truncated {
+		body += "...(truncated)"
+	}
+	return body
+}
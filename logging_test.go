@@ -0,0 +1,111 @@
+package lingstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"data":{"url":"https://cdn.example.com/a.txt"}}` + strings.Repeat(" ", 100)))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL:        server.URL,
+		APIKey:         "key-123456",
+		APISecret:      "secret-abcdef",
+		Logger:         slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Debug:          true,
+		DebugBodyLimit: 64,
+	})
+	url, err := client.GetFileURL("bucket", "a.txt", 0)
+	require.NoError(t, err)
+	// 日志读取响应体后不影响解析
+	assert.Equal(t, "https://cdn.example.com/a.txt", url)
+
+	logged := out.String()
+	assert.NotContains(t, logged, "key-123456")
+	assert.NotContains(t, logged, "secret-abcdef")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, float64(200), entry["status"])
+	assert.Equal(t, float64(1), entry["attempt"])
+	assert.Equal(t, "GetFileURL", entry["operation"])
+	assert.Contains(t, entry["responseBody"], "...(truncated)")
+}
+
+func TestDebugLoggingSkipsBinaryBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("kind") {
+		case "typed":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("PNG-BYTES"))
+		case "untyped":
+			w.Header()["Content-Type"] = nil
+			w.Write([]byte{0xff, 0xfe, 0x00, 0x81})
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"success":true,"data":"中文"}`))
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL:        server.URL,
+		Logger:         slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Debug:          true,
+		DebugBodyLimit: 28,
+	})
+	dump := func(kind string) interface{} {
+		out.Reset()
+		resp, err := client.httpClient.Get(server.URL + "?kind=" + kind)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		client.logAttempt(req, 1, resp, nil, 0)
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+		// 日志不影响调用方读取完整响应体
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.NotEmpty(t, data)
+		return entry["responseBody"]
+	}
+
+	// 二进制响应体不写入日志
+	assert.Nil(t, dump("typed"))
+	assert.Nil(t, dump("untyped"))
+	// 截断在多字节字符中间仍视为文本
+	assert.Contains(t, dump("json"), "...(truncated)")
+}
+
+func TestLoggingQuietByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewClient(&Config{
+		BaseURL: server.URL,
+		APIKey:  "test-key",
+		Logger:  slog.New(slog.NewTextHandler(&out, nil)), // info level
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	assert.Empty(t, out.String())
+}
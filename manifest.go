@@ -0,0 +1,250 @@
+package lingstorage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// manifest formats
+const (
+	ManifestJSON = "json"
+	ManifestCSV  = "csv"
+)
+
+// manifestColumns header of CSV manifests
+var manifestColumns = []string{"bucket", "key", "path", "size", "checksum", "url"}
+
+// ManifestEntry one transferred object. Checksum is the md5 hex of the local
+// file, it matches the ETag unless the upload was compressed or processed
+type ManifestEntry struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	Path     string `json:"path,omitempty"` // local file
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Manifest record of a batch transfer
+type Manifest struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Entries   []ManifestEntry `json:"entries"`
+}
+
+// ManifestFormat format of a manifest file by extension, json unless .csv
+func ManifestFormat(filePath string) string {
+	if strings.EqualFold(filepath.Ext(filePath), ".csv") {
+		return ManifestCSV
+	}
+	return ManifestJSON
+}
+
+// Write encode the manifest as json or csv
+func (m *Manifest) Write(w io.Writer, format string) error {
+	switch format {
+	case ManifestJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		return nil
+	case ManifestCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(manifestColumns)
+		for _, e := range m.Entries {
+			cw.Write([]string{e.Bucket, e.Key, e.Path, strconv.FormatInt(e.Size, 10), e.Checksum, e.URL})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported manifest format %q", format)
+	}
+}
+
+// WriteFile write the manifest, the format follows the file extension
+func (m *Manifest) WriteFile(filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	if err := m.Write(file, ManifestFormat(filePath)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadManifest decode a json or csv manifest
+func ReadManifest(r io.Reader, format string) (*Manifest, error) {
+	switch format {
+	case ManifestJSON:
+		var m Manifest
+		if err := json.NewDecoder(r).Decode(&m); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		return &m, nil
+	case ManifestCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		m := &Manifest{}
+		for i, record := range records {
+			if i == 0 && len(record) > 0 && record[0] == manifestColumns[0] {
+				continue
+			}
+			if len(record) != len(manifestColumns) {
+				return nil, fmt.Errorf("failed to decode manifest: line %d has %d columns", i+1, len(record))
+			}
+			size, err := strconv.ParseInt(record[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode manifest: line %d: invalid size %q", i+1, record[3])
+			}
+			m.Entries = append(m.Entries, ManifestEntry{
+				Bucket: record[0], Key: record[1], Path: record[2], Size: size, Checksum: record[4], URL: record[5],
+			})
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", format)
+	}
+}
+
+// ReadManifestFile read a manifest, the format follows the file extension
+func ReadManifestFile(filePath string) (*Manifest, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+	return ReadManifest(file, ManifestFormat(filePath))
+}
+
+// newManifest manifest of uploaded local files, checksums are computed from the files
+func newManifest(entries []ManifestEntry) (*Manifest, error) {
+	for i := range entries {
+		if entries[i].Path == "" {
+			continue
+		}
+		info, err := os.Stat(entries[i].Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entries[i].Path, err)
+		}
+		entries[i].Size = info.Size()
+		if entries[i].Checksum, err = fileMD5(entries[i].Path); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Bucket != entries[j].Bucket {
+			return entries[i].Bucket < entries[j].Bucket
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return &Manifest{CreatedAt: time.Now().UTC(), Entries: entries}, nil
+}
+
+// writeManifest write a manifest of entries to filePath
+func writeManifest(filePath string, entries []ManifestEntry) error {
+	m, err := newManifest(entries)
+	if err != nil {
+		return fmt.Errorf("failed to build manifest: %w", err)
+	}
+	return m.WriteFile(filePath)
+}
+
+// manifestEntries entries of successful uploads
+func manifestEntries(files []string, results []UploadResult) []ManifestEntry {
+	entries := make([]ManifestEntry, len(results))
+	for i, r := range results {
+		entries[i] = ManifestEntry{Bucket: r.Bucket, Key: r.Key, Path: files[i], URL: r.URL}
+	}
+	return entries
+}
+
+// UploadFromManifest upload the local file of every manifest entry to its bucket and key
+func (c *Client) UploadFromManifest(m *Manifest) (_ *BatchUploadResult, err error) {
+	ctx, op := c.startOperation("UploadFromManifest", "", "")
+	defer func() { c.endOperation(op, err) }()
+	result := &BatchUploadResult{
+		Success: make([]UploadResult, 0),
+		Failed:  make([]UploadError, 0),
+		Total:   len(m.Entries),
+	}
+	client := c.WithContext(ctx)
+	for _, entry := range m.Entries {
+		if entry.Path == "" {
+			result.Failed = append(result.Failed, UploadError{File: entry.Key, Error: "manifest entry has no local path"})
+			continue
+		}
+		uploadResult, err := client.UploadFile(&UploadRequest{FilePath: entry.Path, Bucket: entry.Bucket, Key: entry.Key})
+		if err != nil {
+			result.Failed = append(result.Failed, UploadError{File: entry.Path, Error: err.Error()})
+			continue
+		}
+		result.Success = append(result.Success, *uploadResult)
+	}
+	return result, nil
+}
+
+// ManifestMismatch entry whose object differs from the manifest
+type ManifestMismatch struct {
+	Entry  ManifestEntry
+	Reason string
+}
+
+// ManifestVerification result of VerifyManifest
+type ManifestVerification struct {
+	Verified   int
+	Missing    []ManifestEntry
+	Mismatched []ManifestMismatch
+}
+
+// OK every entry matched
+func (v *ManifestVerification) OK() bool {
+	return len(v.Missing) == 0 && len(v.Mismatched) == 0
+}
+
+// VerifyManifest check that every entry exists with the recorded size and
+// checksum. Checksums are compared with the ETag, multipart ETags only get the size check
+func (c *Client) VerifyManifest(m *Manifest) (_ *ManifestVerification, err error) {
+	ctx, op := c.startOperation("VerifyManifest", "", "")
+	defer func() { c.endOperation(op, err) }()
+	client := c.WithContext(ctx)
+	v := &ManifestVerification{}
+	for _, entry := range m.Entries {
+		info, err := client.GetFileInfo(entry.Bucket, entry.Key)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				v.Missing = append(v.Missing, entry)
+				continue
+			}
+			return nil, err
+		}
+		etag := strings.Trim(info.ETag, `"`)
+		switch {
+		case info.Size != entry.Size:
+			v.Mismatched = append(v.Mismatched, ManifestMismatch{Entry: entry, Reason: fmt.Sprintf("size %d, expected %d", info.Size, entry.Size)})
+		case entry.Checksum != "" && etag != "" && !strings.Contains(etag, "-") && !strings.EqualFold(etag, entry.Checksum):
+			v.Mismatched = append(v.Mismatched, ManifestMismatch{Entry: entry, Reason: fmt.Sprintf("checksum %s, expected %s", etag, entry.Checksum)})
+		default:
+			v.Verified++
+		}
+	}
+	return v, nil
+}
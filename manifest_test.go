@@ -0,0 +1,100 @@
+package lingstorage_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchUploadManifest(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "aaa", "b.txt": "bbbb"})
+	manifestPath := filepath.Join(t.TempDir(), "manifest.csv")
+	result, err := client.BatchUpload(&lingstorage.BatchUploadRequest{
+		Files:        []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "missing.txt")},
+		Bucket:       lingstoragetest.DefaultBucket,
+		KeyPrefix:    "docs",
+		ManifestPath: manifestPath,
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Failed, 1)
+
+	m, err := lingstorage.ReadManifestFile(manifestPath)
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 2)
+	assert.Equal(t, "docs/a.txt", m.Entries[0].Key)
+	assert.Equal(t, int64(3), m.Entries[0].Size)
+	assert.Equal(t, "47bce5c74f589f4867dbd57e9ca9f808", m.Entries[0].Checksum)
+	assert.Equal(t, filepath.Join(dir, "a.txt"), m.Entries[0].Path)
+
+	v, err := client.VerifyManifest(m)
+	require.NoError(t, err)
+	assert.True(t, v.OK())
+	assert.Equal(t, 2, v.Verified)
+
+	// 篡改和删除后校验失败
+	server.PutObject(lingstoragetest.DefaultBucket, "docs/a.txt", []byte("xyz"))
+	require.NoError(t, client.DeleteFile(lingstoragetest.DefaultBucket, "docs/b.txt"))
+	v, err = client.VerifyManifest(m)
+	require.NoError(t, err)
+	assert.False(t, v.OK())
+	require.Len(t, v.Mismatched, 1)
+	assert.Contains(t, v.Mismatched[0].Reason, "checksum")
+	require.Len(t, v.Missing, 1)
+	assert.Equal(t, "docs/b.txt", v.Missing[0].Key)
+
+	// 按清单重新上传即可修复
+	reupload, err := client.UploadFromManifest(m)
+	require.NoError(t, err)
+	assert.Len(t, reupload.Success, 2)
+	v, err = client.VerifyManifest(m)
+	require.NoError(t, err)
+	assert.True(t, v.OK())
+}
+
+func TestSyncManifest(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "aaa", "sub/b.txt": "bbb"})
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	_, err := client.Sync(&lingstorage.SyncRequest{
+		LocalDir:     dir,
+		Bucket:       lingstoragetest.DefaultBucket,
+		Prefix:       "backup",
+		ManifestPath: manifestPath,
+	})
+	require.NoError(t, err)
+
+	m, err := lingstorage.ReadManifestFile(manifestPath)
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 2)
+	assert.Equal(t, "backup/a.txt", m.Entries[0].Key)
+	assert.Equal(t, "backup/sub/b.txt", m.Entries[1].Key)
+	assert.False(t, m.CreatedAt.IsZero())
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	m := &lingstorage.Manifest{Entries: []lingstorage.ManifestEntry{
+		{Bucket: "b", Key: "k,1", Path: "/tmp/k", Size: 5, Checksum: "abc", URL: "http://x/k"},
+	}}
+	for _, format := range []string{lingstorage.ManifestJSON, lingstorage.ManifestCSV} {
+		var buf bytes.Buffer
+		require.NoError(t, m.Write(&buf, format))
+		decoded, err := lingstorage.ReadManifest(&buf, format)
+		require.NoError(t, err)
+		assert.Equal(t, m.Entries, decoded.Entries, format)
+	}
+	assert.Error(t, m.Write(&bytes.Buffer{}, "xml"))
+}
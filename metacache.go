@@ -0,0 +1,144 @@
+package lingstorage
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultMetadataCacheTTL how long GetFileInfo and GetFileURL results are cached
+const DefaultMetadataCacheTTL = time.Minute
+
+// metadataCache LRU cache of object info and URLs, shared by client copies.
+// Entries are indexed by object so writes through the client drop every
+// cached info and URL of the object
+type metadataCache struct {
+	mu       sync.Mutex
+	size     int
+	ttl      time.Duration
+	lru      *list.List // front is the most recently used
+	entries  map[string]*list.Element
+	byObject map[string]map[string]struct{} // object id to entry keys
+}
+
+type metadataEntry struct {
+	key     string
+	object  string
+	value   interface{}
+	expires time.Time
+}
+
+// newMetadataCache cache of size entries, nil when size is not positive
+func newMetadataCache(size int, ttl time.Duration) *metadataCache {
+	if size <= 0 {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = DefaultMetadataCacheTTL
+	}
+	return &metadataCache{
+		size:     size,
+		ttl:      ttl,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		byObject: make(map[string]map[string]struct{}),
+	}
+}
+
+// objectID cache index of an object
+func objectID(bucket, key string) string {
+	return bucket + "\x00" + key
+}
+
+// get cached value, false when missing or expired
+func (m *metadataCache) get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*metadataEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(elem)
+		return nil, false
+	}
+	m.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+// put cache value of the object for the cache TTL, shortened to ttl when positive and smaller
+func (m *metadataCache) put(bucket, objectKey, key string, value interface{}, ttl time.Duration) {
+	if m == nil {
+		return
+	}
+	if ttl <= 0 || ttl > m.ttl {
+		ttl = m.ttl
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.entries[key]; ok {
+		m.remove(elem)
+	}
+	object := objectID(bucket, objectKey)
+	entry := &metadataEntry{key: key, object: object, value: value, expires: time.Now().Add(ttl)}
+	m.entries[key] = m.lru.PushFront(entry)
+	if m.byObject[object] == nil {
+		m.byObject[object] = make(map[string]struct{})
+	}
+	m.byObject[object][key] = struct{}{}
+	for m.lru.Len() > m.size {
+		m.remove(m.lru.Back())
+	}
+}
+
+// invalidate drop every entry of the object
+func (m *metadataCache) invalidate(bucket, objectKey string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.byObject[objectID(bucket, objectKey)] {
+		m.remove(m.entries[key])
+	}
+}
+
+// purge drop every entry
+func (m *metadataCache) purge() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lru.Init()
+	m.entries = make(map[string]*list.Element)
+	m.byObject = make(map[string]map[string]struct{})
+}
+
+// remove unlink elem, the caller holds mu
+func (m *metadataCache) remove(elem *list.Element) {
+	entry := m.lru.Remove(elem).(*metadataEntry)
+	delete(m.entries, entry.key)
+	keys := m.byObject[entry.object]
+	delete(keys, entry.key)
+	if len(keys) == 0 {
+		delete(m.byObject, entry.object)
+	}
+}
+
+// InvalidateMetadataCache drop the cached info and URLs of the objects, every
+// cached entry when no key is given. Writes through this client invalidate
+// automatically, this is for changes made by other writers
+func (c *Client) InvalidateMetadataCache(bucket string, keys ...string) {
+	if len(keys) == 0 {
+		c.metadata.purge()
+		return
+	}
+	for _, key := range keys {
+		c.metadata.invalidate(bucket, key)
+	}
+}
@@ -0,0 +1,87 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataCache(t *testing.T) {
+	var infos, urls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			atomic.AddInt32(&infos, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"key": "a.txt", "size": 3}})
+		case strings.HasSuffix(r.URL.Path, "/url"):
+			atomic.AddInt32(&urls, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"url": "https://cdn/a.txt?" + r.URL.RawQuery}})
+		default:
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, MetadataCacheSize: 2})
+
+	for i := 0; i < 3; i++ {
+		info, err := client.GetFileInfo("bkt", "a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), info.Size)
+		info.Size = 100 // 调用方修改不影响缓存
+	}
+	assert.EqualValues(t, 1, infos)
+	info, _ := client.GetFileInfo("bkt", "a.txt")
+	assert.Equal(t, int64(3), info.Size)
+
+	// 不同的过期时间和选项分别缓存
+	u1, _ := client.GetFileURL("bkt", "a.txt", time.Hour)
+	u2, _ := client.GetFileURL("bkt", "a.txt", time.Hour)
+	u3, _ := client.GetFileURL("bkt", "a.txt", 2*time.Hour)
+	assert.Equal(t, u1, u2)
+	assert.NotEqual(t, u1, u3)
+	assert.EqualValues(t, 2, urls)
+
+	// 容量为 2, 最久未使用的 info 已被淘汰
+	client.GetFileInfo("bkt", "a.txt")
+	assert.EqualValues(t, 2, infos)
+
+	// 通过同一客户端删除后失效, WithContext 派生的客户端共享缓存
+	require.NoError(t, client.WithContext(context.Background()).DeleteFile("bkt", "a.txt"))
+	client.GetFileInfo("bkt", "a.txt")
+	assert.EqualValues(t, 3, infos)
+	client.GetFileURL("bkt", "a.txt", 2*time.Hour)
+	assert.EqualValues(t, 3, urls)
+
+	client.InvalidateMetadataCache("")
+	client.GetFileInfo("bkt", "a.txt")
+	assert.EqualValues(t, 4, infos)
+}
+
+func TestMetadataCacheTTL(t *testing.T) {
+	cache := newMetadataCache(10, 20*time.Millisecond)
+	cache.put("bkt", "a", "k1", "v1", 0)
+	cache.put("bkt", "a", "k2", "v2", 5*time.Millisecond)
+	_, ok := cache.get("k1")
+	assert.True(t, ok)
+	time.Sleep(10 * time.Millisecond)
+	_, ok = cache.get("k2")
+	assert.False(t, ok)
+	time.Sleep(15 * time.Millisecond)
+	_, ok = cache.get("k1")
+	assert.False(t, ok)
+	assert.Empty(t, cache.byObject)
+
+	assert.Nil(t, newMetadataCache(0, time.Minute))
+	var disabled *metadataCache
+	disabled.put("bkt", "a", "k", "v", 0)
+	_, ok = disabled.get("k")
+	assert.False(t, ok)
+}
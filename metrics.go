@@ -0,0 +1,19 @@
+package lingstorage
+
+import "time"
+
+// transfer directions reported to MetricsCollector.ObserveBytes
+const (
+	DirectionUpload   = "upload"
+	DirectionDownload = "download"
+)
+
+// MetricsCollector receive client metrics, implementations must be safe for concurrent use
+type MetricsCollector interface {
+	// ObserveRequest called once per operation. statusCode is the last http status, 0 if no response
+	ObserveRequest(operation string, statusCode int, err error, duration time.Duration)
+	// ObserveRetry called for every retried attempt, attempt starts from 2
+	ObserveRetry(operation string, attempt int)
+	// ObserveBytes called with transferred payload bytes, direction is "upload" or "download"
+	ObserveBytes(operation, direction string, n int64)
+}
@@ -0,0 +1,307 @@
+// Package migrate copies objects from another object store into LingStorage.
+// Transfers run concurrently, are checked against the source ETag and can be
+// resumed: completed objects are appended to a state file and skipped on the
+// next run.
+//
+//	src := &migrate.S3Source{Endpoint: "https://s3.us-east-1.amazonaws.com", Bucket: "old", AccessKeyID: id, SecretAccessKey: secret}
+//	report, err := migrate.Run(ctx, client, src, migrate.Options{Bucket: "new", StateFile: "migrate.state"})
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+)
+
+const (
+	// DefaultConcurrency parallel object transfers
+	DefaultConcurrency = 8
+	// DefaultMultipartThreshold objects of at least this size are streamed as multipart uploads
+	DefaultMultipartThreshold int64 = 64 << 20
+)
+
+// Object object of a source
+type Object struct {
+	Key          string
+	Size         int64
+	ETag         string // md5 hex for single part uploads, used for checksum verification
+	LastModified time.Time
+}
+
+// Source object store to migrate from
+type Source interface {
+	// List call fn for every object below prefix, a fn error stops the listing
+	List(ctx context.Context, prefix string, fn func(obj Object) error) error
+	// Open read the content of an object
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Options migration options
+type Options struct {
+	Bucket      string // destination bucket
+	Prefix      string // source prefix to migrate
+	KeyPrefix   string // prepended to destination keys
+	Concurrency int    // default 8
+	// MultipartThreshold objects of at least this many bytes are streamed part
+	// by part instead of buffered whole, default 64MB
+	MultipartThreshold int64
+	// StateFile records completed objects, a rerun skips them unless their ETag changed
+	StateFile string
+	// SkipChecksum disable md5 verification against the source ETag
+	SkipChecksum bool
+	OnProgress   func(progress Progress)
+}
+
+// Progress running totals of a migration
+type Progress struct {
+	Listed  int
+	Copied  int
+	Skipped int // completed in a previous run
+	Failed  int
+	Bytes   int64
+	Current string // key of the object just finished
+}
+
+// Failure object that could not be migrated
+type Failure struct {
+	Key string
+	Err error
+}
+
+// Report result of a migration
+type Report struct {
+	Progress
+	Failures []Failure
+	Duration time.Duration
+}
+
+// ErrChecksumMismatch transferred content does not match the source ETag
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Run copy every object of src below opts.Prefix into opts.Bucket. Per object
+// failures are collected in the report, the returned error is set when the
+// migration could not run or ctx ended
+func Run(ctx context.Context, client *lingstorage.Client, src Source, opts Options) (*Report, error) {
+	start := time.Now()
+	if opts.Bucket == "" {
+		return nil, errors.New("destination bucket is required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.MultipartThreshold <= 0 {
+		opts.MultipartThreshold = DefaultMultipartThreshold
+	}
+	state, err := openState(opts.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer state.Close()
+
+	m := &migration{client: client.WithContext(ctx), src: src, opts: opts, state: state, report: &Report{}}
+	objects := make(chan Object)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range objects {
+				m.finish(obj, m.copy(ctx, obj))
+			}
+		}()
+	}
+
+	listErr := src.List(ctx, opts.Prefix, func(obj Object) error {
+		m.mu.Lock()
+		m.report.Listed++
+		m.mu.Unlock()
+		if state.done(obj) {
+			m.finish(obj, errSkipped)
+			return nil
+		}
+		select {
+		case objects <- obj:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(objects)
+	wg.Wait()
+
+	m.report.Duration = time.Since(start)
+	sort.Slice(m.report.Failures, func(i, j int) bool { return m.report.Failures[i].Key < m.report.Failures[j].Key })
+	if listErr != nil {
+		return m.report, fmt.Errorf("failed to list source: %w", listErr)
+	}
+	return m.report, ctx.Err()
+}
+
+// errSkipped object completed in a previous run
+var errSkipped = errors.New("skipped")
+
+type migration struct {
+	client *lingstorage.Client
+	src    Source
+	opts   Options
+	state  *stateFile
+	mu     sync.Mutex
+	report *Report
+}
+
+// copy transfer one object
+func (m *migration) copy(ctx context.Context, obj Object) error {
+	body, err := m.src.Open(ctx, obj.Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	h := md5.New()
+	key := strings.TrimPrefix(path.Join(m.opts.KeyPrefix, obj.Key), "/")
+	if obj.Size >= m.opts.MultipartThreshold {
+		_, err = m.client.UploadMultipart(&lingstorage.UploadMultipartRequest{
+			Reader: io.TeeReader(body, h),
+			Bucket: m.opts.Bucket,
+			Key:    key,
+			Size:   obj.Size,
+		})
+	} else {
+		_, err = m.client.UploadFromReader(&lingstorage.UploadFromReaderRequest{
+			Reader:   io.TeeReader(body, h),
+			Filename: path.Base(obj.Key),
+			Size:     obj.Size,
+			Bucket:   m.opts.Bucket,
+			Key:      key,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if !m.opts.SkipChecksum {
+		if err := verify(h, obj); err != nil {
+			// the object is only known to be bad after it was stored, drop it
+			// so no corrupted copy is left behind
+			if delErr := m.client.DeleteFile(m.opts.Bucket, key); delErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to delete mismatched object: %w", delErr))
+			}
+			return err
+		}
+	}
+	return m.state.record(obj)
+}
+
+// verify compare the md5 of the transferred bytes with the source ETag,
+// multipart ETags are not content md5s and are not checked
+func verify(h hash.Hash, obj Object) error {
+	etag := strings.ToLower(strings.Trim(obj.ETag, `"`))
+	if len(etag) != 32 || strings.Contains(etag, "-") {
+		return nil
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != etag {
+		return fmt.Errorf("%w: source etag %s, transferred %s", ErrChecksumMismatch, etag, sum)
+	}
+	return nil
+}
+
+// finish count a finished object and report progress
+func (m *migration) finish(obj Object, err error) {
+	m.mu.Lock()
+	switch {
+	case err == nil:
+		m.report.Copied++
+		m.report.Bytes += obj.Size
+	case errors.Is(err, errSkipped):
+		m.report.Skipped++
+	default:
+		m.report.Failed++
+		m.report.Failures = append(m.report.Failures, Failure{Key: obj.Key, Err: err})
+	}
+	m.report.Current = obj.Key
+	progress := m.report.Progress
+	m.mu.Unlock()
+	if m.opts.OnProgress != nil {
+		m.opts.OnProgress(progress)
+	}
+}
+
+// stateFile append only record of completed objects, one "etag key" line each
+type stateFile struct {
+	mu        sync.Mutex
+	file      *os.File
+	completed map[string]string // key -> etag
+}
+
+func openState(filePath string) (*stateFile, error) {
+	s := &stateFile{completed: make(map[string]string)}
+	if filePath == "" {
+		return s, nil
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		etag, key, ok := strings.Cut(scanner.Text(), " ")
+		if ok {
+			s.completed[key] = etag
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	s.file = file
+	return s, nil
+}
+
+// done object was completed with the same ETag
+func (s *stateFile) done(obj Object) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	etag, ok := s.completed[obj.Key]
+	return ok && etag == stateETag(obj)
+}
+
+func (s *stateFile) record(obj Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed[obj.Key] = stateETag(obj)
+	if s.file == nil {
+		return nil
+	}
+	if _, err := fmt.Fprintf(s.file, "%s %s\n", stateETag(obj), obj.Key); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+func (s *stateFile) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// stateETag etag written to the state file, "-" when the source has none
+func stateETag(obj Object) string {
+	if etag := strings.Trim(obj.ETag, `"`); etag != "" && !strings.ContainsAny(etag, " \n") {
+		return etag
+	}
+	return "-"
+}
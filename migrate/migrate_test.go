@@ -0,0 +1,184 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySource 内存中的数据源
+type memorySource struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string // 覆盖默认的 md5
+	failing map[string]bool
+	opened  []string
+}
+
+func newMemorySource(objects map[string]string) *memorySource {
+	s := &memorySource{objects: make(map[string][]byte), etags: make(map[string]string), failing: make(map[string]bool)}
+	for k, v := range objects {
+		s.objects[k] = []byte(v)
+	}
+	return s
+}
+
+func (s *memorySource) List(ctx context.Context, prefix string, fn func(obj Object) error) error {
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sum := md5.Sum(s.objects[k])
+		etag := hex.EncodeToString(sum[:])
+		if e, ok := s.etags[k]; ok {
+			etag = e
+		}
+		if err := fn(Object{Key: k, Size: int64(len(s.objects[k])), ETag: etag}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memorySource) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opened = append(s.opened, key)
+	if s.failing[key] {
+		return nil, errors.New("source unavailable")
+	}
+	return io.NopCloser(bytes.NewReader(s.objects[key])), nil
+}
+
+func TestRunResumes(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	src := newMemorySource(map[string]string{
+		"photos/a.jpg": "aaa",
+		"photos/b.jpg": "bbbb",
+		"photos/c.jpg": "cc",
+		"other/x.txt":  "x",
+	})
+	src.failing["photos/c.jpg"] = true
+	stateFile := filepath.Join(t.TempDir(), "migrate.state")
+
+	var last Progress
+	report, err := Run(context.Background(), client, src, Options{
+		Bucket:     lingstoragetest.DefaultBucket,
+		Prefix:     "photos/",
+		KeyPrefix:  "migrated",
+		StateFile:  stateFile,
+		OnProgress: func(p Progress) { last = p },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Listed)
+	assert.Equal(t, 2, report.Copied)
+	assert.Equal(t, int64(7), report.Bytes)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "photos/c.jpg", report.Failures[0].Key)
+	assert.Equal(t, 1, last.Failed)
+
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "migrated/photos/b.jpg")
+	require.True(t, ok)
+	assert.Equal(t, "bbbb", string(obj.Data))
+
+	// 重新运行时跳过已完成的对象，只重试失败项
+	src.failing["photos/c.jpg"] = false
+	src.opened = nil
+	report, err = Run(context.Background(), client, src, Options{
+		Bucket:    lingstoragetest.DefaultBucket,
+		Prefix:    "photos/",
+		KeyPrefix: "migrated",
+		StateFile: stateFile,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Copied)
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, []string{"photos/c.jpg"}, src.opened)
+}
+
+func TestRunChecksumMismatch(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	src := newMemorySource(map[string]string{"a.txt": "aaa", "multipart.bin": "data"})
+	src.etags["a.txt"] = "00000000000000000000000000000000"
+	src.etags["multipart.bin"] = "abc-2"
+
+	report, err := Run(context.Background(), server.Client(), src, Options{Bucket: lingstoragetest.DefaultBucket})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Copied)
+	require.Len(t, report.Failures, 1)
+	assert.True(t, errors.Is(report.Failures[0].Err, ErrChecksumMismatch))
+	// 校验失败的对象被删除
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "a.txt")
+	assert.False(t, ok)
+
+	report, err = Run(context.Background(), server.Client(), src, Options{Bucket: lingstoragetest.DefaultBucket, SkipChecksum: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Copied)
+}
+
+func TestRunCanceled(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, server.Client(), newMemorySource(map[string]string{"a": "a"}), Options{Bucket: lingstoragetest.DefaultBucket})
+	assert.Error(t, err)
+}
+
+func TestRunStreamsLargeObjects(t *testing.T) {
+	var mu sync.Mutex
+	var parts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const upload = "/api/public/files/dst/big.bin/uploads"
+		switch {
+		case r.URL.Path == upload && r.Method == "POST":
+			w.Write([]byte(`{"success":true,"data":{"uploadId":"u1"}}`))
+		case strings.HasPrefix(r.URL.Path, upload+"/u1/parts/") && r.Method == "PUT":
+			data, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			parts = append(parts, string(data))
+			mu.Unlock()
+			w.Write([]byte(`{"success":true,"data":{"etag":"e"}}`))
+		case r.URL.Path == upload+"/u1/complete":
+			w.Write([]byte(`{"success":true,"data":{"etag":"final"}}`))
+		default:
+			// 大对象不会走整体上传
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1, SkipCapabilityCheck: true})
+
+	data := strings.Repeat("x", 100)
+	report, err := Run(context.Background(), client, newMemorySource(map[string]string{"big.bin": data}), Options{Bucket: "dst", MultipartThreshold: 50})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, 1, report.Copied)
+	assert.Equal(t, []string{data}, parts)
+}
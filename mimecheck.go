@@ -0,0 +1,110 @@
+package lingstorage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ErrDisallowedType upload content does not match UploadRequest.AllowedTypes
+var ErrDisallowedType = errors.New("lingstorage: file type not allowed")
+
+// sniffLen bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// checkAllowedType sniff the first bytes of body and check them and the
+// filename extension against allowed, which holds MIME types, "type/*"
+// wildcards and extensions such as ".pdf". The extension only counts when
+// the content does not contradict it, so a renamed executable is rejected
+func checkAllowedType(filename string, body io.Reader, allowed []string) error {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("failed to read upload for type check: %w", err)
+	}
+	sniffed := sniffMediaType(head[:n])
+	ext := strings.ToLower(path.Ext(filename))
+	extType := baseMediaType(mime.TypeByExtension(ext))
+
+	candidates := []string{sniffed}
+	if extType != "" && extType != sniffed && extensionConsistent(extType, sniffed) {
+		candidates = append(candidates, extType)
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if !strings.Contains(entry, "/") {
+			// an extension, "pdf" and ".pdf" alike
+			if "."+strings.TrimPrefix(entry, ".") == ext && (extType == "" || extensionConsistent(extType, sniffed)) {
+				return nil
+			}
+			continue
+		}
+		for _, candidate := range candidates {
+			if mediaTypeMatches(entry, candidate) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s detected as %s, allowed %s", ErrDisallowedType, filename, sniffed, strings.Join(allowed, ", "))
+}
+
+// executableSignatures magic numbers of executables, which http.DetectContentType
+// reports as application/octet-stream
+var executableSignatures = []struct {
+	magic     string
+	mediaType string
+}{
+	{"MZ", "application/vnd.microsoft.portable-executable"},
+	{"\x7fELF", "application/x-elf"},
+	{"\xfe\xed\xfa\xce", "application/x-mach-binary"},
+	{"\xfe\xed\xfa\xcf", "application/x-mach-binary"},
+	{"\xce\xfa\xed\xfe", "application/x-mach-binary"},
+	{"\xcf\xfa\xed\xfe", "application/x-mach-binary"},
+}
+
+// sniffMediaType media type of content starting with head
+func sniffMediaType(head []byte) string {
+	for _, sig := range executableSignatures {
+		if bytes.HasPrefix(head, []byte(sig.magic)) {
+			return sig.mediaType
+		}
+	}
+	return baseMediaType(http.DetectContentType(head))
+}
+
+// extensionConsistent content sniffed as sniffed can be of type extType.
+// The sniffer only knows a few formats and reports the rest as generic types
+func extensionConsistent(extType, sniffed string) bool {
+	switch {
+	case extType == sniffed, sniffed == "application/octet-stream":
+		return true
+	case sniffed == "text/plain":
+		return strings.HasPrefix(extType, "text/") || strings.HasSuffix(extType, "json") ||
+			strings.HasSuffix(extType, "xml") || strings.HasSuffix(extType, "javascript") || strings.HasSuffix(extType, "yaml")
+	case sniffed == "text/xml":
+		return strings.HasSuffix(extType, "xml")
+	case sniffed == "application/zip":
+		// office documents, epub, jar and the like are zip containers
+		return strings.HasPrefix(extType, "application/vnd.") || strings.Contains(extType, "zip") || strings.HasSuffix(extType, "java-archive")
+	}
+	return false
+}
+
+// mediaTypeMatches pattern is the media type or a "type/*" wildcard of it
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return pattern == mediaType
+}
+
+// baseMediaType media type without parameters, lower case
+func baseMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
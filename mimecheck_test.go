@@ -0,0 +1,65 @@
+package lingstorage
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	exeHeader = []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff")
+	zipHeader = []byte("PK\x03\x04\x14\x00\x06\x00")
+)
+
+func TestCheckAllowedType(t *testing.T) {
+	for _, tc := range []struct {
+		filename string
+		data     []byte
+		allowed  []string
+		ok       bool
+	}{
+		{"logo.png", pngHeader, []string{"image/*"}, true},
+		{"logo.png", pngHeader, []string{"image/png"}, true},
+		{"logo.png", pngHeader, []string{".png"}, true},
+		{"logo.png", pngHeader, []string{"application/pdf"}, false},
+		// 改了扩展名的可执行文件
+		{"logo.png", exeHeader, []string{"image/*"}, false},
+		{"logo.png", exeHeader, []string{"png"}, false},
+		{"data.json", []byte(`{"a":1}`), []string{"application/json"}, true},
+		{"notes.txt", []byte("hello"), []string{"text/*"}, true},
+		{"report.docx", zipHeader, []string{".docx"}, true},
+		{"report.docx", pngHeader, []string{".docx"}, false},
+		{"empty.bin", nil, []string{"application/octet-stream"}, false},
+	} {
+		err := checkAllowedType(tc.filename, bytes.NewReader(tc.data), tc.allowed)
+		if tc.ok {
+			assert.NoError(t, err, "%s %v", tc.filename, tc.allowed)
+		} else {
+			assert.ErrorIs(t, err, ErrDisallowedType, "%s %v", tc.filename, tc.allowed)
+		}
+	}
+}
+
+func TestUploadRejectsDisallowedType(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"success":true,"data":{"key":"logo.png"}}`))
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	_, err := client.UploadBytes(&UploadBytesRequest{Data: exeHeader, Filename: "logo.png", AllowedTypes: []string{"image/*"}})
+	assert.ErrorIs(t, err, ErrDisallowedType)
+	assert.Zero(t, requests)
+
+	_, err = client.UploadBytes(&UploadBytesRequest{Data: pngHeader, Filename: "logo.png", AllowedTypes: []string{"image/*"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, requests)
+}
@@ -0,0 +1,84 @@
+package lingstorage
+
+import (
+	"fmt"
+)
+
+// moderation scan types
+const (
+	ModerationAdult     = "adult"
+	ModerationViolence  = "violence"
+	ModerationTerrorism = "terrorism"
+	ModerationPolitics  = "politics"
+	ModerationAd        = "ad"
+)
+
+// moderation verdicts
+const (
+	VerdictPass   = "pass"
+	VerdictReview = "review"
+	VerdictBlock  = "block"
+)
+
+// moderation actions of flagged uploads
+const (
+	// ModerationActionFlag keep the object and report the verdict
+	ModerationActionFlag = "flag"
+	// ModerationActionQuarantine keep the object but block downloads until reviewed
+	ModerationActionQuarantine = "quarantine"
+	// ModerationActionReject reject the upload
+	ModerationActionReject = "reject"
+)
+
+// ModerationPolicy content moderation of an upload
+type ModerationPolicy struct {
+	Types     []string `json:"types"`               // scan types, default all
+	Action    string   `json:"action"`              // action on flagged content, default flag
+	Threshold float64  `json:"threshold,omitempty"` // min label score 0-1 to flag, default chosen by the server
+}
+
+// ModerationLabel verdict of one scan type
+type ModerationLabel struct {
+	Type    string  `json:"type"`
+	Label   string  `json:"label"` // detailed label, e.g. nudity
+	Score   float64 `json:"score"` // confidence 0-1
+	Verdict string  `json:"verdict"`
+}
+
+// ModerationResult moderation verdict of an object
+type ModerationResult struct {
+	Verdict     string            `json:"verdict"` // pass, review or block
+	Labels      []ModerationLabel `json:"labels"`
+	Quarantined bool              `json:"quarantined"`
+}
+
+// Flagged content needs review or is blocked
+func (r *ModerationResult) Flagged() bool {
+	return r.Verdict == VerdictReview || r.Verdict == VerdictBlock
+}
+
+func validateModerationPolicy(policy *ModerationPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	switch policy.Action {
+	case "", ModerationActionFlag, ModerationActionQuarantine, ModerationActionReject:
+	default:
+		return fmt.Errorf("unsupported moderation action: %s", policy.Action)
+	}
+	if policy.Threshold < 0 || policy.Threshold > 1 {
+		return fmt.Errorf("moderation threshold must be between 0 and 1, got %g", policy.Threshold)
+	}
+	return nil
+}
+
+// ScanObject run content moderation on an existing object, empty types scans all
+func (c *Client) ScanObject(bucket, key string, types []string) (_ *ModerationResult, err error) {
+	ctx, op := c.startOperation("ScanObject", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	var result ModerationResult
+	if err := c.call(ctx, "POST", objectPath(bucket, key)+"/moderation", map[string][]string{"types": types}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,61 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/files/ugc/a.jpg/moderation", r.URL.Path)
+		var body map[string][]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"adult", "violence"}, body["types"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"verdict": "review",
+			"labels":  []map[string]interface{}{{"type": "adult", "label": "suggestive", "score": 0.72, "verdict": "review"}},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.ScanObject("ugc", "a.jpg", []string{ModerationAdult, ModerationViolence})
+	require.NoError(t, err)
+	assert.True(t, result.Flagged())
+	require.Len(t, result.Labels, 1)
+	assert.Equal(t, 0.72, result.Labels[0].Score)
+}
+
+func TestUploadWithModerationPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(32<<20))
+		var policy ModerationPolicy
+		require.NoError(t, json.Unmarshal([]byte(r.FormValue("moderationPolicy")), &policy))
+		assert.Equal(t, ModerationActionQuarantine, policy.Action)
+		assert.Equal(t, []string{"adult"}, policy.Types)
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{
+			"key":        "a.jpg",
+			"moderation": map[string]interface{}{"verdict": "block", "quarantined": true},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.UploadBytes(&UploadBytesRequest{
+		Data:             []byte("image"),
+		Filename:         "a.jpg",
+		ModerationPolicy: &ModerationPolicy{Types: []string{ModerationAdult}, Action: ModerationActionQuarantine},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Moderation)
+	assert.True(t, result.Moderation.Quarantined)
+	assert.True(t, result.Moderation.Flagged())
+
+	_, err = client.UploadBytes(&UploadBytesRequest{Data: []byte("x"), Filename: "a.jpg", ModerationPolicy: &ModerationPolicy{Action: "delete"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrDestinationExists MoveFile with FailIfDestExists found an object at the destination
+	ErrDestinationExists = errors.New("lingstorage: destination exists")
+	// ErrSourceChanged the source ETag no longer matches OnlyIfSourceETagMatches
+	ErrSourceChanged = errors.New("lingstorage: source changed")
+	// ErrSourceVanished the source was deleted or moved away before the move completed
+	ErrSourceVanished = errors.New("lingstorage: source vanished")
+)
+
+// MoveError failed MoveFile, Reason is one of ErrDestinationExists,
+// ErrSourceChanged and ErrSourceVanished; errors.Is matches both the reason
+// and the underlying API error
+type MoveError struct {
+	Reason error
+	Src    string // bucket/key
+	Dest   string // bucket/key
+	Err    error
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("move %s to %s: %v: %v", e.Src, e.Dest, e.Reason, e.Err)
+}
+
+func (e *MoveError) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// moveError classify a failed move. A missing object is looked up again,
+// because a retried move whose first attempt landed also reports the source
+// as missing; with OnlyIfSourceETagMatches that move is recognized as done
+func (c *Client) moveError(ctx context.Context, req *MoveFileRequest, err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	var reason error
+	switch apiErr.StatusCode {
+	case http.StatusConflict:
+		reason = ErrDestinationExists
+	case http.StatusPreconditionFailed:
+		if req.OnlyIfSourceETagMatches != "" {
+			reason = ErrSourceChanged
+		} else if req.FailIfDestExists {
+			reason = ErrDestinationExists
+		}
+	case http.StatusNotFound:
+		var src FileInfo
+		statErr := c.call(ctx, "GET", objectPath(req.SrcBucket, req.SrcKey)+"/info", nil, &src)
+		if !isNotFound(statErr) {
+			// the source is there, the destination bucket is what is missing
+			break
+		}
+		if req.OnlyIfSourceETagMatches != "" {
+			var dest FileInfo
+			if c.call(ctx, "GET", objectPath(req.DestBucket, req.DestKey)+"/info", nil, &dest) == nil &&
+				strings.Trim(dest.ETag, `"`) == strings.Trim(req.OnlyIfSourceETagMatches, `"`) {
+				return nil
+			}
+		}
+		reason = ErrSourceVanished
+	}
+	if reason == nil {
+		return err
+	}
+	return &MoveError{
+		Reason: reason,
+		Src:    req.SrcBucket + "/" + req.SrcKey,
+		Dest:   req.DestBucket + "/" + req.DestKey,
+		Err:    err,
+	}
+}
+
+// isNotFound err is a 404 API error
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
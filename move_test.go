@@ -0,0 +1,75 @@
+package lingstorage_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveFileConditions(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	bucket := lingstoragetest.DefaultBucket
+	server.PutObject(bucket, "inbox/a.csv", []byte("a"))
+	server.PutObject(bucket, "done/a.csv", []byte("old"))
+
+	err := client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: bucket, SrcKey: "inbox/a.csv", DestBucket: bucket, DestKey: "done/a.csv", FailIfDestExists: true})
+	assert.ErrorIs(t, err, lingstorage.ErrDestinationExists)
+	var apiErr *lingstorage.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	old, _ := server.Object(bucket, "done/a.csv")
+	assert.Equal(t, "old", string(old.Data))
+
+	err = client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: bucket, SrcKey: "inbox/a.csv", DestBucket: bucket, DestKey: "done/b.csv", OnlyIfSourceETagMatches: "not-the-etag"})
+	assert.ErrorIs(t, err, lingstorage.ErrSourceChanged)
+
+	src, _ := server.Object(bucket, "inbox/a.csv")
+	require.NoError(t, client.MoveFile(&lingstorage.MoveFileRequest{
+		SrcBucket: bucket, SrcKey: "inbox/a.csv", DestBucket: bucket, DestKey: "done/b.csv",
+		FailIfDestExists: true, OnlyIfSourceETagMatches: `"` + src.ETag() + `"`,
+	}))
+
+	// 源对象已被其他流程移走
+	err = client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: bucket, SrcKey: "inbox/a.csv", DestBucket: bucket, DestKey: "done/c.csv"})
+	assert.ErrorIs(t, err, lingstorage.ErrSourceVanished)
+	var moveErr *lingstorage.MoveError
+	require.True(t, errors.As(err, &moveErr))
+	assert.Equal(t, bucket+"/inbox/a.csv", moveErr.Src)
+
+	// 目标存储桶不存在不是源对象消失
+	server.PutObject(bucket, "inbox/d.csv", []byte("d"))
+	err = client.MoveFile(&lingstorage.MoveFileRequest{SrcBucket: bucket, SrcKey: "inbox/d.csv", DestBucket: "missing", DestKey: "d.csv"})
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &moveErr))
+}
+
+func TestMoveFileRetryAlreadyMoved(t *testing.T) {
+	// 第一次移动已生效但响应丢失, 重试时源对象不存在
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/bkt/src.bin/move", "/api/public/files/bkt/src.bin/info":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success":false,"message":"file not found"}`))
+		case "/api/public/files/bkt/dst.bin/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"key": "dst.bin", "etag": `"e1"`}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+
+	req := &lingstorage.MoveFileRequest{SrcBucket: "bkt", SrcKey: "src.bin", DestBucket: "bkt", DestKey: "dst.bin", OnlyIfSourceETagMatches: "e1"}
+	require.NoError(t, client.MoveFile(req))
+
+	req.OnlyIfSourceETagMatches = "e2"
+	assert.ErrorIs(t, client.MoveFile(req), lingstorage.ErrSourceVanished)
+}
@@ -0,0 +1,189 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultCopyPartSize bytes copied by one part copy request
+	DefaultCopyPartSize int64 = 512 << 20
+	// DefaultCopyConcurrency parallel part copies of a multipart copy
+	DefaultCopyConcurrency = 4
+	// maxCopyParts parts of a multipart copy when the server reports no limit
+	maxCopyParts = 10000
+)
+
+// CopyPart part of a multipart copy
+type CopyPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// copyPartRange byte range of one part copy, both ends inclusive
+type copyPartRange struct {
+	number     int
+	start, end int64
+}
+
+// sourceInfo info of the copy source
+func (c *Client) sourceInfo(ctx context.Context, req *CopyFileRequest) (*FileInfo, error) {
+	var info FileInfo
+	if err := c.call(ctx, "GET", objectPath(req.SrcBucket, req.SrcKey)+"/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// copyMultipart copy the source as ranged part copies into a multipart upload
+// of the destination. Parts are pinned to the source ETag so a source replaced
+// mid-copy fails the copy instead of mixing versions; each part is retried on
+// its own and the upload is aborted when a part finally fails
+func (c *Client) copyMultipart(ctx context.Context, req *CopyFileRequest, src *FileInfo) error {
+	if err := c.requireFeature(FeatureMultipart); err != nil {
+		return err
+	}
+	ranges := c.copyPartRanges(src.Size)
+
+	// parts only carry data, the attributes are fixed when the upload starts
+	initiate := map[string]interface{}{
+		"contentType": src.ContentType,
+		"metadata":    src.Metadata,
+	}
+	if req.MetadataDirective == MetadataDirectiveReplace {
+		initiate["metadata"] = req.Metadata
+		if req.ContentType != "" {
+			initiate["contentType"] = req.ContentType
+		}
+	}
+	storageClass := req.StorageClass
+	if storageClass == "" {
+		storageClass = src.StorageClass
+	}
+	if storageClass != "" {
+		initiate["storageClass"] = storageClass
+	}
+	uploadPath := objectPath(req.DestBucket, req.DestKey) + "/uploads"
+	var upload struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := c.call(ctx, "POST", uploadPath, initiate, &upload); err != nil {
+		return fmt.Errorf("failed to start multipart copy: %w", err)
+	}
+	uploadPath += "/" + upload.UploadID
+
+	parts, err := c.copyParts(ctx, req, src, uploadPath, ranges)
+	if err == nil {
+		err = c.call(ctx, "POST", uploadPath+"/complete", map[string]interface{}{"parts": parts}, nil)
+		if err != nil {
+			err = fmt.Errorf("failed to complete multipart copy: %w", err)
+		}
+	}
+	if err != nil {
+		// the part copies already stored are dropped with the upload; the copy
+		// error is what the caller needs, an abort failure is only joined to it
+		if abortErr := c.call(context.WithoutCancel(ctx), "DELETE", uploadPath, nil, nil); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to abort multipart copy: %w", abortErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// copyParts run the part copies, returns the parts ordered by number
+func (c *Client) copyParts(ctx context.Context, req *CopyFileRequest, src *FileInfo, uploadPath string, ranges []copyPartRange) ([]CopyPart, error) {
+	concurrency := c.config.CopyConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultCopyConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	parts := make([]CopyPart, 0, len(ranges))
+	sem := make(chan struct{}, concurrency)
+	for _, r := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r copyPartRange) {
+			defer func() { <-sem; wg.Done() }()
+			if ctx.Err() != nil {
+				return
+			}
+			body := map[string]interface{}{
+				"srcBucket": req.SrcBucket,
+				"srcKey":    req.SrcKey,
+				"range":     fmt.Sprintf("bytes=%d-%d", r.start, r.end),
+			}
+			if src.ETag != "" {
+				body["ifMatch"] = src.ETag
+			}
+			var part struct {
+				ETag string `json:"etag"`
+			}
+			err := c.call(ctx, "PUT", fmt.Sprintf("%s/parts/%d/copy", uploadPath, r.number), body, &part)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to copy part %d: %w", r.number, err)
+					cancel()
+				}
+				return
+			}
+			parts = append(parts, CopyPart{PartNumber: r.number, ETag: part.ETag})
+		}(r)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+// copyPartRanges split size bytes into part ranges, growing the part size so
+// the part count stays within the server limit
+func (c *Client) copyPartRanges(size int64) []copyPartRange {
+	partSize := c.config.CopyPartSize
+	if partSize <= 0 {
+		partSize = DefaultCopyPartSize
+	}
+	maxParts := int64(maxCopyParts)
+	if !c.config.SkipCapabilityCheck {
+		if info, err := c.Capabilities(); err == nil && info != nil {
+			if info.Limits.MaxParts > 0 {
+				maxParts = int64(info.Limits.MaxParts)
+			}
+			if info.Limits.MinPartSize > 0 && partSize < info.Limits.MinPartSize {
+				partSize = info.Limits.MinPartSize
+			}
+		}
+	}
+	if minSize := (size + maxParts - 1) / maxParts; partSize < minSize {
+		partSize = minSize
+	}
+	var ranges []copyPartRange
+	for start := int64(0); start < size; start += partSize {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		ranges = append(ranges, copyPartRange{number: len(ranges) + 1, start: start, end: end})
+	}
+	return ranges
+}
+
+// isTooLarge err reports a source above the single request copy limit
+func isTooLarge(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestEntityTooLarge
+}
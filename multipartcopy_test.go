@@ -0,0 +1,129 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartCopyServer server whose copy endpoint rejects sources above limit
+type multipartCopyServer struct {
+	limit      int64
+	failPart   int32 // part number answered with 500 on every attempt
+	mu         sync.Mutex
+	ranges     map[string]string
+	initiate   map[string]interface{}
+	completed  []CopyPart
+	aborted    bool
+	singleCopy int32
+}
+
+func (s *multipartCopyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const upload = "/api/public/files/dst/big.bin/uploads"
+	switch {
+	case r.URL.Path == "/api/public/info":
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"features": []string{FeatureMultipart},
+			"limits":   map[string]interface{}{"maxParts": 3},
+		}})
+	case r.URL.Path == "/api/public/files/src/big.bin/info":
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"key": "big.bin", "size": 1000, "etag": "abc", "contentType": "application/zip", "metadata": map[string]string{"owner": "ling"},
+		}})
+	case r.URL.Path == "/api/public/files/src/big.bin/copy":
+		atomic.AddInt32(&s.singleCopy, 1)
+		if s.limit > 0 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(`{"success":false,"message":"source too large"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true}`))
+	case r.URL.Path == upload && r.Method == "POST":
+		s.mu.Lock()
+		json.NewDecoder(r.Body).Decode(&s.initiate)
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true,"data":{"uploadId":"u1"}}`))
+	case r.URL.Path == upload+"/u1" && r.Method == "DELETE":
+		s.mu.Lock()
+		s.aborted = true
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true}`))
+	case r.URL.Path == upload+"/u1/complete":
+		var body struct {
+			Parts []CopyPart `json:"parts"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.completed = body.Parts
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true}`))
+	case strings.HasPrefix(r.URL.Path, upload+"/u1/parts/"):
+		number := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, upload+"/u1/parts/"), "/copy")
+		if s.failPart > 0 && number == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["ifMatch"] != "abc" || body["srcBucket"] != "src" || body["srcKey"] != "big.bin" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		s.mu.Lock()
+		s.ranges[number] = body["range"]
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"etag": "etag-" + number}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCopyFileMultipart(t *testing.T) {
+	srv := &multipartCopyServer{ranges: map[string]string{}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, MultipartCopyThreshold: 500, CopyPartSize: 100})
+
+	require.NoError(t, client.CopyFile(&CopyFileRequest{SrcBucket: "src", SrcKey: "big.bin", DestBucket: "dst", DestKey: "big.bin"}))
+	// 服务端最多 3 个分片, 分片大小被放大
+	assert.Equal(t, map[string]string{"1": "bytes=0-333", "2": "bytes=334-667", "3": "bytes=668-999"}, srv.ranges)
+	assert.Equal(t, []CopyPart{{1, "etag-1"}, {2, "etag-2"}, {3, "etag-3"}}, srv.completed)
+	assert.Equal(t, "application/zip", srv.initiate["contentType"])
+	assert.Equal(t, map[string]interface{}{"owner": "ling"}, srv.initiate["metadata"])
+	assert.Zero(t, srv.singleCopy)
+	assert.False(t, srv.aborted)
+}
+
+func TestCopyFileMultipartFallback(t *testing.T) {
+	srv := &multipartCopyServer{limit: 500, ranges: map[string]string{}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, CopyPartSize: 400})
+
+	require.NoError(t, client.CopyFile(&CopyFileRequest{
+		SrcBucket: "src", SrcKey: "big.bin", DestBucket: "dst", DestKey: "big.bin",
+		MetadataDirective: MetadataDirectiveReplace,
+		Metadata:          map[string]string{"owner": "copy"},
+	}))
+	assert.EqualValues(t, 1, srv.singleCopy)
+	assert.Len(t, srv.completed, 3)
+	assert.Equal(t, map[string]interface{}{"owner": "copy"}, srv.initiate["metadata"])
+
+	// 分片失败后中止分片上传
+	srv = &multipartCopyServer{limit: 500, failPart: 2, ranges: map[string]string{}}
+	server2 := httptest.NewServer(srv)
+	defer server2.Close()
+	client = NewClient(&Config{BaseURL: server2.URL, RetryCount: -1, CopyPartSize: 400, CopyConcurrency: 1})
+	err := client.CopyFile(&CopyFileRequest{SrcBucket: "src", SrcKey: "big.bin", DestBucket: "dst", DestKey: "big.bin"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "part 2")
+	assert.True(t, srv.aborted)
+	assert.Nil(t, srv.completed)
+}
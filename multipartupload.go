@@ -0,0 +1,122 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultUploadPartSize bytes sent by one part of UploadMultipart
+const DefaultUploadPartSize int64 = 64 << 20
+
+// UploadMultipartRequest stream a reader into an object as a multipart upload
+type UploadMultipartRequest struct {
+	Reader      io.Reader
+	Bucket      string
+	Key         string
+	ContentType string
+	Metadata    map[string]string
+	// Size total bytes when known, grows the part size so the part count
+	// stays within the server limit
+	Size int64
+	// PartSize bytes per part, default 64MB, raised to the server minimum part size
+	PartSize int64
+}
+
+// UploadMultipart upload a reader of any size as a multipart upload. Parts
+// are read and sent one after another, so only one part is held in memory;
+// each part is retried on its own and the upload is aborted when a part
+// finally fails. Use it for objects too large to buffer for UploadFromReader
+func (c *Client) UploadMultipart(req *UploadMultipartRequest) (_ *UploadResult, err error) {
+	ctx, op := c.startOperation("UploadMultipart", req.Bucket, req.Key)
+	defer func() { c.endOperation(op, err) }()
+	if req.Reader == nil {
+		return nil, fmt.Errorf("reader is required")
+	}
+	if err := c.requireFeature(FeatureMultipart); err != nil {
+		return nil, err
+	}
+	partSize := c.uploadPartSize(req.PartSize, req.Size)
+
+	initiate := map[string]interface{}{}
+	if req.ContentType != "" {
+		initiate["contentType"] = req.ContentType
+	}
+	if len(req.Metadata) > 0 {
+		initiate["metadata"] = req.Metadata
+	}
+	uploadPath := objectPath(req.Bucket, req.Key) + "/uploads"
+	var upload struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := c.call(ctx, "POST", uploadPath, initiate, &upload); err != nil {
+		return nil, fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+	uploadPath += "/" + upload.UploadID
+
+	var completed struct {
+		ETag string `json:"etag"`
+	}
+	size, err := c.uploadParts(ctx, uploadPath, req.Reader, partSize, &completed)
+	if err != nil {
+		// the parts already stored are dropped with the upload; the upload
+		// error is what the caller needs, an abort failure is only joined to it
+		if abortErr := c.call(context.WithoutCancel(ctx), "DELETE", uploadPath, nil, nil); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to abort multipart upload: %w", abortErr))
+		}
+		return nil, err
+	}
+	c.metadata.invalidate(req.Bucket, req.Key)
+	return &UploadResult{Key: req.Key, Bucket: req.Bucket, Size: size, OriginalSize: size, ETag: completed.ETag}, nil
+}
+
+// uploadParts send reader in parts of partSize and complete the upload into
+// completed, returns the bytes sent. An empty reader is sent as one empty part
+func (c *Client) uploadParts(ctx context.Context, uploadPath string, reader io.Reader, partSize int64, completed interface{}) (int64, error) {
+	buf := make([]byte, partSize)
+	var parts []CopyPart
+	var size int64
+	for {
+		n, readErr := io.ReadFull(reader, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("failed to read part %d: %w", len(parts)+1, readErr)
+		}
+		if n > 0 || len(parts) == 0 {
+			number := len(parts) + 1
+			etag, err := c.uploadPart(ctx, uploadPath, number, buf[:n])
+			if err != nil {
+				return 0, fmt.Errorf("failed to upload part %d: %w", number, err)
+			}
+			parts = append(parts, CopyPart{PartNumber: number, ETag: etag})
+			size += int64(n)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err := c.call(ctx, "POST", uploadPath+"/complete", map[string]interface{}{"parts": parts}, completed); err != nil {
+		return 0, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return size, nil
+}
+
+// uploadPartSize part size of a multipart upload of size bytes, size 0 when unknown
+func (c *Client) uploadPartSize(partSize, size int64) int64 {
+	if partSize <= 0 {
+		partSize = DefaultUploadPartSize
+	}
+	maxParts := int64(maxCopyParts)
+	if caps := c.capabilities.cached(); caps != nil {
+		if caps.Limits.MaxParts > 0 {
+			maxParts = int64(caps.Limits.MaxParts)
+		}
+		if caps.Limits.MinPartSize > 0 && partSize < caps.Limits.MinPartSize {
+			partSize = caps.Limits.MinPartSize
+		}
+	}
+	if minSize := (size + maxParts - 1) / maxParts; partSize < minSize {
+		partSize = minSize
+	}
+	return partSize
+}
@@ -0,0 +1,123 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartUploadServer server storing the data of uploaded parts
+type multipartUploadServer struct {
+	failPart  string // part number answered with 500
+	mu        sync.Mutex
+	parts     map[string]string
+	initiate  map[string]interface{}
+	completed []CopyPart
+	aborted   bool
+}
+
+func (s *multipartUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const upload = "/api/public/files/dst/big.bin/uploads"
+	switch {
+	case r.URL.Path == "/api/public/info":
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"features": []string{FeatureMultipart},
+			"limits":   map[string]interface{}{"maxParts": 3, "minPartSize": 4},
+		}})
+	case r.URL.Path == upload && r.Method == "POST":
+		s.mu.Lock()
+		json.NewDecoder(r.Body).Decode(&s.initiate)
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true,"data":{"uploadId":"u1"}}`))
+	case r.URL.Path == upload+"/u1" && r.Method == "DELETE":
+		s.mu.Lock()
+		s.aborted = true
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true}`))
+	case r.URL.Path == upload+"/u1/complete":
+		var body struct {
+			Parts []CopyPart `json:"parts"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.completed = body.Parts
+		s.mu.Unlock()
+		w.Write([]byte(`{"success":true,"data":{"etag":"final"}}`))
+	case strings.HasPrefix(r.URL.Path, upload+"/u1/parts/") && r.Method == "PUT":
+		number := strings.TrimPrefix(r.URL.Path, upload+"/u1/parts/")
+		if number == s.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.parts[number] = string(data)
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"etag": "etag-" + number}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestUploadMultipart(t *testing.T) {
+	srv := &multipartUploadServer{parts: map[string]string{}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	// 分片大小被提升到服务端最小值
+	result, err := client.UploadMultipart(&UploadMultipartRequest{
+		Reader:      strings.NewReader("0123456789"),
+		Bucket:      "dst",
+		Key:         "big.bin",
+		ContentType: "application/octet-stream",
+		PartSize:    1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), result.Size)
+	assert.Equal(t, "final", result.ETag)
+	assert.Equal(t, map[string]string{"1": "0123", "2": "4567", "3": "89"}, srv.parts)
+	assert.Equal(t, []CopyPart{{1, "etag-1"}, {2, "etag-2"}, {3, "etag-3"}}, srv.completed)
+	assert.Equal(t, "application/octet-stream", srv.initiate["contentType"])
+	assert.False(t, srv.aborted)
+
+	// 已知大小时分片数不超过服务端上限
+	srv.parts = map[string]string{}
+	_, err = client.UploadMultipart(&UploadMultipartRequest{Reader: strings.NewReader("0123456789ab"), Bucket: "dst", Key: "big.bin", Size: 12, PartSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"1": "0123", "2": "4567", "3": "89ab"}, srv.parts)
+}
+
+func TestUploadMultipartAborts(t *testing.T) {
+	srv := &multipartUploadServer{parts: map[string]string{}, failPart: "2"}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	_, err := client.UploadMultipart(&UploadMultipartRequest{Reader: strings.NewReader("0123456789"), Bucket: "dst", Key: "big.bin", PartSize: 4})
+	assert.ErrorContains(t, err, "failed to upload part 2")
+	assert.True(t, srv.aborted)
+	assert.Nil(t, srv.completed)
+
+	// 读取源失败同样中止
+	srv.aborted = false
+	srv.failPart = ""
+	_, err = client.UploadMultipart(&UploadMultipartRequest{Reader: io.MultiReader(strings.NewReader("0123"), errReader{}), Bucket: "dst", Key: "big.bin", PartSize: 4})
+	assert.ErrorContains(t, err, "failed to read part 2")
+	assert.True(t, srv.aborted)
+
+	_, err = client.UploadMultipart(&UploadMultipartRequest{Bucket: "dst", Key: "big.bin"})
+	assert.ErrorContains(t, err, "reader is required")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("source unavailable") }
@@ -0,0 +1,241 @@
+package lingstorage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultTerminalWidth width used when the terminal width is unknown
+	DefaultTerminalWidth = 80
+	// DefaultProgressLogInterval period of progress lines when not attached to a terminal
+	DefaultProgressLogInterval = 5 * time.Second
+
+	progressRedrawInterval = 100 * time.Millisecond
+)
+
+// MultiProgress renders one bar per in-flight transfer plus a total bar. On a
+// terminal the bars are redrawn in place, otherwise progress is written as
+// periodic log lines
+type MultiProgress struct {
+	mu          sync.Mutex
+	out         io.Writer
+	tty         bool
+	width       int
+	logInterval time.Duration
+
+	active     []*ProgressTracker
+	files      int
+	doneFiles  int
+	totalBytes int64
+	doneBytes  int64 // bytes of finished transfers
+	lines      int   // lines drawn by the last redraw
+	lastDraw   time.Time
+}
+
+// ProgressTracker progress of one transfer of a MultiProgress
+type ProgressTracker struct {
+	m        *MultiProgress
+	name     string
+	uploaded int64
+	total    int64
+}
+
+// NewMultiProgress renderer writing to w, terminal output is used when w is a
+// character device. The width comes from $COLUMNS, default 80
+func NewMultiProgress(w io.Writer) *MultiProgress {
+	m := &MultiProgress{out: w, width: DefaultTerminalWidth, logInterval: DefaultProgressLogInterval}
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			m.tty = true
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		m.width = columns
+	}
+	return m
+}
+
+// SetTerminal force in place rendering with the given width, 0 keeps the current width
+func (m *MultiProgress) SetTerminal(width int) *MultiProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tty = true
+	if width > 0 {
+		m.width = width
+	}
+	return m
+}
+
+// SetLogInterval period of progress lines when not attached to a terminal
+func (m *MultiProgress) SetLogInterval(interval time.Duration) *MultiProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logInterval = interval
+	return m
+}
+
+// SetTotal number of files and bytes of the whole job
+func (m *MultiProgress) SetTotal(files int, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files = files
+	m.totalBytes = bytes
+}
+
+// Track start tracking a transfer of total bytes
+func (m *MultiProgress) Track(name string, total int64) *ProgressTracker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &ProgressTracker{m: m, name: name, total: total}
+	m.active = append(m.active, t)
+	m.render(false)
+	return t
+}
+
+// OnProgress progress callback of the transfer, fits UploadRequest.OnProgress
+func (t *ProgressTracker) OnProgress(uploaded, total int64) {
+	m := t.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.uploaded = uploaded
+	if total > 0 {
+		t.total = total
+	}
+	m.render(false)
+}
+
+// Done finish the transfer, err nil on success
+func (t *ProgressTracker) Done(err error) {
+	m := t.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, active := range m.active {
+		if active == t {
+			m.active = append(m.active[:i], m.active[i+1:]...)
+			break
+		}
+	}
+	m.doneFiles++
+	m.doneBytes += t.total
+	if !m.tty {
+		status := "uploaded"
+		if err != nil {
+			status = "failed"
+		}
+		fmt.Fprintf(m.out, "%s %s (%s) %s\n", status, t.name, FormatBytes(t.total), m.summary())
+		return
+	}
+	m.render(true)
+}
+
+// Finish draw the final state
+func (m *MultiProgress) Finish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tty {
+		m.render(true)
+		return
+	}
+	fmt.Fprintf(m.out, "done %s\n", m.summary())
+}
+
+// transferred bytes of finished and active transfers
+func (m *MultiProgress) transferred() int64 {
+	n := m.doneBytes
+	for _, t := range m.active {
+		n += t.uploaded
+	}
+	return n
+}
+
+func (m *MultiProgress) summary() string {
+	var percentage float64
+	if m.totalBytes > 0 {
+		percentage = float64(m.transferred()) / float64(m.totalBytes) * 100
+	}
+	return fmt.Sprintf("[%d/%d files, %s/%s, %.1f%%]",
+		m.doneFiles, m.files, FormatBytes(m.transferred()), FormatBytes(m.totalBytes), percentage)
+}
+
+// render redraw the bars, throttled unless force
+func (m *MultiProgress) render(force bool) {
+	now := time.Now()
+	if !m.tty {
+		if m.logInterval > 0 && now.Sub(m.lastDraw) >= m.logInterval {
+			if !m.lastDraw.IsZero() {
+				fmt.Fprintf(m.out, "progress %s\n", m.summary())
+			}
+			m.lastDraw = now
+		}
+		return
+	}
+	if !force && now.Sub(m.lastDraw) < progressRedrawInterval {
+		return
+	}
+	m.lastDraw = now
+
+	var sb strings.Builder
+	if m.lines > 0 {
+		// back to the first line of the previous frame
+		fmt.Fprintf(&sb, "\x1b[%dA", m.lines)
+	}
+	lines := 0
+	for _, t := range m.active {
+		sb.WriteString("\x1b[2K")
+		sb.WriteString(m.bar(t.name, t.uploaded, t.total, ""))
+		sb.WriteString("\n")
+		lines++
+	}
+	sb.WriteString("\x1b[2K")
+	sb.WriteString(m.bar("Total", m.transferred(), m.totalBytes, fmt.Sprintf(" %d/%d", m.doneFiles, m.files)))
+	sb.WriteString("\n")
+	lines++
+	// clear lines left over from a taller previous frame
+	for i := lines; i < m.lines; i++ {
+		sb.WriteString("\x1b[2K\n")
+	}
+	if m.lines > lines {
+		fmt.Fprintf(&sb, "\x1b[%dA", m.lines-lines)
+	}
+	m.lines = lines
+	io.WriteString(m.out, sb.String())
+}
+
+// bar one line fitting the terminal width: name, bar, percentage and sizes
+func (m *MultiProgress) bar(name string, done, total int64, suffix string) string {
+	var percentage float64
+	if total > 0 {
+		percentage = float64(done) / float64(total) * 100
+		if percentage > 100 {
+			percentage = 100
+		}
+	}
+	stats := fmt.Sprintf(" %5.1f%% %s/%s%s", percentage, FormatBytes(done), FormatBytes(total), suffix)
+	nameWidth := m.width / 3
+	name = truncateLeft(name, nameWidth)
+	// one column spare, writing the last column wraps on some terminals
+	barWidth := m.width - nameWidth - utf8.RuneCountInString(stats) - 4
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	return fmt.Sprintf("%-*s %s%s", nameWidth, name, CreateProgressBar(int(percentage), barWidth), stats)
+}
+
+// truncateLeft keep the end of s, usually the file name of a path
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[len(runes)-width:])
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
@@ -0,0 +1,88 @@
+package lingstorage_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiProgressTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	m := lingstorage.NewMultiProgress(&buf).SetTerminal(60)
+	m.SetTotal(2, 300)
+	a := m.Track("dir/a.txt", 100)
+	b := m.Track("a/very/long/path/that/does/not/fit/in/the/name/column/b.txt", 200)
+	a.OnProgress(50, 100)
+	a.Done(nil)
+	b.Done(nil)
+	m.Finish()
+
+	out := buf.String()
+	assert.Contains(t, out, "\x1b[2K")
+	assert.Contains(t, out, "…")
+	// 每一行都不超过终端宽度
+	for _, line := range strings.Split(out, "\n") {
+		if i := strings.LastIndex(line, "\x1b[2K"); i >= 0 {
+			line = line[i+len("\x1b[2K"):]
+		}
+		assert.Less(t, utf8.RuneCountInString(line), 60)
+	}
+	frames := strings.Split(out, "\x1b[")
+	last := frames[len(frames)-1]
+	assert.Contains(t, last, "Total")
+	assert.Contains(t, last, "2/2")
+	assert.Contains(t, last, "100.0%")
+}
+
+func TestMultiProgressLogLines(t *testing.T) {
+	var buf bytes.Buffer
+	m := lingstorage.NewMultiProgress(&buf)
+	m.SetTotal(2, 30)
+	m.Track("a.txt", 10).Done(nil)
+	m.Track("b.txt", 20).Done(errors.New("boom"))
+	m.Finish()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "uploaded a.txt (10 B) [1/2 files, 10 B/30 B, 33.3%]", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "failed b.txt"))
+	assert.Equal(t, "done [2/2 files, 30 B/30 B, 100.0%]", lines[2])
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestBatchUploadProgress(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	server.CreateBucket("batch")
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", 1000)), 0644))
+		files = append(files, path)
+	}
+
+	var buf bytes.Buffer
+	var fileCalls int
+	result, err := client.BatchUpload(&lingstorage.BatchUploadRequest{
+		Files:          files,
+		Bucket:         "batch",
+		Progress:       lingstorage.NewMultiProgress(&buf),
+		OnFileProgress: func(uploaded, total int64) { fileCalls++ },
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Success, 2)
+	assert.Greater(t, fileCalls, 0)
+	assert.Contains(t, buf.String(), "done [2/2 files, 2.0 KB/2.0 KB, 100.0%]")
+}
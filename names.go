@@ -0,0 +1,157 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	MinBucketNameLength = 3
+	MaxBucketNameLength = 63
+	MaxObjectKeyLength  = 1024 // bytes of the UTF-8 encoded key
+)
+
+var (
+	// ErrInvalidBucketName bucket name breaks the server naming rules
+	ErrInvalidBucketName = errors.New("lingstorage: invalid bucket name")
+	// ErrInvalidObjectKey object key breaks the server naming rules
+	ErrInvalidObjectKey = errors.New("lingstorage: invalid object key")
+)
+
+// reservedBucketPrefixes prefixes the server keeps for itself
+var reservedBucketPrefixes = []string{"xn--", "lingstorage-"}
+
+// ValidateBucketName check name against the server rules: 3 to 63 lower case
+// letters, digits, hyphens and dots, starting and ending with a letter or
+// digit, no consecutive dots, not an IP address and no reserved prefix
+func ValidateBucketName(name string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidBucketName, name, reason)
+	}
+	if len(name) < MinBucketNameLength || len(name) > MaxBucketNameLength {
+		return invalid(fmt.Sprintf("must be %d to %d characters long", MinBucketNameLength, MaxBucketNameLength))
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') && ch != '-' && ch != '.' {
+			return invalid(fmt.Sprintf("character %q is not allowed, use lower case letters, digits, '-' and '.'", rune(ch)))
+		}
+	}
+	if !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return invalid("must start and end with a letter or digit")
+	}
+	if strings.Contains(name, "..") {
+		return invalid("must not contain consecutive dots")
+	}
+	if net.ParseIP(name) != nil {
+		return invalid("must not be formatted as an IP address")
+	}
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return invalid(fmt.Sprintf("prefix %q is reserved", prefix))
+		}
+	}
+	return nil
+}
+
+// ValidateObjectKey check key against the server rules: 1 to 1024 bytes of
+// valid UTF-8 without control characters, not starting with '/' and without
+// "." or ".." path segments. '#', '?', '%' and spaces are allowed, request
+// paths escape them
+func ValidateObjectKey(key string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidObjectKey, key, reason)
+	}
+	if key == "" {
+		return invalid("must not be empty")
+	}
+	if len(key) > MaxObjectKeyLength {
+		return invalid(fmt.Sprintf("must be at most %d bytes long", MaxObjectKeyLength))
+	}
+	if !utf8.ValidString(key) {
+		return invalid("must be valid UTF-8")
+	}
+	for _, r := range key {
+		if r < 0x20 || r == 0x7f {
+			return invalid(fmt.Sprintf("control character %U is not allowed", r))
+		}
+	}
+	if strings.HasPrefix(key, "/") {
+		return invalid("must not start with '/'")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return invalid("must not contain '.' or '..' path segments")
+		}
+	}
+	return nil
+}
+
+// SanitizeKey make a user supplied filename or key safe to store: normalize
+// it to Unicode NFC, drop invalid UTF-8 and control characters, treat '\' as
+// a separator and drop empty, "." and ".." segments so the key cannot climb
+// out of the prefix it is joined to. "../../etc/passwd" becomes "etc/passwd"
+func SanitizeKey(key string) string {
+	key = norm.NFC.String(strings.ToValidUTF8(key, ""))
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\\':
+			return '/'
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, key)
+	segments := strings.Split(key, "/")
+	kept := segments[:0]
+	for _, segment := range segments {
+		if segment != "" && segment != "." && segment != ".." {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, "/")
+}
+
+// sanitizeUpload apply SanitizeKey to the filename and key of an upload when
+// Config.SanitizeKeys is set, req is copied rather than modified
+func (c *Client) sanitizeUpload(filename string, req *UploadRequest) (string, *UploadRequest, error) {
+	if !c.config.SanitizeKeys {
+		return filename, req, nil
+	}
+	sanitized := *req
+	if req.Key != "" {
+		if sanitized.Key = SanitizeKey(req.Key); sanitized.Key == "" {
+			return "", nil, fmt.Errorf("%w %q: nothing left after sanitizing", ErrInvalidObjectKey, req.Key)
+		}
+	}
+	if filename != "" {
+		if filename = SanitizeKey(filename); filename == "" {
+			return "", nil, fmt.Errorf("%w: filename has nothing left after sanitizing", ErrInvalidObjectKey)
+		}
+	}
+	return filename, &sanitized, nil
+}
+
+func isAlphanumeric(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9')
+}
+
+// validateNames fail fast on names the server would reject
+func (c *Client) validateNames(op *operation) error {
+	if c.config.SkipNameValidation || op == nil || op.bucket == "" {
+		return nil
+	}
+	if err := ValidateBucketName(op.bucket); err != nil {
+		return err
+	}
+	if op.key != "" {
+		return ValidateObjectKey(op.key)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package lingstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBucketName(t *testing.T) {
+	for _, name := range []string{"abc", "my-bucket", "logs.2024", "a1b", strings.Repeat("a", 63)} {
+		assert.NoError(t, ValidateBucketName(name), name)
+	}
+	cases := map[string]string{
+		"ab":                    "3 to 63",
+		strings.Repeat("a", 64): "3 to 63",
+		"MyBucket":              "not allowed",
+		"my_bucket":             "not allowed",
+		"-bucket":               "start and end",
+		"bucket.":               "start and end",
+		"my..bucket":            "consecutive dots",
+		"192.168.1.1":           "IP address",
+		"xn--bucket":            "reserved",
+		"lingstorage-internal":  "reserved",
+	}
+	for name, reason := range cases {
+		err := ValidateBucketName(name)
+		assert.ErrorIs(t, err, ErrInvalidBucketName, name)
+		assert.ErrorContains(t, err, reason, name)
+	}
+}
+
+func TestValidateObjectKey(t *testing.T) {
+	for _, key := range []string{"a", "docs/readme.md", "dir/", "照片/2024.jpg", "a..b/c", strings.Repeat("k", 1024)} {
+		assert.NoError(t, ValidateObjectKey(key), key)
+	}
+	// 请求路径会转义这些字符, 因此允许出现在键中
+	for _, key := range []string{"photos/a#b.jpg", "photos/a?x=1", "100%.txt", "my photos/a b.jpg"} {
+		assert.NoError(t, ValidateObjectKey(key), key)
+	}
+	cases := map[string]string{
+		"":                        "empty",
+		strings.Repeat("k", 1025): "1024 bytes",
+		"bad\xffkey":              "UTF-8",
+		"line\nbreak":             "control character",
+		"/absolute":               "start with '/'",
+		"a/../b":                  "path segments",
+		"./a":                     "path segments",
+	}
+	for key, reason := range cases {
+		err := ValidateObjectKey(key)
+		assert.ErrorIs(t, err, ErrInvalidObjectKey, key)
+		assert.ErrorContains(t, err, reason, key)
+	}
+}
+
+func TestClientValidatesNames(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+	assert.ErrorIs(t, client.DeleteFile("Bad_Bucket", "key"), ErrInvalidBucketName)
+	assert.ErrorIs(t, client.DeleteFile("bucket", "../etc/passwd"), ErrInvalidObjectKey)
+	assert.ErrorIs(t, client.CreateBucket(&CreateBucketRequest{BucketName: "xn--x"}), ErrInvalidBucketName)
+	assert.Equal(t, 0, requests)
+
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	assert.Equal(t, 1, requests)
+
+	// 关闭校验后按原样发送
+	legacy := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, SkipNameValidation: true})
+	require.NoError(t, legacy.DeleteFile("Bad_Bucket", "key"))
+	assert.Equal(t, 2, requests)
+}
+
+func TestSanitizeKey(t *testing.T) {
+	cases := map[string]string{
+		"photos/cat.jpg":       "photos/cat.jpg",
+		"../../etc/passwd":     "etc/passwd",
+		"a/./b//c/../d":        "a/b/c/d",
+		"..\\..\\windows\\ini": "windows/ini",
+		"/abs/path":            "abs/path",
+		"bad\x00name\r\n.txt":  "badname.txt",
+		"cafe\u0301.txt":       "caf\u00e9.txt", // NFD 转为 NFC
+		"inv\xffalid":          "invalid",
+		"../..":                "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, SanitizeKey(in), "%q", in)
+	}
+}
+
+func TestUploadSanitizeKeys(t *testing.T) {
+	var keys, filenames []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		keys = append(keys, r.FormValue("key"))
+		filenames = append(filenames, header.Filename)
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, SanitizeKeys: true})
+	req := &UploadBytesRequest{Bucket: "bucket", Key: "tenant-1/../tenant-2/a.txt", Filename: "..\\a\x07.txt", Data: []byte("x")}
+	_, err := client.UploadBytes(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant-1/tenant-2/a.txt"}, keys)
+	assert.Equal(t, []string{"a.txt"}, filenames)
+	assert.Equal(t, "tenant-1/../tenant-2/a.txt", req.Key)
+
+	_, err = client.UploadBytes(&UploadBytesRequest{Bucket: "bucket", Key: "../..", Filename: "a.txt", Data: []byte("x")})
+	assert.ErrorIs(t, err, ErrInvalidObjectKey)
+	assert.Len(t, keys, 1)
+}
@@ -0,0 +1,29 @@
+package lingstorage
+
+// TextExtraction result of ExtractText. Short results are returned inline in
+// Text, long ones are stored as an object referenced by ResultKey
+type TextExtraction struct {
+	JobID      string  `json:"jobId,omitempty"` // set when the server extracted asynchronously
+	Text       string  `json:"text,omitempty"`
+	ResultKey  string  `json:"resultKey,omitempty"`
+	Language   string  `json:"language"` // detected or requested language
+	Pages      int     `json:"pages"`
+	Confidence float64 `json:"confidence"` // mean confidence 0-1
+}
+
+// ExtractText run OCR on an image or pdf. language is a hint like "en" or
+// "zh", empty for auto detection
+func (c *Client) ExtractText(bucket, key, language string) (_ *TextExtraction, err error) {
+	ctx, op := c.startOperation("ExtractText", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	body := map[string]string{"language": language}
+	result := &TextExtraction{}
+	jobID, err := c.callJob(ctx, "POST", objectPath(bucket, key)+"/ocr", body, result)
+	if err != nil {
+		return nil, err
+	}
+	if jobID != "" {
+		result.JobID = jobID
+	}
+	return result, nil
+}
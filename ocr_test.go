@@ -0,0 +1,48 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/docs/receipt.jpg/ocr":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "zh", body["language"])
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"text": "合计 42.00", "language": "zh", "pages": 1, "confidence": 0.93,
+			}})
+		case "/api/public/files/docs/book.pdf/ocr":
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"jobId": "job-1"}})
+		case "/api/public/jobs/job-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"id": "job-1", "status": "succeeded",
+				"result": map[string]interface{}{"resultKey": "book.pdf.txt", "language": "en", "pages": 320},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.ExtractText("docs", "receipt.jpg", "zh")
+	require.NoError(t, err)
+	assert.Equal(t, "合计 42.00", result.Text)
+	assert.Equal(t, 0.93, result.Confidence)
+
+	result, err = client.ExtractText("docs", "book.pdf", "")
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", result.JobID)
+	assert.Equal(t, "book.pdf.txt", result.ResultKey)
+	assert.Equal(t, 320, result.Pages)
+}
@@ -0,0 +1,109 @@
+package lingstorage
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operation state of one public client call, shared by tracing and metrics.
+// Calls of one operation may run concurrently (e.g. part copies), mu guards
+// the fields they record
+type operation struct {
+	name   string
+	bucket string
+	key    string
+	start  time.Time
+	span   trace.Span
+
+	mu         sync.Mutex
+	attempts   int
+	statusCode int
+	uploaded   int64
+	downloaded int64
+	timing     *Timing
+}
+
+type operationKey struct{}
+
+// startOperation start a client operation, the returned context carries the operation
+func (c *Client) startOperation(name, bucket, key string) (context.Context, *operation) {
+	ctx, span := c.startSpan(c.context(), name, bucket, key)
+	op := &operation{
+		name:   name,
+		bucket: bucket,
+		key:    key,
+		start:  time.Now(),
+		span:   span,
+	}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+// endOperation finish the operation and report it
+func (c *Client) endOperation(op *operation, err error) {
+	duration := time.Since(op.start)
+	op.mu.Lock()
+	c.stats.record(op, err, duration)
+	statusCode := op.statusCode
+	op.mu.Unlock()
+	if c.config.Metrics != nil {
+		c.config.Metrics.ObserveRequest(op.name, statusCode, err, duration)
+	}
+	endSpan(op.span, err)
+}
+
+// operationFromContext operation started by startOperation, nil if none
+func operationFromContext(ctx context.Context) *operation {
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	return op
+}
+
+// recordAttempt record one http attempt of the current operation
+func (c *Client) recordAttempt(req *http.Request, attempt int, resp *http.Response) {
+	if resp != nil {
+		c.stats.recordProtocol(resp.Proto)
+	}
+	op := operationFromContext(req.Context())
+	if op == nil {
+		return
+	}
+	op.mu.Lock()
+	op.attempts = attempt
+	if resp != nil {
+		op.statusCode = resp.StatusCode
+	}
+	op.mu.Unlock()
+	if attempt > 1 && c.config.Metrics != nil {
+		c.config.Metrics.ObserveRetry(op.name, attempt)
+	}
+	if c.tracer != nil {
+		op.span.SetAttributes(attrAttempts.Int(attempt))
+		if resp != nil {
+			op.span.SetAttributes(attrStatusCode.Int(resp.StatusCode))
+		}
+	}
+}
+
+// recordBytes record transferred bytes of the current operation
+func (c *Client) recordBytes(ctx context.Context, direction string, n int64) {
+	op := operationFromContext(ctx)
+	if op == nil || n < 0 {
+		return
+	}
+	op.mu.Lock()
+	if direction == DirectionUpload {
+		op.uploaded += n
+	} else {
+		op.downloaded += n
+	}
+	op.mu.Unlock()
+	if c.config.Metrics != nil {
+		c.config.Metrics.ObserveBytes(op.name, direction, n)
+	}
+	if c.tracer != nil {
+		op.span.SetAttributes(attrBytes.Int64(n))
+	}
+}
@@ -0,0 +1,122 @@
+// Package prometheus exports lingstorage client metrics to Prometheus
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Options collector options
+type Options struct {
+	Namespace string    // metric namespace, default lingstorage
+	Buckets   []float64 // latency histogram buckets, default prometheus.DefBuckets
+}
+
+// Collector implements lingstorage.MetricsCollector and prometheus.Collector
+type Collector struct {
+	requests   *prom.CounterVec
+	errors     *prom.CounterVec
+	latency    *prom.HistogramVec
+	retries    *prom.CounterVec
+	uploaded   *prom.CounterVec
+	downloaded *prom.CounterVec
+}
+
+// NewCollector create collector, register it with a prometheus registry and set it as Config.Metrics
+func NewCollector(opts Options) *Collector {
+	if opts.Namespace == "" {
+		opts.Namespace = "lingstorage"
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = prom.DefBuckets
+	}
+	return &Collector{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "requests_total",
+			Help:      "Number of client operations by operation and status code.",
+		}, []string{"operation", "code"}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "request_errors_total",
+			Help:      "Number of failed client operations by operation and status code.",
+		}, []string{"operation", "code"}),
+		latency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of client operations including retries.",
+			Buckets:   opts.Buckets,
+		}, []string{"operation"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "retries_total",
+			Help:      "Number of retried attempts by operation.",
+		}, []string{"operation"}),
+		uploaded: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "uploaded_bytes_total",
+			Help:      "Payload bytes uploaded by operation.",
+		}, []string{"operation"}),
+		downloaded: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Payload bytes downloaded by operation.",
+		}, []string{"operation"}),
+	}
+}
+
+// ObserveRequest count operation and its latency
+func (c *Collector) ObserveRequest(operation string, statusCode int, err error, duration time.Duration) {
+	code := statusLabel(statusCode)
+	c.requests.WithLabelValues(operation, code).Inc()
+	if err != nil {
+		c.errors.WithLabelValues(operation, code).Inc()
+	}
+	c.latency.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// ObserveRetry count retried attempt
+func (c *Collector) ObserveRetry(operation string, attempt int) {
+	c.retries.WithLabelValues(operation).Inc()
+}
+
+// ObserveBytes count transferred bytes
+func (c *Collector) ObserveBytes(operation, direction string, n int64) {
+	switch direction {
+	case lingstorage.DirectionUpload:
+		c.uploaded.WithLabelValues(operation).Add(float64(n))
+	case lingstorage.DirectionDownload:
+		c.downloaded.WithLabelValues(operation).Add(float64(n))
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+	c.retries.Describe(ch)
+	c.uploaded.Describe(ch)
+	c.downloaded.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+	c.retries.Collect(ch)
+	c.uploaded.Collect(ch)
+	c.downloaded.Collect(ch)
+}
+
+// statusLabel status code label, "error" when no response was received
+func statusLabel(statusCode int) string {
+	if statusCode == 0 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode)
+}
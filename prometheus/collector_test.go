@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ lingstorage.MetricsCollector = (*Collector)(nil)
+
+func TestCollector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/public/files/bucket/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	collector := NewCollector(Options{})
+	registry := prom.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	client := lingstorage.NewClient(&lingstorage.Config{
+		BaseURL: server.URL,
+		APIKey:  "test-key",
+		Metrics: collector,
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+	require.Error(t, client.DeleteFile("bucket", "missing"))
+	result, err := client.Download(&lingstorage.DownloadRequest{Bucket: "bucket", Key: "key"})
+	require.NoError(t, err)
+	result.Body.Close()
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.requests.WithLabelValues("DeleteFile", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.errors.WithLabelValues("DeleteFile", "404")))
+	assert.Equal(t, float64(5), testutil.ToFloat64(collector.downloaded.WithLabelValues("Download")))
+	assert.Equal(t, 2, testutil.CollectAndCount(collector.latency))
+}
@@ -0,0 +1,135 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// newProxyFunc proxy selection of the transport. Without proxy settings in
+// config the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment is used
+func newProxyFunc(config *Config) (func(*http.Request) (*url.URL, error), error) {
+	if config.HTTPProxy == "" && config.HTTPSProxy == "" && len(config.NoProxy) == 0 {
+		return http.ProxyFromEnvironment, nil
+	}
+	httpProxy, err := parseProxyURL(config.HTTPProxy)
+	if err != nil {
+		return nil, err
+	}
+	httpsProxy := httpProxy
+	if config.HTTPSProxy != "" {
+		if httpsProxy, err = parseProxyURL(config.HTTPSProxy); err != nil {
+			return nil, err
+		}
+	}
+	noProxy := parseNoProxy(config.NoProxy)
+	return func(req *http.Request) (*url.URL, error) {
+		proxy, port := httpProxy, "80"
+		if req.URL.Scheme == "https" {
+			proxy, port = httpsProxy, "443"
+		}
+		if req.URL.Port() != "" {
+			port = req.URL.Port()
+		}
+		if proxy == nil || noProxy.match(req.URL.Hostname(), port) {
+			return nil, nil
+		}
+		return proxy, nil
+	}, nil
+}
+
+// parseProxyURL parse an http, https, socks5 or socks5h proxy URL, a bare
+// host:port is an http proxy. Empty raw means no proxy
+func parseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return proxy, nil
+}
+
+// noProxyList hosts reached directly, same rules as the NO_PROXY variable
+type noProxyList struct {
+	all      bool
+	networks []*net.IPNet
+	ips      []net.IP
+	domains  []noProxyHost // "example.com" also matches its subdomains
+}
+
+type noProxyHost struct {
+	host, port string
+}
+
+func parseNoProxy(entries []string) noProxyList {
+	var list noProxyList
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			list.all = true
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			list.networks = append(list.networks, network)
+			continue
+		}
+		host, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			host, port = entry, ""
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			list.ips = append(list.ips, ip)
+			continue
+		}
+		list.domains = append(list.domains, noProxyHost{host: strings.TrimPrefix(host, "*."), port: port})
+	}
+	return list
+}
+
+func (l noProxyList) match(host, port string) bool {
+	if l.all {
+		return true
+	}
+	host = strings.ToLower(host)
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range l.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		for _, other := range l.ips {
+			if other.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, domain := range l.domains {
+		if domain.port != "" && domain.port != port {
+			continue
+		}
+		name := strings.TrimPrefix(domain.host, ".")
+		if host == name || strings.HasSuffix(host, "."+name) {
+			return true
+		}
+	}
+	return false
+}
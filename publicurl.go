@@ -0,0 +1,118 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDomainCacheTTL how long bucket domains are cached by PublicURL
+const DefaultDomainCacheTTL = 5 * time.Minute
+
+var (
+	// ErrBucketPrivate bucket objects are not publicly readable, use GetFileURL for a signed URL
+	ErrBucketPrivate = errors.New("lingstorage: bucket is private")
+	// ErrNoPublicDomain bucket has no domain bound
+	ErrNoPublicDomain = errors.New("lingstorage: bucket has no public domain")
+)
+
+// bucketDomains domains and privacy of one bucket
+type bucketDomains struct {
+	domains   []string
+	private   bool
+	fetchedAt time.Time
+}
+
+// domainCache bucket domain lookups, shared by client copies
+type domainCache struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketDomains
+}
+
+// PublicURL direct public URL of an object on the first domain of the bucket.
+// Domain lookups are cached for Config.DomainCacheTTL, so building many links
+// costs one API call per bucket. Private buckets return ErrBucketPrivate
+func (c *Client) PublicURL(bucket, key string) (string, error) {
+	info, err := c.bucketDomains(bucket)
+	if err != nil {
+		return "", err
+	}
+	if info.private {
+		return "", fmt.Errorf("%w: %s", ErrBucketPrivate, bucket)
+	}
+	if len(info.domains) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrNoPublicDomain, bucket)
+	}
+	base := strings.TrimRight(info.domains[0], "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return base + "/" + strings.Join(segments, "/"), nil
+}
+
+// InvalidateDomainCache drop cached domains of the buckets, all buckets when none given
+func (c *Client) InvalidateDomainCache(buckets ...string) {
+	c.domains.mu.Lock()
+	defer c.domains.mu.Unlock()
+	if len(buckets) == 0 {
+		c.domains.buckets = nil
+		return
+	}
+	for _, bucket := range buckets {
+		delete(c.domains.buckets, bucket)
+	}
+}
+
+// bucketDomains cached domains of bucket, fetched when missing or expired
+func (c *Client) bucketDomains(bucket string) (*bucketDomains, error) {
+	ttl := c.config.DomainCacheTTL
+	if ttl == 0 {
+		ttl = DefaultDomainCacheTTL
+	}
+	c.domains.mu.Lock()
+	info, ok := c.domains.buckets[bucket]
+	c.domains.mu.Unlock()
+	if ok && time.Since(info.fetchedAt) < ttl {
+		return info, nil
+	}
+
+	info, err := c.fetchBucketDomains(bucket)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		c.domains.mu.Lock()
+		if c.domains.buckets == nil {
+			c.domains.buckets = make(map[string]*bucketDomains)
+		}
+		c.domains.buckets[bucket] = info
+		c.domains.mu.Unlock()
+	}
+	return info, nil
+}
+
+// fetchBucketDomains domains and privacy of the bucket from the domains API
+func (c *Client) fetchBucketDomains(bucketName string) (_ *bucketDomains, err error) {
+	ctx, op := c.startOperation("GetBucketDomains", bucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Domains   []string `json:"domains"`
+		IsPrivate bool     `json:"isPrivate"`
+	}
+	if err := c.call(ctx, "GET", bucketPath(bucketName)+"/domains", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &bucketDomains{
+		domains:   data.Domains,
+		private:   data.IsPrivate,
+		fetchedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package lingstorage_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicURL(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	server.CreateBucket("photos")
+
+	url, err := client.PublicURL("photos", "2024/summer trip/a#1.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "https://photos.fake.lingstorage.local/2024/summer%20trip/a%231.jpg", url)
+
+	// 设为私有后缓存失效
+	require.NoError(t, client.SetBucketPrivate(&lingstorage.SetBucketPrivateRequest{BucketName: "photos", IsPrivate: true}))
+	_, err = client.PublicURL("photos", "a.jpg")
+	assert.ErrorIs(t, err, lingstorage.ErrBucketPrivate)
+}
+
+func TestPublicURLCachesDomains(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		switch r.URL.Path {
+		case "/api/public/buckets/cdn-bucket/domains":
+			w.Write([]byte(`{"success":true,"data":{"domains":["http://cdn.example.com/"]}}`))
+		default:
+			w.Write([]byte(`{"success":true,"data":{"domains":[]}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+	for i := 0; i < 10; i++ {
+		url, err := client.WithContext(context.Background()).PublicURL("cdn-bucket", "a.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, "http://cdn.example.com/a.jpg", url)
+	}
+	assert.Equal(t, 1, lookups)
+
+	client.InvalidateDomainCache("cdn-bucket")
+	_, err := client.PublicURL("cdn-bucket", "a.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+
+	_, err = client.PublicURL("bare-bucket", "a.jpg")
+	assert.ErrorIs(t, err, lingstorage.ErrNoPublicDomain)
+
+	uncached := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1, DomainCacheTTL: -1})
+	uncached.PublicURL("cdn-bucket", "a.jpg")
+	uncached.PublicURL("cdn-bucket", "a.jpg")
+	assert.Equal(t, 5, lookups)
+}
@@ -0,0 +1,416 @@
+// Package queue provides a durable upload queue for unreliable networks.
+// Items are persisted to a directory, one JSON file per item, so pending
+// uploads and their retry schedule survive process restarts.
+//
+//	q, err := queue.Open(client, queue.Options{Dir: "/var/lib/app/uploads"})
+//	q.Enqueue("/data/reading.csv", "sensors", "device-1/reading.csv")
+//	err = q.Run(ctx)
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+)
+
+const (
+	// DefaultMaxAttempts upload attempts of an item before it is marked failed
+	DefaultMaxAttempts = 10
+	// DefaultBackoff delay before the first retry, doubled on each retry
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff upper bound of the retry delay
+	DefaultMaxBackoff = 5 * time.Minute
+	// DefaultConcurrency parallel uploads
+	DefaultConcurrency = 1
+)
+
+// Status state of a queued item
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusUploading Status = "uploading"
+	StatusRetrying  Status = "retrying"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+)
+
+// Options queue options
+type Options struct {
+	Dir         string // directory holding the queue, created if missing
+	MaxAttempts int    // default 10
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Concurrency int
+	// OnStatus called on every status change of an item
+	OnStatus func(item Item)
+}
+
+// Item one queued upload
+type Item struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	Bucket      string    `json:"bucket"`
+	Key         string    `json:"key"`
+	Status      Status    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError,omitempty"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Queue durable upload queue
+type Queue struct {
+	client   *lingstorage.Client
+	opts     Options
+	mu       sync.Mutex
+	items    map[string]*Item
+	inFlight map[string]bool
+	wake     chan struct{}
+}
+
+// Open open the queue in opts.Dir, items left over from a previous run are resumed
+func Open(client *lingstorage.Client, opts Options) (*Queue, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("queue directory is required")
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	q := &Queue{
+		client:   client,
+		opts:     opts,
+		items:    make(map[string]*Item),
+		inFlight: make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *Queue) load() error {
+	entries, err := os.ReadDir(q.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read queue directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.opts.Dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read queue item: %w", err)
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			return fmt.Errorf("failed to parse queue item %s: %w", entry.Name(), err)
+		}
+		// interrupted by a crash or shutdown
+		if item.Status == StatusUploading {
+			item.Status = StatusPending
+		}
+		q.items[item.ID] = &item
+	}
+	return nil
+}
+
+// Enqueue add a file to the queue, the item is on disk when Enqueue returns
+func (q *Queue) Enqueue(filePath, bucket, key string) (Item, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if bucket == "" || key == "" {
+		return Item{}, errors.New("bucket and key are required")
+	}
+	id, err := newID()
+	if err != nil {
+		return Item{}, err
+	}
+	now := time.Now()
+	item := &Item{ID: id, Path: abs, Bucket: bucket, Key: key, Status: StatusPending, NextAttempt: now, CreatedAt: now}
+
+	q.mu.Lock()
+	if err := q.save(item); err != nil {
+		q.mu.Unlock()
+		return Item{}, err
+	}
+	q.items[id] = item
+	snapshot := *item
+	q.mu.Unlock()
+
+	q.notify(snapshot)
+	q.signal()
+	return snapshot, nil
+}
+
+// Items queued items ordered by creation time, including failed ones
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := make([]Item, 0, len(q.items))
+	for _, item := range q.items {
+		items = append(items, *item)
+	}
+	sortItems(items)
+	return items
+}
+
+// Retry reschedule a failed item with a fresh attempt budget
+func (q *Queue) Retry(id string) error {
+	q.mu.Lock()
+	item, ok := q.items[id]
+	if !ok {
+		q.mu.Unlock()
+		return fmt.Errorf("queue item %s not found", id)
+	}
+	if q.inFlight[id] {
+		q.mu.Unlock()
+		return nil
+	}
+	item.Status = StatusPending
+	item.Attempts = 0
+	item.NextAttempt = time.Now()
+	err := q.save(item)
+	snapshot := *item
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	q.notify(snapshot)
+	q.signal()
+	return nil
+}
+
+// Remove drop an item from the queue, an item being uploaded finishes its
+// current attempt but is neither saved nor reported again
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.items[id]; !ok {
+		return fmt.Errorf("queue item %s not found", id)
+	}
+	delete(q.items, id)
+	return q.delete(id)
+}
+
+// Run upload queued items until ctx ends, items not yet due wait for their retry time
+func (q *Queue) Run(ctx context.Context) error {
+	client := q.client.WithContext(ctx)
+	sem := make(chan struct{}, q.opts.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		item, wait := q.claim(time.Now())
+		if item == nil {
+			<-sem
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-q.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			q.process(ctx, client, item)
+		}()
+	}
+}
+
+// claim next due item marked uploading, or the time until the next one is due
+func (q *Queue) claim(now time.Time) (*Item, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next *Item
+	for _, item := range q.items {
+		if q.inFlight[item.ID] || (item.Status != StatusPending && item.Status != StatusRetrying) {
+			continue
+		}
+		if next == nil || item.NextAttempt.Before(next.NextAttempt) ||
+			(item.NextAttempt.Equal(next.NextAttempt) && item.ID < next.ID) {
+			next = item
+		}
+	}
+	if next == nil {
+		return nil, time.Hour
+	}
+	if wait := next.NextAttempt.Sub(now); wait > 0 {
+		return nil, wait
+	}
+	q.inFlight[next.ID] = true
+	return next, 0
+}
+
+func (q *Queue) process(ctx context.Context, client *lingstorage.Client, item *Item) {
+	q.update(item, func(item *Item) {
+		item.Status = StatusUploading
+		item.Attempts++
+	})
+
+	_, err := client.UploadFile(&lingstorage.UploadRequest{FilePath: item.Path, Bucket: item.Bucket, Key: item.Key})
+	switch {
+	case err == nil:
+		q.mu.Lock()
+		removed := q.removed(item)
+		item.Status = StatusDone
+		item.LastError = ""
+		delete(q.inFlight, item.ID)
+		if !removed {
+			delete(q.items, item.ID)
+			q.delete(item.ID)
+		}
+		snapshot := *item
+		q.mu.Unlock()
+		if !removed {
+			q.notify(snapshot)
+		}
+	case ctx.Err() != nil:
+		// shutdown is not an attempt, the item is resumed on the next run
+		q.update(item, func(item *Item) {
+			item.Status = StatusPending
+			item.Attempts--
+		})
+	default:
+		q.update(item, func(item *Item) {
+			item.LastError = err.Error()
+			if errors.Is(err, os.ErrNotExist) || item.Attempts >= q.opts.MaxAttempts {
+				item.Status = StatusFailed
+				return
+			}
+			item.Status = StatusRetrying
+			item.NextAttempt = time.Now().Add(q.backoff(item.Attempts))
+		})
+	}
+	q.mu.Lock()
+	delete(q.inFlight, item.ID)
+	q.mu.Unlock()
+	q.signal()
+}
+
+// update change an item, persist it and report the new status
+func (q *Queue) update(item *Item, fn func(item *Item)) {
+	q.mu.Lock()
+	if q.removed(item) {
+		q.mu.Unlock()
+		return
+	}
+	fn(item)
+	// best effort, a lost write only repeats an attempt after a restart
+	q.save(item)
+	snapshot := *item
+	q.mu.Unlock()
+	q.notify(snapshot)
+}
+
+// removed report whether item was dropped by Remove, must hold q.mu
+func (q *Queue) removed(item *Item) bool {
+	return q.items[item.ID] != item
+}
+
+// backoff delay after the given number of failed attempts
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.opts.Backoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= q.opts.MaxBackoff {
+			return q.opts.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func (q *Queue) notify(item Item) {
+	if q.opts.OnStatus != nil {
+		q.opts.OnStatus(item)
+	}
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) itemPath(id string) string {
+	return filepath.Join(q.opts.Dir, id+".json")
+}
+
+// save write an item atomically, caller holds mu
+func (q *Queue) save(item *Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode queue item: %w", err)
+	}
+	tmp := q.itemPath(item.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write queue item: %w", err)
+	}
+	if err := os.Rename(tmp, q.itemPath(item.ID)); err != nil {
+		return fmt.Errorf("failed to write queue item: %w", err)
+	}
+	return nil
+}
+
+// delete remove an item file, caller holds mu
+func (q *Queue) delete(id string) error {
+	if err := os.Remove(q.itemPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove queue item: %w", err)
+	}
+	return nil
+}
+
+// newID sortable unique item id
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate item id: %w", err)
+	}
+	return fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(b)), nil
+}
+
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return strings.Compare(items[i].ID, items[j].ID) < 0
+	})
+}
@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueUploads(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	src := filepath.Join(t.TempDir(), "reading.csv")
+	require.NoError(t, os.WriteFile(src, []byte("1,2,3"), 0644))
+
+	statuses := make(chan Item, 10)
+	dir := t.TempDir()
+	q, err := Open(server.Client(), Options{Dir: dir, OnStatus: func(item Item) { statuses <- item }})
+	require.NoError(t, err)
+	item, err := q.Enqueue(src, lingstoragetest.DefaultBucket, "device-1/reading.csv")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, item.Status)
+	assert.FileExists(t, filepath.Join(dir, item.ID+".json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	done := waitStatus(t, statuses, StatusDone)
+	assert.Equal(t, 1, done.Attempts)
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "device-1/reading.csv")
+	require.True(t, ok)
+	assert.Equal(t, "1,2,3", string(obj.Data))
+	assert.Empty(t, q.Items())
+	assert.NoFileExists(t, filepath.Join(dir, item.ID+".json"))
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	src := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(src, []byte("a"), 0644))
+	dir := t.TempDir()
+
+	// 第一个进程只入队，未上传即退出
+	first, err := Open(server.Client(), Options{Dir: dir})
+	require.NoError(t, err)
+	_, err = first.Enqueue(src, lingstoragetest.DefaultBucket, "a.txt")
+	require.NoError(t, err)
+
+	statuses := make(chan Item, 10)
+	second, err := Open(server.Client(), Options{Dir: dir, OnStatus: func(item Item) { statuses <- item }})
+	require.NoError(t, err)
+	require.Len(t, second.Items(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go second.Run(ctx)
+
+	waitStatus(t, statuses, StatusDone)
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "a.txt")
+	assert.True(t, ok)
+}
+
+func TestQueueRetriesAndFails(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	src := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(src, []byte("a"), 0644))
+	dir := t.TempDir()
+
+	statuses := make(chan Item, 10)
+	q, err := Open(server.Client(), Options{
+		Dir:         dir,
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+		OnStatus:    func(item Item) { statuses <- item },
+	})
+	require.NoError(t, err)
+	item, err := q.Enqueue(src, "missing-bucket", "a.txt")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	retrying := waitStatus(t, statuses, StatusRetrying)
+	assert.NotEmpty(t, retrying.LastError)
+	failed := waitStatus(t, statuses, StatusFailed)
+	assert.Equal(t, 2, failed.Attempts)
+
+	// 失败项保留在磁盘上，重启后仍可查看并重试
+	reopened, err := Open(server.Client(), Options{Dir: dir})
+	require.NoError(t, err)
+	items := reopened.Items()
+	require.Len(t, items, 1)
+	assert.Equal(t, StatusFailed, items[0].Status)
+
+	server.CreateBucket("missing-bucket")
+	require.NoError(t, q.Retry(item.ID))
+	waitStatus(t, statuses, StatusDone)
+}
+
+func TestQueueRemoveInFlight(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	src := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(src, []byte("a"), 0644))
+
+	release := make(chan struct{})
+	client := server.Client(lingstorage.WithPreUploadHook(func(*lingstorage.UploadRequest, io.Reader) error {
+		<-release
+		return errors.New("network down")
+	}))
+	statuses := make(chan Item, 10)
+	dir := t.TempDir()
+	q, err := Open(client, Options{Dir: dir, Backoff: time.Millisecond, OnStatus: func(item Item) { statuses <- item }})
+	require.NoError(t, err)
+	item, err := q.Enqueue(src, lingstoragetest.DefaultBucket, "a.txt")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+	waitStatus(t, statuses, StatusUploading)
+
+	// 上传中删除的条目不会被失败处理重新写回
+	require.NoError(t, q.Remove(item.ID))
+	close(release)
+	select {
+	case got := <-statuses:
+		t.Fatalf("unexpected status %s after remove", got.Status)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Empty(t, q.Items())
+	assert.NoFileExists(t, filepath.Join(dir, item.ID+".json"))
+}
+
+func TestQueueBackoff(t *testing.T) {
+	q := &Queue{opts: Options{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 4*time.Second, q.backoff(3))
+	assert.Equal(t, 5*time.Second, q.backoff(10))
+}
+
+func waitStatus(t *testing.T, statuses chan Item, status Status) Item {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case item := <-statuses:
+			if item.Status == status {
+				return item
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for status %s", status)
+			return Item{}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RedactedPlaceholder replacement of sensitive values
+const RedactedPlaceholder = "[REDACTED]"
+
+// Redactor report whether a header or query parameter is sensitive, on top of the built in list
+type Redactor func(name string) bool
+
+// sensitiveHeaders built in sensitive headers, canonical form
+var sensitiveHeaders = map[string]bool{
+	"Authorization":                         true,
+	"Proxy-Authorization":                   true,
+	"Cookie":                                true,
+	"Set-Cookie":                            true,
+	"X-Api-Key":                             true,
+	"X-Api-Secret":                          true,
+	"X-Signature":                           true,
+	"X-Server-Side-Encryption-Customer-Key": true,
+}
+
+// sensitiveQueryParams built in sensitive query parameters of presigned URLs, lower case
+var sensitiveQueryParams = map[string]bool{
+	"signature":            true,
+	"sign":                 true,
+	"token":                true,
+	"access_token":         true,
+	"secret":               true,
+	"credential":           true,
+	"x-api-key":            true,
+	"x-api-secret":         true,
+	"x-amz-signature":      true,
+	"x-amz-credential":     true,
+	"x-amz-security-token": true,
+}
+
+func (c *Client) isSensitiveHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if sensitiveHeaders[name] {
+		return true
+	}
+	if c.config.TokenHeader != "" && name == http.CanonicalHeaderKey(c.config.TokenHeader) {
+		return true
+	}
+	return c.config.Redactor != nil && c.config.Redactor(name)
+}
+
+func (c *Client) isSensitiveQuery(name string) bool {
+	if sensitiveQueryParams[strings.ToLower(name)] {
+		return true
+	}
+	return c.config.Redactor != nil && c.config.Redactor(name)
+}
+
+// RedactHeader copy of header with sensitive values replaced
+func (c *Client) RedactHeader(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if c.isSensitiveHeader(name) {
+			redacted[name] = []string{RedactedPlaceholder}
+			continue
+		}
+		redacted[name] = append([]string(nil), values...)
+	}
+	return redacted
+}
+
+// RedactURL url string with sensitive query parameters replaced
+func (c *Client) RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.scrub(rawURL)
+	}
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for name := range q {
+			if c.isSensitiveQuery(name) {
+				q[name] = []string{RedactedPlaceholder}
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return c.scrub(u.String())
+}
+
+// DumpRequest redacted one line description of the request, safe for logging
+func (c *Client) DumpRequest(req *http.Request) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", req.Method, c.RedactURL(req.URL.String()))
+	header := c.RedactHeader(req.Header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, " %s=%q", name, strings.Join(header[name], ","))
+	}
+	return sb.String()
+}
+
+// scrub replace any credential value of the client appearing in s, including
+// the access token last sent in the Authorization or TokenHeader header
+func (c *Client) scrub(s string) string {
+	secrets := []string{c.config.APISecret, c.config.APIKey}
+	if c.credentialsProvider != nil {
+		if creds, err := c.credentialsProvider.Retrieve(); err == nil {
+			secrets = append(secrets, creds.APISecret, creds.APIKey)
+		}
+	}
+	if c.token != nil {
+		if token := c.token.Load(); token != nil {
+			secrets = append(secrets, *token)
+		}
+	}
+	for _, secret := range secrets {
+		// short values would redact unrelated text
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, RedactedPlaceholder)
+		}
+	}
+	return s
+}
+
+// redactError redact URL of transport errors and credentials in error messages
+func (c *Client) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = c.RedactURL(urlErr.URL)
+	}
+	return err
+}
+
+// redactAPIError scrub credentials echoed back by the server
+func (c *Client) redactAPIError(apiErr *APIError) *APIError {
+	apiErr.Message = c.scrub(apiErr.Message)
+	apiErr.Details = c.scrub(apiErr.Details)
+	return apiErr
+}
+
+// String config description with credentials redacted
+func (c Config) String() string {
+	apiKey, apiSecret := c.APIKey, c.APISecret
+	if apiKey != "" {
+		apiKey = RedactedPlaceholder
+	}
+	if apiSecret != "" {
+		apiSecret = RedactedPlaceholder
+	}
+	return fmt.Sprintf("Config{BaseURL: %s, APIKey: %s, APISecret: %s, Timeout: %s, RetryCount: %d, UserAgent: %s}",
+		c.BaseURL, apiKey, apiSecret, c.Timeout, c.RetryCount, c.UserAgent)
+}
+
+// GoString same as String so %#v does not leak credentials
+func (c Config) GoString() string {
+	return c.String()
+}
@@ -0,0 +1,145 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownRegion configured region is not offered by the server
+	ErrUnknownRegion = errors.New("lingstorage: unknown region")
+	// ErrRegionMismatch bucket lives in another region than the client targets
+	ErrRegionMismatch = errors.New("lingstorage: bucket is in another region")
+)
+
+// RegionInfo storage region
+type RegionInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"` // regional API address, empty when served by BaseURL
+	Default  bool   `json:"default"`
+}
+
+// regionResolver regional endpoint and bucket regions, shared by client copies
+type regionResolver struct {
+	mu       sync.Mutex
+	resolved bool
+	endpoint string
+	buckets  map[string]string
+}
+
+// ListRegions regions offered by the server
+func (c *Client) ListRegions() (_ []RegionInfo, err error) {
+	ctx, op := c.startOperation("ListRegions", "", "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Regions []RegionInfo `json:"regions"`
+	}
+	if err := c.call(ctx, "GET", "/api/public/regions", nil, &data); err != nil {
+		return nil, fmt.Errorf("list regions %w", err)
+	}
+	return data.Regions, nil
+}
+
+// GetBucketRegion region of the bucket
+func (c *Client) GetBucketRegion(bucketName string) (_ string, err error) {
+	ctx, op := c.startOperation("GetBucketRegion", bucketName, "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Region string `json:"region"`
+	}
+	if err := c.call(ctx, "GET", bucketPath(bucketName)+"/region", nil, &data); err != nil {
+		return "", fmt.Errorf("get bucket region %w", err)
+	}
+	return data.Region, nil
+}
+
+// Region region the client targets, empty when not configured
+func (c *Client) Region() string {
+	return c.config.Region
+}
+
+// regionEndpoint regional endpoint replacing BaseURL, resolved once by ListRegions.
+// Lookup failures are not cached so a later request may succeed
+func (c *Client) regionEndpoint() (string, error) {
+	r := c.regions
+	r.mu.Lock()
+	if r.resolved {
+		endpoint := r.endpoint
+		r.mu.Unlock()
+		return endpoint, nil
+	}
+	r.mu.Unlock()
+
+	regions, err := c.ListRegions()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve region %s: %w", c.config.Region, err)
+	}
+	for _, region := range regions {
+		if region.ID != c.config.Region {
+			continue
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.resolved = true
+		r.endpoint = strings.TrimRight(region.Endpoint, "/")
+		return r.endpoint, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownRegion, c.config.Region)
+}
+
+// checkBucketRegion fail when the bucket is known to live in another region.
+// Servers without bucket regions are not validated
+func (c *Client) checkBucketRegion(bucket string) error {
+	r := c.regions
+	r.mu.Lock()
+	region, ok := r.buckets[bucket]
+	r.mu.Unlock()
+	if !ok {
+		var err error
+		region, err = c.GetBucketRegion(bucket)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			region, err = "", nil
+		}
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		if r.buckets == nil {
+			r.buckets = make(map[string]string)
+		}
+		r.buckets[bucket] = region
+		r.mu.Unlock()
+	}
+	if region != "" && region != c.config.Region {
+		return fmt.Errorf("%w: %s is in %s, client targets %s", ErrRegionMismatch, bucket, region, c.config.Region)
+	}
+	return nil
+}
+
+// prepareRegion regional endpoint of req after validating its bucket, empty
+// when no region is configured
+func (c *Client) prepareRegion(req *http.Request) (string, error) {
+	if c.config.Region == "" {
+		return "", nil
+	}
+	op := operationFromContext(req.Context())
+	if op != nil && (op.name == "ListRegions" || op.name == "GetBucketRegion") {
+		return "", nil
+	}
+	lookup := c.WithContext(req.Context())
+	endpoint, err := lookup.regionEndpoint()
+	if err != nil {
+		return "", err
+	}
+	if op != nil && op.bucket != "" && op.name != "CreateBucket" {
+		if err := lookup.checkBucketRegion(op.bucket); err != nil {
+			return "", err
+		}
+	}
+	return endpoint, nil
+}
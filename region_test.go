@@ -0,0 +1,82 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionRouting(t *testing.T) {
+	var regionalHits, regionLookups int32
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&regionalHits, 1)
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer regional.Close()
+
+	var created CreateBucketRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/regions":
+			w.Write([]byte(`{"success":true,"data":{"regions":[
+				{"id":"cn-east","name":"East","endpoint":"` + regional.URL + `"},
+				{"id":"cn-north","name":"North","default":true}]}}`))
+		case "/api/public/buckets/east-bucket/region":
+			atomic.AddInt32(&regionLookups, 1)
+			w.Write([]byte(`{"success":true,"data":{"region":"cn-east"}}`))
+		case "/api/public/buckets/north-bucket/region":
+			w.Write([]byte(`{"success":true,"data":{"region":"cn-north"}}`))
+		case "/api/public/buckets/legacy/region":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request to primary: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Region: "cn-east", RetryCount: -1})
+	regions, err := client.ListRegions()
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	assert.True(t, regions[1].Default)
+
+	// 桶操作发往区域节点，桶区域只查询一次
+	require.NoError(t, client.DeleteFile("east-bucket", "a"))
+	require.NoError(t, client.DeleteFile("east-bucket", "b"))
+	assert.EqualValues(t, 2, regionalHits)
+	assert.EqualValues(t, 1, regionLookups)
+
+	// 桶位于其他区域
+	err = client.DeleteFile("north-bucket", "a")
+	assert.ErrorIs(t, err, ErrRegionMismatch)
+
+	// 服务端未返回桶区域时不做校验
+	require.NoError(t, client.DeleteFile("legacy", "a"))
+
+	// 创建桶默认使用客户端区域
+	regional.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.Write([]byte(`{"success":true}`))
+	})
+	require.NoError(t, client.CreateBucket(&CreateBucketRequest{BucketName: "new"}))
+	assert.Equal(t, "cn-east", created.Region)
+	err = client.CreateBucket(&CreateBucketRequest{BucketName: "new", Region: "cn-north"})
+	assert.ErrorIs(t, err, ErrRegionMismatch)
+}
+
+func TestUnknownRegion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":{"regions":[{"id":"cn-east"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Region: "us-west", RetryCount: -1})
+	err := client.DeleteFile("bucket", "key")
+	assert.ErrorIs(t, err, ErrUnknownRegion)
+	assert.Equal(t, "us-west", client.Region())
+}
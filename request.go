@@ -0,0 +1,71 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxDrainBytes response bytes read before closing a discarded response, so
+// its connection can go back to the pool
+const maxDrainBytes = 64 << 10
+
+// newAttempt request of attempt n built from template. Every attempt gets its
+// own copy with fresh headers, a body replayed through GetBody and, with
+// AttemptTimeout, a context carrying the attempt deadline. It is signed by
+// signAttempt once routed. The cancel func releases that context and must be
+// called once the attempt is done
+func (c *Client) newAttempt(template *http.Request, n int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := template.Context(), context.CancelFunc(func() {})
+	if c.config.AttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.config.AttemptTimeout)
+	}
+	req := template.Clone(ctx)
+	if n > 1 && template.GetBody != nil {
+		body, err := template.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		req.Body = body
+	}
+	return req, cancel, nil
+}
+
+// replayable whether template can be sent again, a body without GetBody is
+// consumed by the first attempt
+func replayable(template *http.Request) bool {
+	return template.Body == nil || template.Body == http.NoBody || template.GetBody != nil
+}
+
+// discardResponse drain and close a response that is not returned to the caller
+func discardResponse(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	resp.Body.Close()
+}
+
+// cancelOnClose response body releasing the attempt context once the caller closed it
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay wait before retry n, returns early with the error of ctx
+func retryDelay(ctx context.Context, retry int) error {
+	timer := time.NewTimer(time.Duration(retry) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
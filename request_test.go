@@ -0,0 +1,95 @@
+package lingstorage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBuildsFreshRequests(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 每次尝试只带本次 hook 添加的请求头
+		assert.Len(t, r.Header.Values("X-Attempt"), 1)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("busy"))
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"key":"k","size":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 1}, WithRequestHook(func(req *http.Request) {
+		req.Header.Add("X-Attempt", "1")
+	}))
+	info, err := client.GetFileInfo("bkt", "k")
+	require.NoError(t, err)
+	assert.Equal(t, "k", info.Key)
+	assert.EqualValues(t, 2, attempts)
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"key":"k"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 1, AttemptTimeout: 100 * time.Millisecond})
+	start := time.Now()
+	_, err := client.GetFileInfo("bkt", "k")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, attempts)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestRetryDelayHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 3}).WithContext(ctx)
+	start := time.Now()
+	_, err := client.GetFileInfo("bkt", "k")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetrySkipsConsumedBody(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 2})
+	// 无法重放的 body 只发送一次
+	req, err := http.NewRequest("POST", server.URL+"/api/public/upload", io.NopCloser(strings.NewReader("data")))
+	require.NoError(t, err)
+	resp, err := client.doRequestWithRetry(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 1, attempts)
+}
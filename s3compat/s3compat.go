@@ -0,0 +1,319 @@
+// Package s3compat exposes a LingStorage client through S3 style operations
+// (PutObject, GetObject, HeadObject, ListObjectsV2, ...) so tools written
+// against S3 semantics can be pointed at LingStorage with little change.
+//
+//	s3 := s3compat.New(client)
+//	out, err := s3.ListObjectsV2(ctx, &s3compat.ListObjectsV2Input{Bucket: "photos", Prefix: "2024/"})
+package s3compat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+)
+
+// DefaultMaxKeys page size of ListObjectsV2, same as S3
+const DefaultMaxKeys = 1000
+
+// S3 error codes
+const (
+	ErrCodeNoSuchKey    = "NoSuchKey"
+	ErrCodeNoSuchBucket = "NoSuchBucket"
+	ErrCodeAccessDenied = "AccessDenied"
+)
+
+// Error S3 style error, the LingStorage error is kept as Err
+type Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Client S3 style view of a LingStorage client
+type Client struct {
+	client *lingstorage.Client
+}
+
+// New wrap a LingStorage client
+func New(client *lingstorage.Client) *Client {
+	return &Client{client: client}
+}
+
+// Object entry of ListObjectsV2
+type Object struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// PutObjectInput upload one object, ContentLength is -1 if unknown
+type PutObjectInput struct {
+	Bucket        string
+	Key           string
+	Body          io.Reader
+	ContentLength int64
+	Metadata      map[string]string
+}
+
+// PutObjectOutput result of PutObject
+type PutObjectOutput struct {
+	ETag string
+}
+
+// PutObject upload an object
+func (c *Client) PutObject(ctx context.Context, in *PutObjectInput) (*PutObjectOutput, error) {
+	_, err := c.client.WithContext(ctx).UploadFromReader(&lingstorage.UploadFromReaderRequest{
+		Reader:   in.Body,
+		Filename: path.Base(in.Key),
+		Size:     in.ContentLength,
+		Bucket:   in.Bucket,
+		Key:      in.Key,
+		Metadata: in.Metadata,
+	})
+	if err != nil {
+		return nil, convertError(err, ErrCodeNoSuchBucket)
+	}
+	// upload results carry no checksum, read it back like S3 clients expect
+	head, err := c.HeadObject(ctx, &HeadObjectInput{Bucket: in.Bucket, Key: in.Key})
+	if err != nil {
+		return nil, err
+	}
+	return &PutObjectOutput{ETag: head.ETag}, nil
+}
+
+// GetObjectInput download one object
+type GetObjectInput struct {
+	Bucket string
+	Key    string
+}
+
+// GetObjectOutput object content, caller must close Body
+type GetObjectOutput struct {
+	Body          io.ReadCloser
+	ContentLength int64
+	ContentType   string
+	ETag          string
+	Metadata      map[string]string
+}
+
+// GetObject download an object
+func (c *Client) GetObject(ctx context.Context, in *GetObjectInput) (*GetObjectOutput, error) {
+	result, err := c.client.WithContext(ctx).Download(&lingstorage.DownloadRequest{Bucket: in.Bucket, Key: in.Key})
+	if err != nil {
+		return nil, convertError(err, ErrCodeNoSuchKey)
+	}
+	return &GetObjectOutput{
+		Body:          result.Body,
+		ContentLength: result.Size,
+		ContentType:   result.ContentType,
+		ETag:          quoteETag(result.ETag),
+		Metadata:      result.Metadata,
+	}, nil
+}
+
+// HeadObjectInput metadata of one object
+type HeadObjectInput struct {
+	Bucket string
+	Key    string
+}
+
+// HeadObjectOutput object metadata
+type HeadObjectOutput struct {
+	ContentLength int64
+	ContentType   string
+	ETag          string
+	LastModified  time.Time
+	Metadata      map[string]string
+}
+
+// HeadObject object metadata without content
+func (c *Client) HeadObject(ctx context.Context, in *HeadObjectInput) (*HeadObjectOutput, error) {
+	info, err := c.client.WithContext(ctx).GetFileInfo(in.Bucket, in.Key)
+	if err != nil {
+		return nil, convertError(err, ErrCodeNoSuchKey)
+	}
+	return &HeadObjectOutput{
+		ContentLength: info.Size,
+		ContentType:   info.ContentType,
+		ETag:          quoteETag(info.ETag),
+		LastModified:  info.LastModified,
+		Metadata:      info.Metadata,
+	}, nil
+}
+
+// DeleteObjectInput delete one object
+type DeleteObjectInput struct {
+	Bucket string
+	Key    string
+}
+
+// DeleteObject delete an object, deleting a missing object succeeds like on S3
+func (c *Client) DeleteObject(ctx context.Context, in *DeleteObjectInput) error {
+	err := c.client.WithContext(ctx).DeleteFile(in.Bucket, in.Key)
+	if err != nil && statusCode(err) != http.StatusNotFound {
+		return convertError(err, ErrCodeNoSuchKey)
+	}
+	return nil
+}
+
+// CopyObjectInput server side copy, CopySource is "bucket/key".
+// MetadataDirective is COPY (default) or REPLACE
+type CopyObjectInput struct {
+	Bucket            string
+	Key               string
+	CopySource        string
+	MetadataDirective string
+	Metadata          map[string]string
+	ContentType       string
+	StorageClass      string
+}
+
+// CopyObject copy an object
+func (c *Client) CopyObject(ctx context.Context, in *CopyObjectInput) error {
+	srcBucket, srcKey, ok := strings.Cut(strings.TrimPrefix(in.CopySource, "/"), "/")
+	if !ok || srcBucket == "" || srcKey == "" {
+		return &Error{Code: "InvalidArgument", Message: fmt.Sprintf("invalid copy source %q", in.CopySource), StatusCode: http.StatusBadRequest}
+	}
+	err := c.client.WithContext(ctx).CopyFile(&lingstorage.CopyFileRequest{
+		SrcBucket:         srcBucket,
+		SrcKey:            srcKey,
+		DestBucket:        in.Bucket,
+		DestKey:           in.Key,
+		MetadataDirective: lingstorage.MetadataDirective(in.MetadataDirective),
+		Metadata:          in.Metadata,
+		ContentType:       in.ContentType,
+		StorageClass:      in.StorageClass,
+	})
+	return convertError(err, ErrCodeNoSuchKey)
+}
+
+// ListObjectsV2Input list one page of objects
+type ListObjectsV2Input struct {
+	Bucket            string
+	Prefix            string
+	Delimiter         string
+	MaxKeys           int // default 1000
+	ContinuationToken string
+	StartAfter        string // used when ContinuationToken is empty
+}
+
+// ListObjectsV2Output one page of objects
+type ListObjectsV2Output struct {
+	Contents              []Object
+	CommonPrefixes        []string
+	IsTruncated           bool
+	NextContinuationToken string
+	KeyCount              int
+}
+
+// ListObjectsV2 list objects, continuation tokens are LingStorage markers
+func (c *Client) ListObjectsV2(ctx context.Context, in *ListObjectsV2Input) (*ListObjectsV2Output, error) {
+	maxKeys := in.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	marker := in.ContinuationToken
+	if marker == "" {
+		marker = in.StartAfter
+	}
+	result, err := c.client.WithContext(ctx).ListFiles(&lingstorage.ListFilesRequest{
+		Bucket:    in.Bucket,
+		Prefix:    in.Prefix,
+		Marker:    marker,
+		Delimiter: in.Delimiter,
+		Limit:     maxKeys,
+	})
+	if err != nil {
+		return nil, convertError(err, ErrCodeNoSuchBucket)
+	}
+	out := &ListObjectsV2Output{
+		Contents:       make([]Object, len(result.Files)),
+		CommonPrefixes: result.Directories,
+		IsTruncated:    result.IsTruncated,
+	}
+	for i, f := range result.Files {
+		out.Contents[i] = Object{Key: f.Key, Size: f.Size, ETag: quoteETag(f.ETag), LastModified: f.LastModified}
+	}
+	out.KeyCount = len(out.Contents) + len(out.CommonPrefixes)
+	if result.IsTruncated {
+		out.NextContinuationToken = result.NextMarker
+	}
+	return out, nil
+}
+
+// ListBuckets names of the buckets of the account
+func (c *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	buckets, err := c.client.WithContext(ctx).ListBuckets("", false)
+	return buckets, convertError(err, "")
+}
+
+// CreateBucket create a bucket
+func (c *Client) CreateBucket(ctx context.Context, bucket string) error {
+	err := c.client.WithContext(ctx).CreateBucket(&lingstorage.CreateBucketRequest{BucketName: bucket})
+	return convertError(err, "")
+}
+
+// DeleteBucket delete an empty bucket
+func (c *Client) DeleteBucket(ctx context.Context, bucket string) error {
+	err := c.client.WithContext(ctx).DeleteBucket(bucket)
+	return convertError(err, ErrCodeNoSuchBucket)
+}
+
+// convertError S3 error of a LingStorage error, notFound is the code used for 404
+func convertError(err error, notFound string) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *lingstorage.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	code := "InternalError"
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		if notFound == "" {
+			notFound = "NotFound"
+		}
+		code = notFound
+	case http.StatusForbidden, http.StatusUnauthorized:
+		code = ErrCodeAccessDenied
+	case http.StatusConflict:
+		code = "Conflict"
+	case http.StatusBadRequest:
+		code = "InvalidRequest"
+	}
+	return &Error{Code: code, Message: apiErr.Message, StatusCode: apiErr.StatusCode, Err: err}
+}
+
+func statusCode(err error) int {
+	var apiErr *lingstorage.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// quoteETag S3 returns quoted ETags
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
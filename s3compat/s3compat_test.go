@@ -0,0 +1,108 @@
+package s3compat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectOperations(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	s3 := New(server.Client())
+	ctx := context.Background()
+	bucket := lingstoragetest.DefaultBucket
+
+	put, err := s3.PutObject(ctx, &PutObjectInput{Bucket: bucket, Key: "docs/a.txt", Body: strings.NewReader("hello"), ContentLength: 5})
+	require.NoError(t, err)
+	assert.Equal(t, `"5d41402abc4b2a76b9719d911017c592"`, put.ETag)
+
+	get, err := s3.GetObject(ctx, &GetObjectInput{Bucket: bucket, Key: "docs/a.txt"})
+	require.NoError(t, err)
+	data, err := io.ReadAll(get.Body)
+	get.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	head, err := s3.HeadObject(ctx, &HeadObjectInput{Bucket: bucket, Key: "docs/a.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), head.ContentLength)
+	assert.Equal(t, put.ETag, head.ETag)
+
+	require.NoError(t, s3.CopyObject(ctx, &CopyObjectInput{Bucket: bucket, Key: "docs/b.txt", CopySource: bucket + "/docs/a.txt"}))
+	require.NoError(t, s3.DeleteObject(ctx, &DeleteObjectInput{Bucket: bucket, Key: "docs/a.txt"}))
+	// 删除不存在的对象与 S3 一样返回成功
+	require.NoError(t, s3.DeleteObject(ctx, &DeleteObjectInput{Bucket: bucket, Key: "docs/a.txt"}))
+
+	_, err = s3.HeadObject(ctx, &HeadObjectInput{Bucket: bucket, Key: "docs/a.txt"})
+	var s3Err *Error
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, ErrCodeNoSuchKey, s3Err.Code)
+
+	err = s3.CopyObject(ctx, &CopyObjectInput{Bucket: bucket, Key: "x", CopySource: "no-key"})
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, "InvalidArgument", s3Err.Code)
+}
+
+func TestListObjectsV2(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	s3 := New(server.Client())
+	ctx := context.Background()
+	bucket := lingstoragetest.DefaultBucket
+	for _, key := range []string{"a.txt", "b.txt", "c.txt", "dir/d.txt"} {
+		server.PutObject(bucket, key, []byte(key))
+	}
+
+	out, err := s3.ListObjectsV2(ctx, &ListObjectsV2Input{Bucket: bucket, Delimiter: "/"})
+	require.NoError(t, err)
+	assert.Len(t, out.Contents, 3)
+	assert.Equal(t, []string{"dir/"}, out.CommonPrefixes)
+	assert.Equal(t, 4, out.KeyCount)
+	assert.False(t, out.IsTruncated)
+
+	// 分页遍历
+	var keys []string
+	in := &ListObjectsV2Input{Bucket: bucket, MaxKeys: 2}
+	for {
+		page, err := s3.ListObjectsV2(ctx, in)
+		require.NoError(t, err)
+		for _, obj := range page.Contents {
+			keys = append(keys, obj.Key)
+		}
+		if !page.IsTruncated {
+			break
+		}
+		in.ContinuationToken = page.NextContinuationToken
+	}
+	assert.Equal(t, []string{"a.txt", "b.txt", "c.txt", "dir/d.txt"}, keys)
+
+	out, err = s3.ListObjectsV2(ctx, &ListObjectsV2Input{Bucket: bucket, StartAfter: "b.txt"})
+	require.NoError(t, err)
+	require.Len(t, out.Contents, 2)
+	assert.Equal(t, "c.txt", out.Contents[0].Key)
+}
+
+func TestBucketOperations(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	s3 := New(server.Client())
+	ctx := context.Background()
+
+	require.NoError(t, s3.CreateBucket(ctx, "logs"))
+	buckets, err := s3.ListBuckets(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, buckets, "logs")
+	require.NoError(t, s3.DeleteBucket(ctx, "logs"))
+
+	err = s3.DeleteBucket(ctx, "logs")
+	var s3Err *Error
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, ErrCodeNoSuchBucket, s3Err.Code)
+}
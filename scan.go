@@ -0,0 +1,70 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// malware scan states
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+	ScanError    = "error"
+)
+
+// ErrQuarantined object is quarantined and can not be downloaded
+var ErrQuarantined = errors.New("lingstorage: object is quarantined")
+
+// QuarantinedError download of a quarantined object, matches ErrQuarantined with errors.Is
+type QuarantinedError struct {
+	Bucket string
+	Key    string
+	Reason string // malware or moderation
+}
+
+func (e *QuarantinedError) Error() string {
+	return fmt.Sprintf("lingstorage: object %s/%s is quarantined: %s", e.Bucket, e.Key, e.Reason)
+}
+
+// Is report whether target is ErrQuarantined
+func (e *QuarantinedError) Is(target error) bool {
+	return target == ErrQuarantined
+}
+
+// ScanResult malware scan verdict of an object
+type ScanResult struct {
+	Status    string    `json:"status"` // pending, clean, infected or error
+	Threats   []string  `json:"threats,omitempty"`
+	Engine    string    `json:"engine,omitempty"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// Infected malware was found
+func (r *ScanResult) Infected() bool {
+	return r.Status == ScanInfected
+}
+
+// quarantineError typed error of a quarantined object response, nil otherwise
+func quarantineError(resp *http.Response, bucket, key string) error {
+	reason := resp.Header.Get(constants.XQUARANTINEREASON)
+	if resp.StatusCode != http.StatusForbidden || reason == "" {
+		return nil
+	}
+	return &QuarantinedError{Bucket: bucket, Key: key, Reason: reason}
+}
+
+// GetScanResult get the malware scan verdict of an object
+func (c *Client) GetScanResult(bucket, key string) (_ *ScanResult, err error) {
+	ctx, op := c.startOperation("GetScanResult", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	var result ScanResult
+	if err := c.call(ctx, "GET", objectPath(bucket, key)+"/scan", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,59 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMalwareScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/upload":
+			require.NoError(t, r.ParseMultipartForm(32<<20))
+			assert.Equal(t, "true", r.FormValue("scanMalware"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{
+				"key": "a.exe", "malwareScan": map[string]interface{}{"status": "pending"},
+			}})
+		case "/api/public/files/shared/a.exe/scan":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"status": "infected", "threats": []string{"Win.Trojan.Agent"}, "engine": "clamav",
+			}})
+		case "/api/public/files/shared/a.exe/download":
+			w.Header().Set("X-Quarantine-Reason", "malware")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"object is quarantined"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	upload, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("MZ"), Filename: "a.exe", Bucket: "shared", ScanMalware: true})
+	require.NoError(t, err)
+	require.NotNil(t, upload.MalwareScan)
+	assert.Equal(t, ScanPending, upload.MalwareScan.Status)
+
+	result, err := client.GetScanResult("shared", "a.exe")
+	require.NoError(t, err)
+	assert.True(t, result.Infected())
+	assert.Equal(t, []string{"Win.Trojan.Agent"}, result.Threats)
+
+	_, err = client.Download(&DownloadRequest{Bucket: "shared", Key: "a.exe"})
+	assert.True(t, errors.Is(err, ErrQuarantined))
+	var quarantined *QuarantinedError
+	require.True(t, errors.As(err, &quarantined))
+	assert.Equal(t, "malware", quarantined.Reason)
+
+	// 普通 403 不是隔离错误
+	var apiErr *APIError
+	err = client.DeleteFile("shared", "missing")
+	assert.False(t, errors.Is(err, ErrQuarantined))
+	assert.True(t, errors.As(err, &apiErr))
+}
@@ -0,0 +1,60 @@
+package lingstorage
+
+import (
+	"time"
+)
+
+// server features reported by ServerInfo
+const (
+	FeatureMultipart    = "multipart"
+	FeatureVersioning   = "versioning"
+	FeatureTags         = "tags"
+	FeatureACL          = "acl"
+	FeatureCDN          = "cdn"
+	FeatureInventory    = "inventory"
+	FeatureImage        = "image"
+	FeatureAcceleration = "acceleration"
+	FeatureSnapshots    = "snapshots"
+	FeatureBucketSync   = "bucket-sync"
+)
+
+// ServerLimits limits enforced by the server, zero means not reported
+type ServerLimits struct {
+	MaxUploadSize int64 `json:"maxUploadSize"` // bytes of a single upload request
+	MaxObjectSize int64 `json:"maxObjectSize"` // bytes of a multipart object
+	MaxParts      int   `json:"maxParts"`
+	MinPartSize   int64 `json:"minPartSize"`
+	MaxPartSize   int64 `json:"maxPartSize"`
+	MaxListLimit  int   `json:"maxListLimit"` // max page size of list calls
+}
+
+// ServerInfo version, features and limits of the server
+type ServerInfo struct {
+	Version    string       `json:"version"`
+	APIVersion string       `json:"apiVersion"`
+	Region     string       `json:"region"`
+	Features   []string     `json:"features"`
+	Limits     ServerLimits `json:"limits"`
+	ServerTime time.Time    `json:"serverTime"`
+}
+
+// HasFeature check the server reports feature, see the Feature constants
+func (i *ServerInfo) HasFeature(feature string) bool {
+	for _, f := range i.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerInfo 获取服务端版本, 功能与限制, 用于在调用前做功能探测
+func (c *Client) ServerInfo() (_ *ServerInfo, err error) {
+	ctx, op := c.startOperation("ServerInfo", "", "")
+	defer func() { c.endOperation(op, err) }()
+	var result ServerInfo
+	if err := c.call(ctx, "GET", "/api/public/info", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,46 @@
+package lingstorage_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/info", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"version":  "2.3.1",
+			"region":   "cn-east-1",
+			"features": []string{"multipart", "tags"},
+			"limits":   map[string]interface{}{"maxUploadSize": 5 << 30, "maxParts": 10000},
+		}})
+	}))
+	defer server.Close()
+
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+	info, err := client.ServerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "2.3.1", info.Version)
+	assert.Equal(t, "cn-east-1", info.Region)
+	assert.True(t, info.HasFeature(lingstorage.FeatureMultipart))
+	assert.False(t, info.HasFeature(lingstorage.FeatureVersioning))
+	assert.EqualValues(t, 5<<30, info.Limits.MaxUploadSize)
+	assert.Equal(t, 10000, info.Limits.MaxParts)
+}
+
+func TestServerInfoFakeServer(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	info, err := server.Client().ServerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "fake", info.Version)
+	assert.False(t, info.ServerTime.IsZero())
+}
@@ -0,0 +1,98 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BucketSnapshot point in time copy of every object of a bucket, kept by the server
+type BucketSnapshot struct {
+	Name        string    `json:"name"`
+	Bucket      string    `json:"bucket"`
+	Status      string    `json:"status"` // e.g. creating, ready or failed
+	ObjectCount int64     `json:"objectCount"`
+	TotalSize   int64     `json:"totalSize"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func validateSnapshotName(name string) error {
+	if name == "" {
+		return fmt.Errorf("snapshot name is empty")
+	}
+	if strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("snapshot name %q must not contain path separators", name)
+	}
+	return nil
+}
+
+func snapshotPath(bucket, name string) string {
+	return bucketPath(bucket) + "/snapshots/" + url.PathEscape(name)
+}
+
+// CreateBucketSnapshot 为存储桶创建快照, 例如在迁移前作为回退点
+func (c *Client) CreateBucketSnapshot(bucket, name string) (_ *BucketSnapshot, err error) {
+	ctx, op := c.startOperation("CreateBucketSnapshot", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	if err := c.requireFeature(FeatureSnapshots); err != nil {
+		return nil, err
+	}
+	var result BucketSnapshot
+	if err := c.call(ctx, "POST", bucketPath(bucket)+"/snapshots", map[string]string{"name": name}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBucketSnapshots 列出存储桶的快照
+func (c *Client) ListBucketSnapshots(bucket string) (_ []BucketSnapshot, err error) {
+	ctx, op := c.startOperation("ListBucketSnapshots", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := c.requireFeature(FeatureSnapshots); err != nil {
+		return nil, err
+	}
+	var result struct {
+		Snapshots []BucketSnapshot `json:"snapshots"`
+	}
+	if err := c.call(ctx, "GET", bucketPath(bucket)+"/snapshots", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Snapshots, nil
+}
+
+// DeleteBucketSnapshot 删除存储桶快照, 不影响存储桶中的对象
+func (c *Client) DeleteBucketSnapshot(bucket, name string) (err error) {
+	ctx, op := c.startOperation("DeleteBucketSnapshot", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	if err := c.requireFeature(FeatureSnapshots); err != nil {
+		return err
+	}
+	return c.call(ctx, "DELETE", snapshotPath(bucket, name), nil, nil)
+}
+
+// RestoreBucketSnapshot start restoring the bucket to the snapshot: objects
+// are returned to their snapshot content and objects created since are
+// removed. Poll the job with WaitForJob. The metadata cache is purged
+func (c *Client) RestoreBucketSnapshot(bucket, name string) (_ *Job, err error) {
+	ctx, op := c.startOperation("RestoreBucketSnapshot", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	if err := c.requireFeature(FeatureSnapshots); err != nil {
+		return nil, err
+	}
+	var result Job
+	if err := c.call(ctx, "POST", snapshotPath(bucket, name)+"/restore", nil, &result); err != nil {
+		return nil, err
+	}
+	c.metadata.purge()
+	return &result, nil
+}
@@ -0,0 +1,71 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketSnapshots(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/public/buckets/media/snapshots" && r.Method == "POST":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "pre-migration", body["name"])
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"name": "pre-migration", "bucket": "media", "status": "creating"}})
+		case r.URL.Path == "/api/public/buckets/media/snapshots":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"snapshots": []map[string]interface{}{
+				{"name": "pre-migration", "bucket": "media", "status": "ready", "objectCount": 42, "totalSize": 1024},
+			}}})
+		case r.URL.Path == "/api/public/buckets/media/snapshots/pre-migration/restore":
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "restore-1", "type": "snapshot-restore", "status": "pending", "bucket": "media"}})
+		case r.URL.Path == "/api/public/buckets/media/snapshots/old snap" && r.Method == "DELETE":
+			deleted = r.URL.Path
+			w.Write([]byte(`{"success":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	snapshot, err := client.CreateBucketSnapshot("media", "pre-migration")
+	require.NoError(t, err)
+	assert.Equal(t, "creating", snapshot.Status)
+
+	snapshots, err := client.ListBucketSnapshots("media")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.EqualValues(t, 42, snapshots[0].ObjectCount)
+
+	job, err := client.RestoreBucketSnapshot("media", "pre-migration")
+	require.NoError(t, err)
+	assert.Equal(t, "restore-1", job.ID)
+
+	require.NoError(t, client.DeleteBucketSnapshot("media", "old snap"))
+	assert.Equal(t, "/api/public/buckets/media/snapshots/old snap", deleted)
+
+	_, err = client.CreateBucketSnapshot("media", "")
+	assert.Error(t, err)
+	assert.Error(t, client.DeleteBucketSnapshot("media", "../other"))
+}
+
+func TestBucketSnapshotsNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"features": []string{FeatureTags}}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	_, err := client.CreateBucketSnapshot("media", "pre-migration")
+	assert.ErrorIs(t, err, ErrNotSupported)
+	_, err = client.RestoreBucketSnapshot("media", "pre-migration")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
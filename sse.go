@@ -0,0 +1,49 @@
+package lingstorage
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+const (
+	// SSEAES256 server managed AES-256 keys
+	SSEAES256 = "AES256"
+	// SSEKMS server side encryption with KMS managed keys
+	SSEKMS = "kms"
+)
+
+// validateSSE check server side encryption options
+func validateSSE(algorithm string, customerKey []byte) error {
+	switch algorithm {
+	case "", SSEAES256, SSEKMS:
+	default:
+		return fmt.Errorf("unsupported server side encryption algorithm: %s", algorithm)
+	}
+	if customerKey != nil && len(customerKey) != 32 {
+		return fmt.Errorf("customer encryption key must be 32 bytes, got %d", len(customerKey))
+	}
+	if customerKey != nil && algorithm != "" {
+		return fmt.Errorf("customer encryption key can not be combined with %s", algorithm)
+	}
+	return nil
+}
+
+// setSSEHeaders set server side encryption headers
+func setSSEHeaders(header http.Header, algorithm, kmsKeyID string, customerKey []byte) {
+	if algorithm != "" {
+		header.Set(constants.XSSE, algorithm)
+	}
+	if algorithm == SSEKMS && kmsKeyID != "" {
+		header.Set(constants.XSSEKMSKEYID, kmsKeyID)
+	}
+	if customerKey != nil {
+		sum := md5.Sum(customerKey)
+		header.Set(constants.XSSECALGORITHM, SSEAES256)
+		header.Set(constants.XSSECKEY, base64.StdEncoding.EncodeToString(customerKey))
+		header.Set(constants.XSSECKEYMD5, base64.StdEncoding.EncodeToString(sum[:]))
+	}
+}
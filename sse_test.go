@@ -0,0 +1,69 @@
+package lingstorage
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadWithServerSideEncryption(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "kms", r.Header.Get("X-Server-Side-Encryption"))
+		assert.Equal(t, "key-1", r.Header.Get("X-Server-Side-Encryption-Kms-Key-Id"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 200,
+			"data": map[string]interface{}{"key": "a.txt"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	_, err := client.UploadBytes(&UploadBytesRequest{
+		Data:         []byte("hello"),
+		Filename:     "a.txt",
+		Bucket:       "secure",
+		SSEAlgorithm: SSEKMS,
+		SSEKMSKeyID:  "key-1",
+	})
+	require.NoError(t, err)
+}
+
+func TestDownloadWithCustomerKey(t *testing.T) {
+	customerKey := []byte(strings.Repeat("k", 32))
+	sum := md5.Sum(customerKey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "AES256", r.Header.Get("X-Server-Side-Encryption-Customer-Algorithm"))
+		assert.Equal(t, base64.StdEncoding.EncodeToString(customerKey), r.Header.Get("X-Server-Side-Encryption-Customer-Key"))
+		assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), r.Header.Get("X-Server-Side-Encryption-Customer-Key-Md5"))
+		w.Header().Set("X-Server-Side-Encryption", "AES256")
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.Download(&DownloadRequest{Bucket: "secure", Key: "a.txt", SSECustomerKey: customerKey})
+	require.NoError(t, err)
+	defer result.Body.Close()
+	assert.Equal(t, "AES256", result.ServerSideEncryption)
+}
+
+func TestValidateSSE(t *testing.T) {
+	assert.NoError(t, validateSSE("", nil))
+	assert.NoError(t, validateSSE(SSEAES256, nil))
+	assert.Error(t, validateSSE("rot13", nil))
+	assert.Error(t, validateSSE("", []byte("short")))
+	assert.Error(t, validateSSE(SSEKMS, make([]byte, 32)))
+
+	info := &FileInfo{}
+	assert.False(t, info.Encrypted())
+	info.ServerSideEncryption = SSEAES256
+	assert.True(t, info.Encrypted())
+}
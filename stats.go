@@ -0,0 +1,126 @@
+package lingstorage
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats cumulative transfer statistics of a client
+type Stats struct {
+	Since             time.Time        // stats collected since
+	Operations        int64            // total operations
+	Errors            int64            // total failed operations
+	Retries           int64            // total retried attempts
+	BytesUploaded     int64            // payload bytes uploaded
+	BytesDownloaded   int64            // payload bytes downloaded
+	OperationsByType  map[string]int64 // operations by name, e.g. UploadFile
+	ErrorsByType      map[string]int64 // failed operations by name
+	ErrorsByCode      map[int]int64    // failed operations by http status, 0 for transport errors
+	TransferTime      time.Duration    // time spent in operations that transferred payload
+	AverageThroughput float64          // bytes per second over TransferTime
+
+	ConnectionsOpened  int64            // attempts sent on a new connection
+	ConnectionsReused  int64            // attempts sent on a pooled connection
+	RequestsByProtocol map[string]int64 // attempts by response protocol, e.g. HTTP/2.0
+}
+
+// statsRecorder concurrency safe stats accumulator
+type statsRecorder struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func newStatsRecorder() *statsRecorder {
+	r := &statsRecorder{}
+	r.reset()
+	return r
+}
+
+func (r *statsRecorder) reset() {
+	r.mu.Lock()
+	r.stats = Stats{
+		Since:            time.Now(),
+		OperationsByType: make(map[string]int64),
+		ErrorsByType:     make(map[string]int64),
+		ErrorsByCode:     make(map[int]int64),
+
+		RequestsByProtocol: make(map[string]int64),
+	}
+	r.mu.Unlock()
+}
+
+func (r *statsRecorder) record(op *operation, err error, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &r.stats
+	s.Operations++
+	s.OperationsByType[op.name]++
+	if op.attempts > 1 {
+		s.Retries += int64(op.attempts - 1)
+	}
+	if err != nil {
+		s.Errors++
+		s.ErrorsByType[op.name]++
+		s.ErrorsByCode[op.statusCode]++
+	}
+	if op.uploaded > 0 || op.downloaded > 0 {
+		s.BytesUploaded += op.uploaded
+		s.BytesDownloaded += op.downloaded
+		s.TransferTime += duration
+	}
+}
+
+// recordConn count the connection an attempt was sent on
+func (r *statsRecorder) recordConn(reused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reused {
+		r.stats.ConnectionsReused++
+	} else {
+		r.stats.ConnectionsOpened++
+	}
+}
+
+// recordProtocol count the protocol an attempt was answered with
+func (r *statsRecorder) recordProtocol(proto string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.RequestsByProtocol[proto]++
+}
+
+func (r *statsRecorder) snapshot() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats
+	s.OperationsByType = copyCounts(r.stats.OperationsByType)
+	s.ErrorsByType = copyCounts(r.stats.ErrorsByType)
+	s.RequestsByProtocol = copyCounts(r.stats.RequestsByProtocol)
+	s.ErrorsByCode = make(map[int]int64, len(r.stats.ErrorsByCode))
+	for k, v := range r.stats.ErrorsByCode {
+		s.ErrorsByCode[k] = v
+	}
+	if s.TransferTime > 0 {
+		s.AverageThroughput = float64(s.BytesUploaded+s.BytesDownloaded) / s.TransferTime.Seconds()
+	}
+	return s
+}
+
+func copyCounts(m map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Stats snapshot of the cumulative transfer statistics
+func (c *Client) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats clear the statistics
+func (c *Client) ResetStats() {
+	c.stats.reset()
+}
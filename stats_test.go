@@ -0,0 +1,76 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/public/upload":
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.txt"}})
+		case strings.HasSuffix(r.URL.Path, "/download"):
+			w.Write([]byte("0123456789"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	_, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt"})
+	require.NoError(t, err)
+	result, err := client.Download(&DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	require.NoError(t, err)
+	result.Body.Close()
+	require.Error(t, client.DeleteFile("bkt", "missing"))
+
+	stats := client.Stats()
+	assert.Equal(t, int64(3), stats.Operations)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, int64(5), stats.BytesUploaded)
+	assert.Equal(t, int64(10), stats.BytesDownloaded)
+	assert.Equal(t, int64(1), stats.OperationsByType["Upload"])
+	assert.Equal(t, int64(1), stats.ErrorsByType["DeleteFile"])
+	assert.Equal(t, int64(1), stats.ErrorsByCode[http.StatusNotFound])
+	assert.Greater(t, stats.AverageThroughput, float64(0))
+
+	// 派生客户端共享统计
+	require.Error(t, client.WithContext(context.Background()).DeleteFile("bkt", "missing"))
+	assert.Equal(t, int64(4), client.Stats().Operations)
+
+	client.ResetStats()
+	assert.Equal(t, int64(0), client.Stats().Operations)
+}
+
+func TestStatsCountUploadedBytesOnce(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.txt"}})
+	}))
+	defer server.Close()
+
+	// 重试成功只计一次, 失败的上传不计入
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: 1})
+	_, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), client.Stats().BytesUploaded)
+
+	client = NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	_, err = client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt"})
+	require.Error(t, err)
+	assert.Zero(t, client.Stats().BytesUploaded)
+}
@@ -0,0 +1,95 @@
+package lingstorage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// fileForm multipart upload form whose file part is read from disk on demand.
+// Only the part headers and form fields are held in memory
+type fileForm struct {
+	head, tail []byte // form before and after the file data
+	file       *os.File
+	size       int64
+	ctx        context.Context
+	req        *UploadRequest
+}
+
+// body new reader over the whole form. The file is read through a section
+// reader, so every body starts at the beginning of the file and replays on retries
+func (f *fileForm) body() io.ReadCloser {
+	data := &progressReader{
+		reader:   io.NewSectionReader(f.file, 0, f.size),
+		total:    f.size,
+		callback: f.req.OnProgress,
+		check:    f.req.OnProgressCheck,
+		ctx:      f.ctx,
+	}
+	return io.NopCloser(io.MultiReader(bytes.NewReader(f.head), data, bytes.NewReader(f.tail)))
+}
+
+func (f *fileForm) getBody() (io.ReadCloser, error) {
+	return f.body(), nil
+}
+
+// uploadFile upload a regular file without copying its content into memory
+func (c *Client) uploadFile(file *os.File, filename string, size int64, req *UploadRequest) (_ *UploadResult, err error) {
+	filename, req, err = c.sanitizeUpload(filename, req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, op := c.startOperation("Upload", req.Bucket, req.Key)
+	defer func() { c.endOperation(op, err) }()
+	if _, err := c.runPreUploadHooks(req, filename, io.NewSectionReader(file, 0, size)); err != nil {
+		return nil, err
+	}
+	if _, err := c.checkUploadSize(filename, size, nil); err != nil {
+		return nil, err
+	}
+	if err := validateUploadRequest(req); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if _, err := writer.CreateFormFile("file", filename); err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	split := buf.Len()
+	if err := writeUploadFields(writer, req); err != nil {
+		return nil, err
+	}
+	form := &fileForm{
+		head: buf.Bytes()[:split],
+		tail: buf.Bytes()[split:],
+		file: file,
+		size: size,
+		ctx:  ctx,
+		req:  req,
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL("/api/public/upload"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Body = form.body()
+	httpReq.GetBody = form.getBody
+	httpReq.ContentLength = int64(len(form.head)) + size + int64(len(form.tail))
+	httpReq.Header.Set(constants.CONTENT_TYPE, writer.FormDataContentType())
+	if err := c.setHeaders(httpReq, nil); err != nil {
+		return nil, err
+	}
+	result, err := c.sendUpload(ctx, httpReq, req)
+	if err != nil {
+		return nil, err
+	}
+	c.recordBytes(ctx, DirectionUpload, size)
+	return result, nil
+}
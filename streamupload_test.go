@@ -0,0 +1,92 @@
+package lingstorage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadFileStreams(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<20) // 16MB
+	path := filepath.Join(t.TempDir(), "big.bin")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	want := sha256.Sum256(content)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		reader, err := r.MultipartReader()
+		require.NoError(t, err)
+		fields := map[string]string{}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			if part.FormName() == "file" {
+				assert.Equal(t, "big.bin", part.FileName())
+				h := sha256.New()
+				io.Copy(h, part)
+				assert.Equal(t, want[:], h.Sum(nil))
+				continue
+			}
+			value, _ := io.ReadAll(part)
+			fields[part.FormName()] = string(value)
+		}
+		assert.Equal(t, "bkt", fields["bucket"])
+		assert.Equal(t, "big.bin", fields["key"])
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"key":"big.bin","size":16777216}}`))
+	}))
+	defer server.Close()
+
+	var progressed int64
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: 1})
+	result, err := client.UploadFile(&UploadRequest{
+		FilePath:   path,
+		Bucket:     "bkt",
+		Key:        "big.bin",
+		OnProgress: func(uploaded, total int64) { progressed = uploaded },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "big.bin", result.Key)
+	assert.Equal(t, 2, attempts)
+	assert.EqualValues(t, len(content), progressed)
+	assert.EqualValues(t, len(content), client.Stats().BytesUploaded)
+}
+
+func TestUploadFileMemory(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 32<<20)
+	path := filepath.Join(t.TempDir(), "big.bin")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	content = nil
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"success":true,"data":{"key":"big.bin"}}`))
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	_, err := client.UploadFile(&UploadRequest{FilePath: path, Bucket: "bkt", Key: "big.bin"})
+	require.NoError(t, err)
+	runtime.ReadMemStats(&after)
+	// 文件内容不经过内存缓冲
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(8<<20))
+}
@@ -0,0 +1,124 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ImageStyle named server side image processing pipeline
+type ImageStyle struct {
+	Name      string    `json:"name"`
+	Pipeline  string    `json:"pipeline"` // processing steps, see ImageURLBuilder.Pipeline
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var styleNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// validateStyleName style names are 1-64 letters, digits, '_' or '-'
+func validateStyleName(name string) error {
+	if !styleNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid style name %q", name)
+	}
+	return nil
+}
+
+// WithStyle apply a named image style to the URL
+func WithStyle(name string) URLOption {
+	return func(q url.Values) {
+		q.Set("style", name)
+	}
+}
+
+// Pipeline processing steps of the builder, to save as an ImageStyle
+func (b *ImageURLBuilder) Pipeline() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return strings.Join(b.steps, "/"), nil
+}
+
+// Style apply a named image style, later steps run after the style
+func (b *ImageURLBuilder) Style(name string) *ImageURLBuilder {
+	if err := validateStyleName(name); err != nil {
+		return b.fail("%w", err)
+	}
+	return b.add("style," + name)
+}
+
+// CreateStyle create a named image style
+func (c *Client) CreateStyle(name, pipeline string) (_ *ImageStyle, err error) {
+	return c.saveStyle("CreateStyle", "POST", name, pipeline)
+}
+
+// UpdateStyle replace the pipeline of an image style
+func (c *Client) UpdateStyle(name, pipeline string) (_ *ImageStyle, err error) {
+	return c.saveStyle("UpdateStyle", "PUT", name, pipeline)
+}
+
+func (c *Client) saveStyle(operation, method, name, pipeline string) (_ *ImageStyle, err error) {
+	ctx, op := c.startOperation(operation, "", name)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateStyleName(name); err != nil {
+		return nil, err
+	}
+	if pipeline == "" {
+		return nil, fmt.Errorf("style pipeline is empty")
+	}
+	path := "/api/public/styles"
+	if method == "PUT" {
+		path += "/" + name
+	}
+
+	body := map[string]string{
+		"name":     name,
+		"pipeline": pipeline,
+	}
+	var result ImageStyle
+	if err := c.call(ctx, method, path, body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetStyle get an image style
+func (c *Client) GetStyle(name string) (_ *ImageStyle, err error) {
+	ctx, op := c.startOperation("GetStyle", "", name)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateStyleName(name); err != nil {
+		return nil, err
+	}
+	var result ImageStyle
+	if err := c.call(ctx, "GET", fmt.Sprintf("/api/public/styles/%s", name), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListStyles list image styles
+func (c *Client) ListStyles() (_ []ImageStyle, err error) {
+	ctx, op := c.startOperation("ListStyles", "", "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Styles []ImageStyle `json:"styles"`
+	}
+	if err := c.call(ctx, "GET", "/api/public/styles", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Styles, nil
+}
+
+// DeleteStyle delete an image style
+func (c *Client) DeleteStyle(name string) (err error) {
+	ctx, op := c.startOperation("DeleteStyle", "", name)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateStyleName(name); err != nil {
+		return err
+	}
+	return c.call(ctx, "DELETE", fmt.Sprintf("/api/public/styles/%s", name), nil, nil)
+}
@@ -0,0 +1,78 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageStyles(t *testing.T) {
+	styles := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/public/styles",
+			r.Method == "PUT" && r.URL.Path == "/api/public/styles/thumb":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			styles[body["name"]] = body["pipeline"]
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": body})
+		case r.Method == "GET" && r.URL.Path == "/api/public/styles":
+			list := []map[string]string{}
+			for name, pipeline := range styles {
+				list = append(list, map[string]string{"name": name, "pipeline": pipeline})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"styles": list}})
+		case r.Method == "GET" && r.URL.Path == "/api/public/styles/thumb":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"name": "thumb", "pipeline": styles["thumb"]}})
+		case r.Method == "DELETE" && r.URL.Path == "/api/public/styles/thumb":
+			delete(styles, "thumb")
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/api/public/files/photos/a.jpg/url":
+			assert.Equal(t, "thumb", r.URL.Query().Get("style"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"url": "https://cdn/a.jpg?style=thumb"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	pipeline, err := client.ImageURL("", "").Resize(200, 200).Format(FormatWebP).Pipeline()
+	require.NoError(t, err)
+
+	style, err := client.CreateStyle("thumb", pipeline)
+	require.NoError(t, err)
+	assert.Equal(t, "resize,w_200,h_200/format,webp", style.Pipeline)
+
+	_, err = client.UpdateStyle("thumb", "resize,w_100")
+	require.NoError(t, err)
+	style, err = client.GetStyle("thumb")
+	require.NoError(t, err)
+	assert.Equal(t, "resize,w_100", style.Pipeline)
+
+	list, err := client.ListStyles()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	url, err := client.GetFileURL("photos", "a.jpg", time.Hour, WithStyle("thumb"))
+	require.NoError(t, err)
+	assert.Contains(t, url, "style=thumb")
+
+	require.NoError(t, client.DeleteStyle("thumb"))
+	assert.Empty(t, styles)
+
+	_, err = client.CreateStyle("bad name", pipeline)
+	assert.Error(t, err)
+}
+
+func TestImageURLStyle(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://storage.example.com", APIKey: "test-key"})
+	u, err := client.ImageURL("photos", "a.jpg").Style("thumb").Quality(90).URL()
+	require.NoError(t, err)
+	assert.Contains(t, u, "x-process=style%2Cthumb%2Fquality%2Cq_90")
+}
@@ -0,0 +1,183 @@
+package lingstorage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// SymlinkPolicy how UploadDirectory and Sync treat symbolic links
+type SymlinkPolicy string
+
+const (
+	// SymlinkSkip leave links out and report them in Skipped, the default
+	SymlinkSkip SymlinkPolicy = "skip"
+	// SymlinkFollow upload the file or directory a link points to under the
+	// link path. Broken links and links back into their own ancestors are skipped
+	SymlinkFollow SymlinkPolicy = "follow"
+	// SymlinkStore upload links as empty objects whose MetaSymlinkTarget
+	// metadata holds the link target
+	SymlinkStore SymlinkPolicy = "store"
+)
+
+// MetaSymlinkTarget metadata key of the link target of objects stored by SymlinkStore
+const MetaSymlinkTarget = "x-ling-symlink-target"
+
+// SkippedFile local file left out of a directory upload or sync, such as a
+// socket, device, symbolic link or unreadable entry
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func validateSymlinkPolicy(policy SymlinkPolicy) error {
+	switch policy {
+	case "", SymlinkSkip, SymlinkFollow, SymlinkStore:
+		return nil
+	}
+	return fmt.Errorf("unsupported symlink policy: %s", policy)
+}
+
+// localWalker collect the files below a root. Entries that cannot be uploaded
+// are skipped with a reason instead of failing the walk
+type localWalker struct {
+	filter    *pathFilter
+	policy    SymlinkPolicy
+	files     []localFile
+	skipped   []SkippedFile
+	ancestors map[string]bool // real paths of the directories being walked
+}
+
+// walkLocal files below root in lexical order, with the entries skipped by policy
+func walkLocal(root string, filter *pathFilter, policy SymlinkPolicy) ([]localFile, []SkippedFile, error) {
+	w := &localWalker{filter: filter, policy: policy, ancestors: make(map[string]bool)}
+	if err := w.walkDir(root, ""); err != nil {
+		return nil, nil, fmt.Errorf("failed to walk local directory: %w", err)
+	}
+	return w.files, w.skipped, nil
+}
+
+func (w *localWalker) skip(p, reason string) {
+	w.skipped = append(w.skipped, SkippedFile{Path: p, Reason: reason})
+}
+
+// walkDir walk dir whose slash separated path relative to the root is rel.
+// Only a failure to read the root is returned
+func (w *localWalker) walkDir(dir, rel string) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		real = dir
+	}
+	w.ancestors[real] = true
+	defer delete(w.ancestors, real)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel == "" {
+			return err
+		}
+		w.skip(dir, fmt.Sprintf("unreadable directory: %v", err))
+		return nil
+	}
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+		r := path.Join(rel, entry.Name())
+		switch mode := entry.Type(); {
+		case mode&fs.ModeSymlink != 0:
+			w.walkLink(p, r)
+		case mode.IsDir():
+			if !w.filter.skipDir(r) {
+				w.walkDir(p, r)
+			}
+		default:
+			info, err := entry.Info()
+			if err != nil {
+				w.skip(p, fmt.Sprintf("unreadable file: %v", err))
+				continue
+			}
+			w.addFile(p, r, info)
+		}
+	}
+	return nil
+}
+
+// walkLink handle the symbolic link p by the policy
+func (w *localWalker) walkLink(p, rel string) {
+	switch w.policy {
+	case SymlinkFollow:
+		info, err := os.Stat(p)
+		if err != nil {
+			if w.filter.keep(rel) {
+				w.skip(p, "broken symbolic link")
+			}
+			return
+		}
+		if !info.IsDir() {
+			w.addFile(p, rel, info)
+			return
+		}
+		if w.filter.skipDir(rel) {
+			return
+		}
+		if real, err := filepath.EvalSymlinks(p); err == nil && w.ancestors[real] {
+			w.skip(p, "symbolic link cycle")
+			return
+		}
+		w.walkDir(p, rel)
+	case SymlinkStore:
+		if !w.filter.keep(rel) {
+			return
+		}
+		target, err := os.Readlink(p)
+		if err != nil {
+			w.skip(p, fmt.Sprintf("unreadable symbolic link: %v", err))
+			return
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			w.skip(p, fmt.Sprintf("unreadable symbolic link: %v", err))
+			return
+		}
+		w.files = append(w.files, localFile{path: p, rel: rel, info: info, link: target})
+	default:
+		if w.filter.keep(rel) {
+			w.skip(p, "symbolic link")
+		}
+	}
+}
+
+// addFile add a regular file, sockets, devices and pipes are skipped
+func (w *localWalker) addFile(p, rel string, info fs.FileInfo) {
+	if !w.filter.keep(rel) {
+		return
+	}
+	if !info.Mode().IsRegular() {
+		w.skip(p, fmt.Sprintf("special file (%s)", info.Mode().Type()))
+		return
+	}
+	w.files = append(w.files, localFile{path: p, rel: rel, info: info})
+}
+
+// size bytes uploaded for the file, links are stored as empty objects
+func (f localFile) size() int64 {
+	if f.link != "" {
+		return 0
+	}
+	return f.info.Size()
+}
+
+// uploadLocal upload a walked file, or the link object of a stored link
+func (c *Client) uploadLocal(bucket, key string, file localFile, preserveAttributes bool) (*UploadResult, error) {
+	if file.link != "" {
+		return c.UploadBytes(&UploadBytesRequest{
+			Bucket:   bucket,
+			Key:      key,
+			Filename: path.Base(file.rel),
+			Data:     []byte{},
+			Metadata: map[string]string{MetaSymlinkTarget: file.link},
+		})
+	}
+	return c.UploadFile(&UploadRequest{FilePath: file.path, Bucket: bucket, Key: key, PreserveAttributes: preserveAttributes})
+}
@@ -0,0 +1,102 @@
+package lingstorage_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadDirectorySymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	bucket := lingstoragetest.DefaultBucket
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.txt":        "a",
+		"shared/b.txt": "b",
+	})
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(dir, "link.txt")))
+	require.NoError(t, os.Symlink("shared", filepath.Join(dir, "alias")))
+	require.NoError(t, os.Symlink("missing.txt", filepath.Join(dir, "broken.txt")))
+	// 指向祖先目录的链接形成环
+	require.NoError(t, os.Symlink("..", filepath.Join(dir, "shared", "up")))
+	// unix socket 路径长度有限, 放在短路径下
+	sockDir, err := os.MkdirTemp("", "ls")
+	require.NoError(t, err)
+	defer os.RemoveAll(sockDir)
+	listener, err := net.Listen("unix", filepath.Join(sockDir, "s.sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NoError(t, os.Symlink(filepath.Join(sockDir, "s.sock"), filepath.Join(dir, "sock")))
+
+	result, err := client.UploadDirectory(&lingstorage.UploadDirectoryRequest{Dir: dir, Bucket: bucket, Prefix: "skip"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Empty(t, result.Failed)
+	assert.Len(t, result.Skipped, 5)
+
+	result, err = client.UploadDirectory(&lingstorage.UploadDirectoryRequest{Dir: dir, Bucket: bucket, Prefix: "follow", Symlinks: lingstorage.SymlinkFollow})
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed)
+	for _, key := range []string{"follow/a.txt", "follow/link.txt", "follow/alias/b.txt", "follow/shared/b.txt"} {
+		_, ok := server.Object(bucket, key)
+		assert.True(t, ok, key)
+	}
+	reasons := map[string]string{}
+	for _, skipped := range result.Skipped {
+		reasons[filepath.Base(skipped.Path)] = skipped.Reason
+	}
+	assert.Equal(t, "broken symbolic link", reasons["broken.txt"])
+	assert.Equal(t, "symbolic link cycle", reasons["up"])
+	assert.Contains(t, reasons["sock"], "special file")
+
+	result, err = client.UploadDirectory(&lingstorage.UploadDirectoryRequest{Dir: dir, Bucket: bucket, Prefix: "store", Symlinks: lingstorage.SymlinkStore})
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed)
+	obj, ok := server.Object(bucket, "store/link.txt")
+	require.True(t, ok)
+	assert.Empty(t, obj.Data)
+	assert.Equal(t, "a.txt", obj.Metadata[lingstorage.MetaSymlinkTarget])
+	obj, ok = server.Object(bucket, "store/broken.txt")
+	require.True(t, ok)
+	assert.Equal(t, "missing.txt", obj.Metadata[lingstorage.MetaSymlinkTarget])
+
+	_, err = client.UploadDirectory(&lingstorage.UploadDirectoryRequest{Dir: dir, Bucket: bucket, Symlinks: "copy"})
+	assert.Error(t, err)
+}
+
+func TestSyncStoredSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "a"})
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(dir, "link.txt")))
+	req := &lingstorage.SyncRequest{LocalDir: dir, Bucket: lingstoragetest.DefaultBucket, Symlinks: lingstorage.SymlinkStore}
+
+	report, err := client.Sync(req)
+	require.NoError(t, err)
+	assert.Len(t, report.Created, 2)
+	assert.Empty(t, report.Failed)
+
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Zero(t, report.Changes())
+	assert.Equal(t, 2, report.Unchanged)
+}
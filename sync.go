@@ -0,0 +1,393 @@
+package lingstorage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sync compare modes
+const (
+	// CompareSize transfer when sizes differ
+	CompareSize = "size"
+	// CompareMtime transfer when sizes differ or the source is newer
+	CompareMtime = "mtime"
+	// CompareHash transfer when the md5 differs from the ETag, falls back to size
+	// for multipart ETags
+	CompareHash = "hash"
+)
+
+// DefaultSyncConcurrency parallel transfers of Sync and SyncDown
+const DefaultSyncConcurrency = 4
+
+// SyncAction change applied by a sync
+type SyncAction string
+
+const (
+	SyncCreate SyncAction = "create"
+	SyncUpdate SyncAction = "update"
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncRequest sync a local directory to a bucket prefix
+type SyncRequest struct {
+	LocalDir    string
+	Bucket      string
+	Prefix      string // remote key prefix, "/" is appended if missing
+	Delete      bool   // delete remote objects missing locally
+	Compare     string // size, mtime or hash, default size
+	DryRun      bool   // report the changes without applying them
+	Concurrency int    // parallel uploads, default 4
+	// Include glob patterns of files to sync, e.g. **/*.jpg, empty syncs all files
+	Include []string
+	// Exclude gitignore style patterns of files to skip, excluded remote objects
+	// are never deleted. Rules of IgnoreFile below LocalDir are added
+	Exclude    []string
+	IgnoreFile string // default .lingignore, a missing file is fine
+	// ManifestPath write a manifest of created and updated objects, csv by .csv
+	// extension, else json. Not written on dry runs
+	ManifestPath string
+	// PreserveAttributes store file modification times and permissions as
+	// metadata, see DownloadFileWithAttributes
+	PreserveAttributes bool
+	// Symlinks how symbolic links are synced, default SymlinkSkip
+	Symlinks SymlinkPolicy
+	// HashWorkers files hashed in parallel by CompareHash, default the number of CPUs
+	HashWorkers int
+	// HashCache file caching the md5 of local files by path, size and mtime,
+	// so unchanged files are not hashed again by later runs. Empty disables it
+	HashCache string
+	// State records the size, mtime, hash and remote ETag of every synced
+	// file. Once a state is saved, later runs compare against it instead of
+	// listing the bucket and skip files whose size and mtime are unchanged;
+	// changes made to the bucket by others are then not noticed
+	State    SyncStateStore
+	OnChange func(change SyncChange)
+}
+
+// SyncChange one planned or applied change
+type SyncChange struct {
+	Action SyncAction
+	Key    string
+	Path   string // local path
+	Size   int64
+	Err    error // set if the change failed
+}
+
+// SyncReport changes of a sync, failed changes are listed in Failed only
+type SyncReport struct {
+	Created   []SyncChange
+	Updated   []SyncChange
+	Deleted   []SyncChange
+	Failed    []SyncChange
+	Skipped   []SkippedFile // local entries left out, such as sockets and devices
+	Unchanged int
+	Bytes     int64 // bytes transferred
+	DryRun    bool
+	Duration  time.Duration
+}
+
+// Changes number of applied or planned changes
+func (r *SyncReport) Changes() int {
+	return len(r.Created) + len(r.Updated) + len(r.Deleted)
+}
+
+// add record a finished change
+func (r *SyncReport) add(change SyncChange) {
+	if change.Err != nil {
+		r.Failed = append(r.Failed, change)
+		return
+	}
+	switch change.Action {
+	case SyncCreate:
+		r.Created = append(r.Created, change)
+	case SyncUpdate:
+		r.Updated = append(r.Updated, change)
+	case SyncDelete:
+		r.Deleted = append(r.Deleted, change)
+	}
+	if change.Action != SyncDelete {
+		r.Bytes += change.Size
+	}
+}
+
+// localFile regular file below the sync root
+type localFile struct {
+	path string
+	rel  string // slash separated path relative to the root
+	info fs.FileInfo
+	link string // target of a link stored by SymlinkStore
+}
+
+// Sync upload new and changed files of a local directory, optionally deleting
+// remote extras. Per file failures are reported in SyncReport.Failed, the
+// returned error is set only if the sync could not run
+func (c *Client) Sync(req *SyncRequest) (_ *SyncReport, err error) {
+	ctx, op := c.startOperation("Sync", req.Bucket, req.Prefix)
+	defer func() { c.endOperation(op, err) }()
+	start := time.Now()
+	if err := validateCompare(req.Compare); err != nil {
+		return nil, err
+	}
+	if err := validateSymlinkPolicy(req.Symlinks); err != nil {
+		return nil, err
+	}
+	prefix := normalizePrefix(req.Prefix)
+	filter, err := newPathFilter(req.LocalDir, req.Include, req.Exclude, req.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	locals, skipped, err := walkLocal(req.LocalDir, filter, req.Symlinks)
+	if err != nil {
+		return nil, err
+	}
+	var state map[string]SyncStateEntry
+	if req.State != nil {
+		if state, err = req.State.Load(req.Bucket, prefix); err != nil {
+			return nil, err
+		}
+	}
+	var remotes map[string]FileInfo
+	if state != nil {
+		remotes = remotesFromState(state)
+	} else if remotes, err = c.WithContext(ctx).listAll(req.Bucket, prefix); err != nil {
+		return nil, err
+	}
+	filterRemotes(remotes, prefix, filter)
+	hashCache, err := loadHashCache(req.HashCache)
+	if err != nil {
+		return nil, err
+	}
+	hashes := hashForCompare(req.Compare, locals, func(local localFile) (FileInfo, bool) {
+		key := prefix + local.rel
+		if entry, ok := state[key]; ok && entry.matches(local.info) {
+			return FileInfo{}, false
+		}
+		remote, ok := remotes[key]
+		return remote, ok
+	}, req.HashWorkers, hashCache)
+
+	var changes []SyncChange
+	report := &SyncReport{DryRun: req.DryRun, Skipped: skipped}
+	localByKey := make(map[string]localFile, len(locals))
+	nextState := make(map[string]SyncStateEntry, len(locals))
+	for _, local := range locals {
+		key := prefix + local.rel
+		localByKey[key] = local
+		remote, exists := remotes[key]
+		delete(remotes, key)
+		if entry, ok := state[key]; ok && entry.matches(local.info) {
+			nextState[key] = entry
+			report.Unchanged++
+			continue
+		}
+		if !exists {
+			changes = append(changes, SyncChange{Action: SyncCreate, Key: key, Path: local.path, Size: local.size()})
+			continue
+		}
+		changed, err := localChanged(local, remote, req.Compare, hashes)
+		if err != nil {
+			report.add(SyncChange{Action: SyncUpdate, Key: key, Path: local.path, Err: err})
+			continue
+		}
+		if changed {
+			changes = append(changes, SyncChange{Action: SyncUpdate, Key: key, Path: local.path, Size: local.size()})
+		} else {
+			nextState[key] = stateEntry(local, remote.ETag, hashes)
+			report.Unchanged++
+		}
+	}
+	if req.Delete {
+		for _, key := range sortedKeys(remotes) {
+			changes = append(changes, SyncChange{Action: SyncDelete, Key: key, Size: remotes[key].Size})
+		}
+	}
+
+	client := c.WithContext(ctx)
+	var mu sync.Mutex
+	var entries []ManifestEntry
+	runChanges(changes, req.Concurrency, req.DryRun, report, req.OnChange, func(change *SyncChange) error {
+		if change.Action == SyncDelete {
+			return client.DeleteFile(req.Bucket, change.Key)
+		}
+		local := localByKey[change.Key]
+		result, err := client.uploadLocal(req.Bucket, change.Key, local, req.PreserveAttributes)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		entries = append(entries, ManifestEntry{Bucket: req.Bucket, Key: change.Key, Path: change.Path, URL: result.URL})
+		nextState[change.Key] = stateEntry(local, result.ETag, hashes)
+		mu.Unlock()
+		return nil
+	})
+	report.Duration = time.Since(start)
+	if err := hashCache.save(); err != nil {
+		return report, err
+	}
+	if req.State != nil && !req.DryRun {
+		// failed changes are retried by the next run
+		for _, change := range report.Failed {
+			if entry, ok := state[change.Key]; ok {
+				nextState[change.Key] = entry
+			}
+		}
+		if err := req.State.Save(req.Bucket, prefix, nextState); err != nil {
+			return report, err
+		}
+	}
+	if req.ManifestPath != "" && !req.DryRun {
+		if err := writeManifest(req.ManifestPath, entries); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// runChanges apply changes with bounded concurrency and collect them in report
+func runChanges(changes []SyncChange, concurrency int, dryRun bool, report *SyncReport, onChange func(SyncChange), apply func(change *SyncChange) error) {
+	if concurrency <= 0 {
+		concurrency = DefaultSyncConcurrency
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range changes {
+		change := changes[i]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if !dryRun {
+				change.Err = apply(&change)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.add(change)
+			if onChange != nil {
+				onChange(change)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, list := range [][]SyncChange{report.Created, report.Updated, report.Deleted, report.Failed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	}
+}
+
+// listAll list every object below prefix, keyed by object key
+func (c *Client) listAll(bucket, prefix string) (map[string]FileInfo, error) {
+	files := make(map[string]FileInfo)
+	marker := ""
+	for {
+		result, err := c.ListFiles(&ListFilesRequest{Bucket: bucket, Prefix: prefix, Marker: marker})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remote files: %w", err)
+		}
+		for _, file := range result.Files {
+			files[file.Key] = file
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			return files, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// localChanged compare a walked file with a remote object. A stored link
+// changes when the object is not empty or records another target
+func localChanged(local localFile, remote FileInfo, compare string, hashes map[string]hashResult) (bool, error) {
+	if local.link != "" {
+		target, listed := remote.Metadata[MetaSymlinkTarget]
+		return remote.Size != 0 || (listed && target != local.link), nil
+	}
+	return fileChanged(local.path, local.info, remote, compare, true, hashes)
+}
+
+// fileChanged compare a local file with a remote object, upload tells the
+// sync direction for mtime comparison. hashes holds precomputed md5 sums
+func fileChanged(localPath string, local fs.FileInfo, remote FileInfo, compare string, upload bool, hashes map[string]hashResult) (bool, error) {
+	if local.Size() != remote.Size {
+		return true, nil
+	}
+	switch compare {
+	case CompareMtime:
+		if upload {
+			return local.ModTime().After(remote.LastModified), nil
+		}
+		return remote.LastModified.After(local.ModTime()), nil
+	case CompareHash:
+		if !needsHash(local, remote) {
+			return false, nil
+		}
+		result, ok := hashes[localPath]
+		if !ok {
+			result.sum, result.err = fileMD5(localPath)
+		}
+		if result.err != nil {
+			return false, result.err
+		}
+		return !strings.EqualFold(result.sum, strings.Trim(remote.ETag, `"`)), nil
+	}
+	return false, nil
+}
+
+func fileMD5(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func validateCompare(compare string) error {
+	switch compare {
+	case "", CompareSize, CompareMtime, CompareHash:
+		return nil
+	}
+	return fmt.Errorf("unsupported sync compare mode: %s", compare)
+}
+
+// normalizePrefix clean key prefix ending with "/", empty stays empty
+func normalizePrefix(prefix string) string {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path.Clean(prefix), "/") + "/"
+}
+
+// filterRemotes drop objects excluded by filter, they are neither compared nor deleted
+func filterRemotes(remotes map[string]FileInfo, prefix string, filter *pathFilter) {
+	for key := range remotes {
+		if !filter.keep(strings.TrimPrefix(key, prefix)) {
+			delete(remotes, key)
+		}
+	}
+}
+
+func sortedKeys(files map[string]FileInfo) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
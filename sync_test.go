@@ -0,0 +1,128 @@
+package lingstorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+func keys(changes []lingstorage.SyncChange) []string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.Key
+	}
+	return out
+}
+
+func TestSync(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.txt":       "aaa",
+		"sub/b.txt":   "bbb",
+		"sub/c/d.txt": "ddd",
+	})
+	server.PutObject(lingstoragetest.DefaultBucket, "backup/a.txt", []byte("aaa"))
+	server.PutObject(lingstoragetest.DefaultBucket, "backup/sub/b.txt", []byte("old"))
+	server.PutObject(lingstoragetest.DefaultBucket, "backup/stale.txt", []byte("stale"))
+	server.PutObject(lingstoragetest.DefaultBucket, "other/keep.txt", []byte("keep"))
+
+	req := &lingstorage.SyncRequest{
+		LocalDir: dir,
+		Bucket:   lingstoragetest.DefaultBucket,
+		Prefix:   "backup",
+		Delete:   true,
+		Compare:  lingstorage.CompareHash,
+		DryRun:   true,
+	}
+	report, err := client.Sync(req)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []string{"backup/sub/c/d.txt"}, keys(report.Created))
+	assert.Equal(t, []string{"backup/sub/b.txt"}, keys(report.Updated))
+	assert.Equal(t, []string{"backup/stale.txt"}, keys(report.Deleted))
+	assert.Equal(t, 1, report.Unchanged)
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "backup/sub/c/d.txt")
+	assert.False(t, ok, "dry run must not upload")
+
+	req.DryRun = false
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Changes())
+	assert.Empty(t, report.Failed)
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "backup/sub/b.txt")
+	require.True(t, ok)
+	assert.Equal(t, "bbb", string(obj.Data))
+	_, ok = server.Object(lingstoragetest.DefaultBucket, "backup/stale.txt")
+	assert.False(t, ok)
+	_, ok = server.Object(lingstoragetest.DefaultBucket, "other/keep.txt")
+	assert.True(t, ok, "objects outside the prefix are untouched")
+
+	// 再次同步没有变化
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Changes())
+	assert.Equal(t, 3, report.Unchanged)
+}
+
+func TestSyncInvalidCompare(t *testing.T) {
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: "http://127.0.0.1:1"})
+	_, err := client.Sync(&lingstorage.SyncRequest{LocalDir: t.TempDir(), Compare: "checksum"})
+	assert.Error(t, err)
+}
+
+func TestSyncExclude(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".lingignore":   "*.log\n",
+		"a.txt":         "aaa",
+		"debug.log":     "log",
+		"tmp/scratch":   "tmp",
+		"photos/01.jpg": "jpg",
+	})
+	// 被排除的远程对象不会被删除
+	server.PutObject(lingstoragetest.DefaultBucket, "backup/server.log", []byte("remote log"))
+
+	report, err := client.Sync(&lingstorage.SyncRequest{
+		LocalDir: dir,
+		Bucket:   lingstoragetest.DefaultBucket,
+		Prefix:   "backup",
+		Delete:   true,
+		Exclude:  []string{"tmp/"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup/a.txt", "backup/photos/01.jpg"}, keys(report.Created))
+	assert.Empty(t, report.Deleted)
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "backup/server.log")
+	assert.True(t, ok)
+
+	restore := t.TempDir()
+	report, err = client.SyncDown(&lingstorage.SyncDownRequest{
+		Bucket:   lingstoragetest.DefaultBucket,
+		Prefix:   "backup",
+		LocalDir: restore,
+		Include:  []string{"photos/**"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup/photos/01.jpg"}, keys(report.Created))
+}
@@ -0,0 +1,146 @@
+package lingstorage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncDownRequest mirror a bucket prefix to a local directory
+type SyncDownRequest struct {
+	Bucket      string
+	Prefix      string // remote key prefix, "/" is appended if missing
+	LocalDir    string
+	Delete      bool   // delete local files missing remotely
+	Compare     string // size, mtime or hash, default size
+	DryRun      bool   // report the changes without applying them
+	Concurrency int    // parallel downloads, default 4
+	// Include glob patterns of files to sync, e.g. **/*.jpg, empty syncs all files
+	Include []string
+	// Exclude gitignore style patterns of files to skip, excluded local files
+	// are never deleted. Rules of IgnoreFile below LocalDir are added
+	Exclude    []string
+	IgnoreFile string // default .lingignore, a missing file is fine
+	// HashWorkers files hashed in parallel by CompareHash, default the number of CPUs
+	HashWorkers int
+	// HashCache file caching the md5 of local files by path, size and mtime,
+	// see SyncRequest.HashCache
+	HashCache string
+	OnChange  func(change SyncChange)
+}
+
+// SyncDown download new and changed objects below a prefix, optionally deleting
+// local extras. Downloaded files get the remote modification time, so mtime
+// comparison stays stable across runs. Per file failures are reported in
+// SyncReport.Failed, the returned error is set only if the sync could not run
+func (c *Client) SyncDown(req *SyncDownRequest) (_ *SyncReport, err error) {
+	ctx, op := c.startOperation("SyncDown", req.Bucket, req.Prefix)
+	defer func() { c.endOperation(op, err) }()
+	start := time.Now()
+	if err := validateCompare(req.Compare); err != nil {
+		return nil, err
+	}
+	prefix := normalizePrefix(req.Prefix)
+
+	if err := os.MkdirAll(req.LocalDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create local directory: %w", err)
+	}
+	filter, err := newPathFilter(req.LocalDir, req.Include, req.Exclude, req.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+	locals, _, err := walkLocal(req.LocalDir, filter, SymlinkSkip)
+	if err != nil {
+		return nil, err
+	}
+	localByRel := make(map[string]localFile, len(locals))
+	for _, local := range locals {
+		localByRel[local.rel] = local
+	}
+	remotes, err := c.WithContext(ctx).listAll(req.Bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	filterRemotes(remotes, prefix, filter)
+	hashCache, err := loadHashCache(req.HashCache)
+	if err != nil {
+		return nil, err
+	}
+	hashes := hashForCompare(req.Compare, locals, func(local localFile) (FileInfo, bool) {
+		remote, ok := remotes[prefix+local.rel]
+		return remote, ok
+	}, req.HashWorkers, hashCache)
+
+	var changes []SyncChange
+	report := &SyncReport{DryRun: req.DryRun}
+	for _, key := range sortedKeys(remotes) {
+		remote := remotes[key]
+		rel := strings.TrimPrefix(key, prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			// directory marker
+			continue
+		}
+		localPath, err := localPathOf(req.LocalDir, rel)
+		if err != nil {
+			report.add(SyncChange{Action: SyncCreate, Key: key, Err: err})
+			continue
+		}
+		local, exists := localByRel[rel]
+		delete(localByRel, rel)
+		if !exists {
+			changes = append(changes, SyncChange{Action: SyncCreate, Key: key, Path: localPath, Size: remote.Size})
+			continue
+		}
+		changed, err := fileChanged(local.path, local.info, remote, req.Compare, false, hashes)
+		if err != nil {
+			report.add(SyncChange{Action: SyncUpdate, Key: key, Path: localPath, Err: err})
+			continue
+		}
+		if changed {
+			changes = append(changes, SyncChange{Action: SyncUpdate, Key: key, Path: localPath, Size: remote.Size})
+		} else {
+			report.Unchanged++
+		}
+	}
+	if req.Delete {
+		for _, local := range localByRel {
+			changes = append(changes, SyncChange{Action: SyncDelete, Key: prefix + local.rel, Path: local.path, Size: local.info.Size()})
+		}
+	}
+
+	client := c.WithContext(ctx)
+	runChanges(changes, req.Concurrency, req.DryRun, report, req.OnChange, func(change *SyncChange) error {
+		if change.Action == SyncDelete {
+			if err := os.Remove(change.Path); err != nil {
+				return fmt.Errorf("failed to delete local file: %w", err)
+			}
+			return nil
+		}
+		if err := client.DownloadFile(req.Bucket, change.Key, change.Path); err != nil {
+			return err
+		}
+		if modTime := remotes[change.Key].LastModified; !modTime.IsZero() {
+			if err := os.Chtimes(change.Path, modTime, modTime); err != nil {
+				return fmt.Errorf("failed to set modification time: %w", err)
+			}
+		}
+		return nil
+	})
+	report.Duration = time.Since(start)
+	if err := hashCache.save(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// localPathOf local path of a relative key, keys escaping root are rejected
+func localPathOf(root, rel string) (string, error) {
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	r, err := filepath.Rel(root, p)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("object key %q escapes the local directory", rel)
+	}
+	return p, nil
+}
@@ -0,0 +1,67 @@
+package lingstorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDown(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.PutObject(lingstoragetest.DefaultBucket, "assets/app.js", []byte("console.log(1)"))
+	server.PutObject(lingstoragetest.DefaultBucket, "assets/css/site.css", []byte("body{}"))
+	server.PutObject(lingstoragetest.DefaultBucket, "assets/../evil.txt", []byte("evil"))
+	server.PutObject(lingstoragetest.DefaultBucket, "other.txt", []byte("other"))
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app.js":     "old",
+		"stale.html": "stale",
+	})
+
+	req := &lingstorage.SyncDownRequest{
+		Bucket:   lingstoragetest.DefaultBucket,
+		Prefix:   "assets/",
+		LocalDir: dir,
+		Delete:   true,
+		Compare:  lingstorage.CompareMtime,
+		DryRun:   true,
+	}
+	report, err := client.SyncDown(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"assets/css/site.css"}, keys(report.Created))
+	assert.Equal(t, []string{"assets/app.js"}, keys(report.Updated))
+	assert.Equal(t, []string{"assets/stale.html"}, keys(report.Deleted))
+	require.Len(t, report.Failed, 1, "keys escaping the directory are rejected")
+	_, err = os.Stat(filepath.Join(dir, "css", "site.css"))
+	assert.True(t, os.IsNotExist(err), "dry run must not download")
+
+	req.DryRun = false
+	report, err = client.SyncDown(req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Changes())
+	data, err := os.ReadFile(filepath.Join(dir, "css", "site.css"))
+	require.NoError(t, err)
+	assert.Equal(t, "body{}", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "app.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "console.log(1)", string(data))
+	_, err = os.Stat(filepath.Join(dir, "stale.html"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "evil.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 修改时间与远端一致，再次同步没有变化
+	report, err = client.SyncDown(req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Changes())
+	assert.Equal(t, 2, report.Unchanged)
+}
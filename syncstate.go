@@ -0,0 +1,121 @@
+package lingstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// SyncStateEntry state of a local file when it was last synced
+type SyncStateEntry struct {
+	Size  int64     `json:"size"`
+	MTime time.Time `json:"mtime"`
+	MD5   string    `json:"md5,omitempty"`  // set when the file was hashed
+	ETag  string    `json:"etag,omitempty"` // of the remote object
+}
+
+// matches the file still has the recorded size and modification time
+func (e SyncStateEntry) matches(info fs.FileInfo) bool {
+	return e.Size == info.Size() && e.MTime.Equal(info.ModTime())
+}
+
+// SyncStateStore persistent record of the files synced to a bucket prefix,
+// keyed by object key. Implementations may keep it in a file or a database
+type SyncStateStore interface {
+	// Load state of the last sync, nil when none was saved
+	Load(bucket, prefix string) (map[string]SyncStateEntry, error)
+	// Save replace the state of bucket and prefix
+	Save(bucket, prefix string, entries map[string]SyncStateEntry) error
+}
+
+// FileStateStore SyncStateStore in a JSON file, which may hold the state of
+// several bucket prefixes
+type FileStateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStateStore state store in the file at path, created on first save
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+func stateScope(bucket, prefix string) string {
+	return bucket + "/" + prefix
+}
+
+// Load state of the last sync of bucket and prefix
+func (s *FileStateStore) Load(bucket, prefix string) (map[string]SyncStateEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return scopes[stateScope(bucket, prefix)], nil
+}
+
+// Save replace the state of bucket and prefix, keeping other prefixes
+func (s *FileStateStore) Save(bucket, prefix string, entries map[string]SyncStateEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scopes, err := s.read()
+	if err != nil {
+		return err
+	}
+	if scopes == nil {
+		scopes = make(map[string]map[string]SyncStateEntry)
+	}
+	scopes[stateScope(bucket, prefix)] = entries
+	data, err := json.Marshal(scopes)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %w", err)
+	}
+	if err := writeFile(s.path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStateStore) read() (map[string]map[string]SyncStateEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	var scopes map[string]map[string]SyncStateEntry
+	if err := json.Unmarshal(data, &scopes); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", s.path, err)
+	}
+	return scopes, nil
+}
+
+// remotesFromState remote objects as recorded by the last sync, used instead
+// of listing the bucket
+func remotesFromState(state map[string]SyncStateEntry) map[string]FileInfo {
+	remotes := make(map[string]FileInfo, len(state))
+	for key, entry := range state {
+		etag := entry.ETag
+		if etag == "" {
+			etag = entry.MD5
+		}
+		remotes[key] = FileInfo{Key: key, Size: entry.Size, ETag: etag, LastModified: entry.MTime}
+	}
+	return remotes
+}
+
+// stateEntry state of local after a sync left the remote object with etag
+func stateEntry(local localFile, etag string, hashes map[string]hashResult) SyncStateEntry {
+	entry := SyncStateEntry{Size: local.info.Size(), MTime: local.info.ModTime(), ETag: etag}
+	if result, ok := hashes[local.path]; ok && result.err == nil {
+		entry.MD5 = result.sum
+	}
+	return entry
+}
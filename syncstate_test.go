@@ -0,0 +1,74 @@
+package lingstorage_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncState(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	var lists int32
+	client := server.Client(lingstorage.WithRequestHook(func(r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/files") {
+			atomic.AddInt32(&lists, 1)
+		}
+	}))
+	bucket := lingstoragetest.DefaultBucket
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "a", "b.txt": "b", "sub/c.txt": "c"})
+	store := lingstorage.NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+	req := &lingstorage.SyncRequest{LocalDir: dir, Bucket: bucket, Prefix: "backup", Delete: true, Compare: lingstorage.CompareHash, State: store}
+
+	report, err := client.Sync(req)
+	require.NoError(t, err)
+	assert.Len(t, report.Created, 3)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lists))
+	state, err := store.Load(bucket, "backup/")
+	require.NoError(t, err)
+	require.Len(t, state, 3)
+	assert.NotEmpty(t, state["backup/a.txt"].ETag)
+
+	// 有状态后不再列举也不再计算哈希
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Unchanged)
+	assert.Zero(t, report.Changes())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&lists))
+
+	// 只改时间不改内容: 哈希相同, 不上传
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "a.txt"), later, later))
+	writeTree(t, dir, map[string]string{"b.txt": "bb", "d.txt": "d"})
+	require.NoError(t, os.Remove(filepath.Join(dir, "sub", "c.txt")))
+	report, err = client.Sync(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup/d.txt"}, keys(report.Created))
+	assert.Equal(t, []string{"backup/b.txt"}, keys(report.Updated))
+	assert.Equal(t, []string{"backup/sub/c.txt"}, keys(report.Deleted))
+	assert.Equal(t, 1, report.Unchanged)
+	_, ok := server.Object(bucket, "backup/sub/c.txt")
+	assert.False(t, ok)
+
+	state, err = store.Load(bucket, "backup/")
+	require.NoError(t, err)
+	assert.Len(t, state, 3)
+	assert.True(t, state["backup/a.txt"].MTime.Equal(later))
+	assert.NotEmpty(t, state["backup/a.txt"].MD5)
+
+	// 其他前缀的状态互不影响
+	other, err := store.Load(bucket, "other/")
+	require.NoError(t, err)
+	assert.Nil(t, other)
+}
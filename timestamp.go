@@ -0,0 +1,94 @@
+package lingstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts layouts of string timestamps, tried in order. Layouts
+// without a zone are read as UTC
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// parseTimestamp parse a JSON timestamp the way different deployments send it:
+// RFC 3339 and similar strings, or unix epoch numbers in seconds, milliseconds,
+// microseconds or nanoseconds, bare or quoted. null and "" are the zero time
+func parseTimestamp(data []byte) (time.Time, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return time.Time{}, nil
+	}
+	s := string(data)
+	if data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, err
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return time.Time{}, nil
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return epochTime(n), nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		// fractional epochs are seconds
+		return time.Unix(0, int64(f*1e9)).UTC(), nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp %s", data)
+}
+
+// epochTime unix time whose unit is guessed from the magnitude: seconds
+// below 1e11 (the year 5138), then milliseconds, microseconds and nanoseconds
+func epochTime(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0).UTC()
+	case abs < 1e14:
+		return time.UnixMilli(n).UTC()
+	case abs < 1e17:
+		return time.UnixMicro(n).UTC()
+	}
+	return time.Unix(0, n).UTC()
+}
+
+// UnmarshalJSON decode FileInfo accepting any timestamp format of parseTimestamp
+// for lastModified, which would otherwise fail or stay zero
+func (f *FileInfo) UnmarshalJSON(data []byte) error {
+	type fileInfo FileInfo
+	aux := struct {
+		*fileInfo
+		LastModified json.RawMessage `json:"lastModified"`
+	}{fileInfo: (*fileInfo)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t, err := parseTimestamp(aux.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid lastModified of %s: %w", f.Key, err)
+	}
+	f.LastModified = t
+	return nil
+}
@@ -0,0 +1,57 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 3, 1, 8, 30, 15, 0, time.UTC)
+	for _, raw := range []string{
+		`"2024-03-01T08:30:15Z"`,
+		`"2024-03-01T16:30:15+08:00"`,
+		`"2024-03-01T08:30:15"`,
+		`"2024-03-01 08:30:15"`,
+		`"Fri, 01 Mar 2024 08:30:15 UTC"`,
+		`1709281815`,
+		`1709281815000`,
+		`"1709281815000"`,
+		`1709281815000000`,
+		`1709281815000000000`,
+		`1709281815.0`,
+	} {
+		got, err := parseTimestamp([]byte(raw))
+		require.NoError(t, err, raw)
+		assert.True(t, want.Equal(got), "%s parsed as %s", raw, got)
+	}
+
+	for _, raw := range []string{``, `null`, `""`, `0`} {
+		got, err := parseTimestamp([]byte(raw))
+		require.NoError(t, err)
+		assert.True(t, got.IsZero(), raw)
+	}
+	_, err := parseTimestamp([]byte(`"yesterday"`))
+	assert.Error(t, err)
+}
+
+func TestFileInfoEpochLastModified(t *testing.T) {
+	var result ListFilesResult
+	require.NoError(t, json.Unmarshal([]byte(`{"files":[
+		{"key":"a.txt","size":3,"lastModified":1709281815123,"etag":"e"},
+		{"key":"b.txt","lastModified":"2024-03-01T08:30:15Z"},
+		{"key":"c.txt"}
+	]}`), &result))
+	require.Len(t, result.Files, 3)
+	assert.Equal(t, int64(1709281815123), result.Files[0].LastModified.UnixMilli())
+	assert.Equal(t, int64(3), result.Files[0].Size)
+	assert.Equal(t, "e", result.Files[0].ETag)
+	assert.Equal(t, int64(1709281815), result.Files[1].LastModified.Unix())
+	assert.True(t, result.Files[2].LastModified.IsZero())
+
+	var info FileInfo
+	assert.Error(t, json.Unmarshal([]byte(`{"key":"d.txt","lastModified":"soon"}`), &info))
+}
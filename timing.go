@@ -0,0 +1,176 @@
+package lingstorage
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// AttemptTiming timing breakdown of one http attempt, skipped phases are zero (e.g. reused connection)
+type AttemptTiming struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	TTFB         time.Duration // attempt start to first response byte
+	Total        time.Duration // attempt start to response headers
+	StatusCode   int           // 0 on transport error
+	ReusedConn   bool
+	Protocol     string // e.g. HTTP/1.1 or HTTP/2.0, empty on transport error
+}
+
+// Timing timing breakdown of an operation, collected when Config.CollectTiming is set
+type Timing struct {
+	Attempts []AttemptTiming
+	// Transfer time moving the payload: request body write of uploads,
+	// response body read of downloads (set once the body is closed)
+	Transfer time.Duration
+	// Total operation duration, downloads include the body read once closed
+	Total time.Duration
+}
+
+// attemptTrace httptrace state of one attempt. The trace callbacks run on
+// transport goroutines, mu guards everything but start
+type attemptTrace struct {
+	mu     sync.Mutex
+	timing AttemptTiming
+
+	start, dnsStart, connectStart, tlsStart time.Time
+	gotConn, wroteRequest                   time.Time
+}
+
+// update apply f to the trace under its lock
+func (at *attemptTrace) update(f func()) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	f()
+}
+
+type attemptTraceKey struct{}
+
+// ResponseTiming timing of the attempt that produced resp, for use in response hooks.
+// Only available when Config.CollectTiming is set
+func ResponseTiming(resp *http.Response) (AttemptTiming, bool) {
+	if resp == nil || resp.Request == nil {
+		return AttemptTiming{}, false
+	}
+	at, ok := resp.Request.Context().Value(attemptTraceKey{}).(*attemptTrace)
+	if !ok {
+		return AttemptTiming{}, false
+	}
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.timing, true
+}
+
+// traceAttempt attach an httptrace to req counting connection reuse in the
+// client stats and, with CollectTiming, collecting the timing of one attempt
+func (c *Client) traceAttempt(req *http.Request) (*http.Request, *attemptTrace) {
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { c.stats.recordConn(info.Reused) },
+	}))
+	if !c.config.CollectTiming {
+		return req, nil
+	}
+	at := &attemptTrace{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			at.update(func() { at.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			at.update(func() { at.timing.DNS = time.Since(at.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			at.update(func() { at.connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			at.update(func() { at.timing.Connect = time.Since(at.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			at.update(func() { at.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			at.update(func() { at.timing.TLSHandshake = time.Since(at.tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			at.update(func() {
+				at.gotConn = time.Now()
+				at.timing.ReusedConn = info.Reused
+			})
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			at.update(func() { at.wroteRequest = time.Now() })
+		},
+		GotFirstResponseByte: func() {
+			at.update(func() { at.timing.TTFB = time.Since(at.start) })
+		},
+	}
+	ctx := httptrace.WithClientTrace(req.Context(), trace)
+	ctx = context.WithValue(ctx, attemptTraceKey{}, at)
+	return req.WithContext(ctx), at
+}
+
+// finishAttempt complete the attempt timing and record it on the current operation
+func (c *Client) finishAttempt(req *http.Request, at *attemptTrace, resp *http.Response) {
+	if at == nil {
+		return
+	}
+	at.mu.Lock()
+	at.timing.Total = time.Since(at.start)
+	if resp != nil {
+		at.timing.StatusCode = resp.StatusCode
+		at.timing.Protocol = resp.Proto
+	}
+	timing := at.timing
+	gotConn, wroteRequest := at.gotConn, at.wroteRequest
+	at.mu.Unlock()
+	op := operationFromContext(req.Context())
+	if op == nil {
+		return
+	}
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.timing == nil {
+		op.timing = &Timing{}
+	}
+	op.timing.Attempts = append(op.timing.Attempts, timing)
+	if !gotConn.IsZero() && !wroteRequest.IsZero() {
+		op.timing.Transfer = wroteRequest.Sub(gotConn)
+	}
+	op.timing.Total = time.Since(op.start)
+}
+
+// operationTiming timing collected for the operation of ctx, nil if disabled
+func operationTiming(ctx context.Context) *Timing {
+	op := operationFromContext(ctx)
+	if op == nil {
+		return nil
+	}
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.timing == nil {
+		return nil
+	}
+	op.timing.Total = time.Since(op.start)
+	return op.timing
+}
+
+// timedBody download body completing the operation timing on close
+type timedBody struct {
+	io.ReadCloser
+	timing  *Timing
+	opStart time.Time
+	start   time.Time
+	once    sync.Once
+}
+
+func (b *timedBody) Close() error {
+	b.once.Do(func() {
+		b.timing.Transfer = time.Since(b.start)
+		b.timing.Total = time.Since(b.opStart)
+	})
+	return b.ReadCloser.Close()
+}
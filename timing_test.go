@@ -0,0 +1,68 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/download") {
+			w.Write([]byte("content"))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.txt"}})
+	}))
+	defer server.Close()
+
+	var hookTimings []AttemptTiming
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", CollectTiming: true},
+		WithResponseHook(func(resp *http.Response, err error) {
+			if timing, ok := ResponseTiming(resp); ok {
+				hookTimings = append(hookTimings, timing)
+			}
+		}))
+
+	result, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Timing)
+	require.Len(t, result.Timing.Attempts, 1)
+	attempt := result.Timing.Attempts[0]
+	assert.Equal(t, http.StatusOK, attempt.StatusCode)
+	assert.Greater(t, attempt.Connect, time.Duration(0))
+	assert.Greater(t, attempt.TTFB, time.Duration(0))
+	assert.GreaterOrEqual(t, attempt.Total, attempt.TTFB)
+	assert.GreaterOrEqual(t, result.Timing.Total, attempt.Total)
+
+	download, err := client.Download(&DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	require.NoError(t, err)
+	require.NotNil(t, download.Timing)
+	_, err = io.ReadAll(download.Body)
+	require.NoError(t, err)
+	require.NoError(t, download.Body.Close())
+	assert.Greater(t, download.Timing.Transfer, time.Duration(0))
+	// 第二次请求复用连接
+	assert.True(t, download.Timing.Attempts[0].ReusedConn)
+
+	assert.Len(t, hookTimings, 2)
+}
+
+func TestTimingDisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "data": map[string]interface{}{"key": "a.txt"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	result, err := client.UploadBytes(&UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt"})
+	require.NoError(t, err)
+	assert.Nil(t, result.Timing)
+}
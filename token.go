@@ -0,0 +1,108 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/LingByte/lingstorage-sdk-go/constants"
+)
+
+// Token access token issued by an identity provider
+type Token struct {
+	AccessToken string
+	TokenType   string    // default Bearer
+	Expiry      time.Time // zero means never expires
+}
+
+// valid check token is usable for at least window
+func (t *Token) valid(window time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(window).Before(t.Expiry)
+}
+
+// TokenSource source of access tokens
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc custom token source func
+type TokenSourceFunc func() (*Token, error)
+
+// Token call the func
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+// StaticTokenSource token source always returning the same token
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func() (*Token, error) {
+		return &Token{AccessToken: accessToken}, nil
+	})
+}
+
+// RefreshTokenSource cache token and call Refresh when it is about to expire
+type RefreshTokenSource struct {
+	Refresh      func() (*Token, error)
+	ExpiryWindow time.Duration // refresh before expiry, default 1 minute
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewRefreshTokenSource create refreshing token source
+func NewRefreshTokenSource(refresh func() (*Token, error)) *RefreshTokenSource {
+	return &RefreshTokenSource{
+		Refresh:      refresh,
+		ExpiryWindow: time.Minute,
+	}
+}
+
+// Token cached token, refreshed if needed
+func (s *RefreshTokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.valid(s.ExpiryWindow) {
+		return s.token, nil
+	}
+	token, err := s.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.AccessToken == "" {
+		return nil, errors.New("token refresh returned empty token")
+	}
+	s.token = token
+	return token, nil
+}
+
+// setTokenHeader set token auth header
+func (c *Client) setTokenHeader(req *http.Request) error {
+	token, err := c.config.TokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve token: %w", err)
+	}
+	if c.token != nil {
+		accessToken := token.AccessToken
+		c.token.Store(&accessToken)
+	}
+	header := c.config.TokenHeader
+	if header == "" || http.CanonicalHeaderKey(header) == constants.AUTHORIZATION {
+		tokenType := token.TokenType
+		if tokenType == "" {
+			tokenType = "Bearer"
+		}
+		req.Header.Set(constants.AUTHORIZATION, tokenType+" "+token.AccessToken)
+		return nil
+	}
+	req.Header.Set(header, token.AccessToken)
+	return nil
+}
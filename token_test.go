@@ -0,0 +1,65 @@
+package lingstorage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerTokenAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("X-API-Key"))
+		assert.Empty(t, r.Header.Get("X-API-Secret"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL:     server.URL,
+		APIKey:      "test-key",
+		APISecret:   "test-secret",
+		TokenSource: StaticTokenSource("test-token"),
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+}
+
+func TestCustomTokenHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("X-Auth-Token"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		BaseURL:     server.URL,
+		TokenSource: StaticTokenSource("test-token"),
+		TokenHeader: "X-Auth-Token",
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	refreshes := 0
+	source := NewRefreshTokenSource(func() (*Token, error) {
+		refreshes++
+		return &Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	for i := 0; i < 3; i++ {
+		token, err := source.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "token", token.AccessToken)
+	}
+	assert.Equal(t, 1, refreshes)
+
+	// 即将过期的 token 会被刷新
+	source.token.Expiry = time.Now().Add(10 * time.Second)
+	_, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, 2, refreshes)
+}
@@ -0,0 +1,58 @@
+package lingstorage
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/LingByte/lingstorage-sdk-go"
+
+// span attribute keys
+const (
+	attrOperation  = attribute.Key("lingstorage.operation")
+	attrBucket     = attribute.Key("lingstorage.bucket")
+	attrKey        = attribute.Key("lingstorage.key")
+	attrBytes      = attribute.Key("lingstorage.bytes")
+	attrAttempts   = attribute.Key("lingstorage.attempts")
+	attrStatusCode = attribute.Key("http.response.status_code")
+)
+
+// startSpan start span of a client operation, a no-op unless Config.TracerProvider is set
+func (c *Client) startSpan(ctx context.Context, operation, bucket, key string) (context.Context, trace.Span) {
+	if c.tracer == nil {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	attrs := []attribute.KeyValue{attrOperation.String(operation)}
+	if bucket != "" {
+		attrs = append(attrs, attrBucket.String(bucket))
+	}
+	if key != "" {
+		attrs = append(attrs, attrKey.String(key))
+	}
+	return c.tracer.Start(ctx, "lingstorage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan record error and end span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// injectTraceHeaders propagate trace context to the server
+func (c *Client) injectTraceHeaders(req *http.Request) {
+	if c.tracer == nil {
+		return
+	}
+	c.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+}
@@ -0,0 +1,76 @@
+package lingstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingSpans(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := NewClient(&Config{
+		BaseURL:        server.URL,
+		APIKey:         "test-key",
+		RetryCount:     1,
+		TracerProvider: provider,
+		Propagator:     propagation.TraceContext{},
+	})
+
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	err := client.WithContext(parentCtx).DeleteFile("test-bucket", "test-key")
+	parent.End()
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "lingstorage.DeleteFile", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, "test-bucket", spanAttr(span, attrBucket).AsString())
+	assert.Equal(t, "test-key", spanAttr(span, attrKey).AsString())
+	assert.Equal(t, int64(2), spanAttr(span, attrAttempts).AsInt64())
+	assert.Equal(t, int64(http.StatusNotFound), spanAttr(span, attrStatusCode).AsInt64())
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestTracingDisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("traceparent"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+}
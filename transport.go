@@ -0,0 +1,156 @@
+package lingstorage
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrPinMismatch server certificate chain does not match any pinned public key
+var ErrPinMismatch = errors.New("lingstorage: server certificate does not match pinned public keys")
+
+// connection pool defaults, the net/http default of 2 idle connections per
+// host makes concurrent uploads open and close a connection per request
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// newTransport build http transport from config
+func newTransport(config *Config) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	// the default transport negotiates HTTP/2 over TLS via ALPN
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if config.ForceHTTP1 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if transport.Proxy, err = newProxyFunc(config); err != nil {
+		return nil, err
+	}
+	dial := config.DialContext
+	if config.DNSCacheTTL > 0 || config.Resolver != nil {
+		dial = NewCachingResolver(config.Resolver, config.DNSCacheTTL).DialContext(dial)
+	}
+	if dial != nil {
+		transport.DialContext = dial
+	}
+	transport.MaxIdleConns = config.MaxIdleConns
+	if transport.MaxIdleConns == 0 {
+		transport.MaxIdleConns = DefaultMaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	if transport.MaxIdleConnsPerHost == 0 {
+		transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	if transport.IdleConnTimeout == 0 {
+		transport.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	return transport, nil
+}
+
+// newTLSConfig build tls config from config, nil if no TLS option is set
+func newTLSConfig(config *Config) (*tls.Config, error) {
+	hasClientCert := config.ClientCertFile != "" || len(config.ClientCertPEM) > 0
+	hasCA := config.CAFile != "" || len(config.CAPEM) > 0
+	if config.TLSConfig == nil && !hasClientCert && !hasCA && len(config.PinnedPublicKeys) == 0 && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.TLSConfig != nil {
+		tlsConfig = config.TLSConfig.Clone()
+	}
+	if config.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if hasClientCert {
+		certPEM, keyPEM := config.ClientCertPEM, config.ClientKeyPEM
+		var err error
+		if config.ClientCertFile != "" {
+			if certPEM, err = os.ReadFile(config.ClientCertFile); err != nil {
+				return nil, fmt.Errorf("failed to read client certificate: %w", err)
+			}
+		}
+		if config.ClientKeyFile != "" {
+			if keyPEM, err = os.ReadFile(config.ClientKeyFile); err != nil {
+				return nil, fmt.Errorf("failed to read client key: %w", err)
+			}
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	if hasCA {
+		pool := tlsConfig.RootCAs
+		if pool == nil {
+			systemPool, err := x509.SystemCertPool()
+			if err != nil || systemPool == nil {
+				systemPool = x509.NewCertPool()
+			}
+			pool = systemPool
+		}
+		caPEM := config.CAPEM
+		if config.CAFile != "" {
+			data, err := os.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			caPEM = append(append([]byte{}, caPEM...), data...)
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no valid CA certificate found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.PinnedPublicKeys) > 0 {
+		pins := make(map[string]bool, len(config.PinnedPublicKeys))
+		for _, pin := range config.PinnedPublicKeys {
+			pins[pin] = true
+		}
+		// Only certificates of the verified chains count, the peer may append any
+		// certificate to the chain it sends. Without verification only the leaf
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			chains := state.VerifiedChains
+			if len(chains) == 0 && len(state.PeerCertificates) > 0 {
+				chains = [][]*x509.Certificate{state.PeerCertificates[:1]}
+			}
+			for _, chain := range chains {
+				for _, cert := range chain {
+					if pins[PublicKeyPin(cert)] {
+						return nil
+					}
+				}
+			}
+			return ErrPinMismatch
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// PublicKeyPin base64 sha256 of the certificate SPKI, the value used in PinnedPublicKeys
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
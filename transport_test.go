@@ -0,0 +1,227 @@
+package lingstorage
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedCert 生成测试用自签名证书
+func selfSignedCert(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "lingstorage-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func TestMutualTLS(t *testing.T) {
+	certPEM, keyPEM := selfSignedCert(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Len(t, r.TLS.PeerCertificates, 1)
+		assert.Equal(t, "lingstorage-test-client", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client := NewClient(&Config{
+		BaseURL:       server.URL,
+		APIKey:        "test-key",
+		RetryCount:    -1,
+		ClientCertPEM: certPEM,
+		ClientKeyPEM:  keyPEM,
+		CAPEM:         caPEM,
+	})
+	require.NoError(t, client.DeleteFile("bucket", "key"))
+
+	// 未配置客户端证书时握手失败
+	noCert := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1, CAPEM: caPEM})
+	assert.Error(t, noCert.DeleteFile("bucket", "key"))
+}
+
+func TestCertificatePinning(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	pinned := NewClient(&Config{
+		BaseURL:          server.URL,
+		RetryCount:       -1,
+		CAPEM:            caPEM,
+		PinnedPublicKeys: []string{PublicKeyPin(server.Certificate())},
+	})
+	require.NoError(t, pinned.DeleteFile("bucket", "key"))
+
+	mismatched := NewClient(&Config{
+		BaseURL:          server.URL,
+		RetryCount:       -1,
+		CAPEM:            caPEM,
+		PinnedPublicKeys: []string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	})
+	err := mismatched.DeleteFile("bucket", "key")
+	assert.True(t, errors.Is(err, ErrPinMismatch))
+}
+
+func TestPinningIgnoresUnverifiedCertificates(t *testing.T) {
+	// 服务端在证书链后附加一张未经验证的证书, 其公钥不能满足固定
+	extraPEM, _ := selfSignedCert(t)
+	block, _ := pem.Decode(extraPEM)
+	extra, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.StartTLS()
+	defer server.Close()
+	cert := server.TLS.Certificates[0]
+	cert.Certificate = append(cert.Certificate, extra.Raw)
+	server.TLS.Certificates = []tls.Certificate{cert}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	for _, insecure := range []bool{false, true} {
+		appended := NewClient(&Config{
+			BaseURL:            server.URL,
+			RetryCount:         -1,
+			CAPEM:              caPEM,
+			InsecureSkipVerify: insecure,
+			PinnedPublicKeys:   []string{PublicKeyPin(extra)},
+		})
+		assert.ErrorIs(t, appended.DeleteFile("bucket", "key"), ErrPinMismatch, "insecure=%v", insecure)
+
+		leaf := NewClient(&Config{
+			BaseURL:            server.URL,
+			RetryCount:         -1,
+			CAPEM:              caPEM,
+			InsecureSkipVerify: insecure,
+			PinnedPublicKeys:   []string{PublicKeyPin(server.Certificate())},
+		})
+		assert.NoError(t, leaf.DeleteFile("bucket", "key"), "insecure=%v", insecure)
+	}
+}
+
+func TestInvalidTLSConfig(t *testing.T) {
+	client := NewClient(&Config{
+		BaseURL:        "https://example.com",
+		ClientCertFile: "/nonexistent/cert.pem",
+		ClientKeyFile:  "/nonexistent/key.pem",
+	})
+	err := client.DeleteFile("bucket", "key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "client certificate")
+}
+
+func TestConnectionPoolOptions(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://example.com"})
+	transport := client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, DefaultMaxIdleConns, transport.MaxIdleConns)
+	assert.Equal(t, DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, transport.MaxConnsPerHost)
+	assert.Equal(t, DefaultIdleConnTimeout, transport.IdleConnTimeout)
+
+	client = NewClient(&Config{
+		BaseURL:             "https://example.com",
+		MaxIdleConns:        500,
+		MaxIdleConnsPerHost: 64,
+		MaxConnsPerHost:     128,
+		IdleConnTimeout:     time.Minute,
+	})
+	transport = client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 500, transport.MaxIdleConns)
+	assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 128, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+}
+
+func TestConnectionReuseUnderConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	conns := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, MaxConnsPerHost: 4})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, client.DeleteFile("bkt", "k"))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, len(conns), 4)
+}
+
+func TestHTTP2AndConnectionReuse(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, InsecureSkipVerify: true, CollectTiming: true})
+	var protocols []string
+	client = client.Clone(WithResponseHook(func(resp *http.Response, err error) {
+		if timing, ok := ResponseTiming(resp); ok {
+			protocols = append(protocols, timing.Protocol)
+		}
+	}))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.DeleteFile("bkt", "k"))
+	}
+	stats := client.Stats()
+	assert.Equal(t, []string{"HTTP/2.0", "HTTP/2.0", "HTTP/2.0"}, protocols)
+	assert.EqualValues(t, 3, stats.RequestsByProtocol["HTTP/2.0"])
+	assert.EqualValues(t, 1, stats.ConnectionsOpened)
+	assert.EqualValues(t, 2, stats.ConnectionsReused)
+
+	// 强制 HTTP/1.1
+	http1 := NewClient(&Config{BaseURL: server.URL, RetryCount: -1, InsecureSkipVerify: true, ForceHTTP1: true})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, http1.DeleteFile("bkt", "k"))
+	}
+	stats = http1.Stats()
+	assert.EqualValues(t, 3, stats.RequestsByProtocol["HTTP/1.1"])
+	assert.Zero(t, stats.RequestsByProtocol["HTTP/2.0"])
+	assert.EqualValues(t, 1, stats.ConnectionsOpened)
+	assert.EqualValues(t, 2, stats.ConnectionsReused)
+}
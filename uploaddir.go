@@ -0,0 +1,70 @@
+package lingstorage
+
+import "fmt"
+
+// UploadDirectoryRequest upload the files below a local directory
+type UploadDirectoryRequest struct {
+	Dir    string
+	Bucket string
+	Prefix string // remote key prefix, keys are Prefix + path relative to Dir
+	// Include glob patterns of files to upload, e.g. **/*.jpg, empty uploads all files
+	Include []string
+	// Exclude gitignore style patterns of files to skip. Rules of IgnoreFile below Dir are added
+	Exclude    []string
+	IgnoreFile string // default .lingignore, a missing file is fine
+	// ManifestPath write a manifest of uploaded objects, csv by .csv extension, else json
+	ManifestPath string
+	// Symlinks how symbolic links are uploaded, default SymlinkSkip
+	Symlinks   SymlinkPolicy
+	OnProgress func(completed, total int, current string)
+}
+
+// UploadDirectory upload every file below Dir that passes the include and
+// exclude rules, failed files are reported in BatchUploadResult.Failed and
+// sockets, devices and other entries that cannot be uploaded in Skipped
+func (c *Client) UploadDirectory(req *UploadDirectoryRequest) (_ *BatchUploadResult, err error) {
+	ctx, op := c.startOperation("UploadDirectory", req.Bucket, req.Prefix)
+	defer func() { c.endOperation(op, err) }()
+	if err := validateSymlinkPolicy(req.Symlinks); err != nil {
+		return nil, err
+	}
+	filter, err := newPathFilter(req.Dir, req.Include, req.Exclude, req.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+	files, skipped, err := walkLocal(req.Dir, filter, req.Symlinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+	prefix := normalizePrefix(req.Prefix)
+
+	result := &BatchUploadResult{
+		Success: make([]UploadResult, 0),
+		Failed:  make([]UploadError, 0),
+		Total:   len(files),
+		Skipped: skipped,
+	}
+	var uploaded []string
+	client := c.WithContext(ctx)
+	for i, file := range files {
+		if req.OnProgress != nil {
+			req.OnProgress(i, len(files), file.path)
+		}
+		uploadResult, err := client.uploadLocal(req.Bucket, prefix+file.rel, file, false)
+		if err != nil {
+			result.Failed = append(result.Failed, UploadError{File: file.path, Error: err.Error()})
+		} else {
+			result.Success = append(result.Success, *uploadResult)
+			uploaded = append(uploaded, file.path)
+		}
+	}
+	if req.OnProgress != nil {
+		req.OnProgress(len(files), len(files), "")
+	}
+	if req.ManifestPath != "" {
+		if err := writeManifest(req.ManifestPath, manifestEntries(uploaded, result.Success)); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
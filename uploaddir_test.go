@@ -0,0 +1,59 @@
+package lingstorage_test
+
+import (
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadDirectory(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".lingignore":        "cache/\n*.tmp\n",
+		"index.html":         "<html>",
+		"img/logo.png":       "png",
+		"img/raw/photo.jpg":  "jpg",
+		"img/raw/photo.tmp":  "tmp",
+		"cache/page.html":    "cached",
+		"private/secret.txt": "secret",
+	})
+
+	result, err := client.UploadDirectory(&lingstorage.UploadDirectoryRequest{
+		Dir:     dir,
+		Bucket:  lingstoragetest.DefaultBucket,
+		Prefix:  "site",
+		Exclude: []string{"private/"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, 3, result.Total)
+	for _, key := range []string{"site/index.html", "site/img/logo.png", "site/img/raw/photo.jpg"} {
+		_, ok := server.Object(lingstoragetest.DefaultBucket, key)
+		assert.True(t, ok, key)
+	}
+	for _, key := range []string{"site/.lingignore", "site/img/raw/photo.tmp", "site/cache/page.html", "site/private/secret.txt"} {
+		_, ok := server.Object(lingstoragetest.DefaultBucket, key)
+		assert.False(t, ok, key)
+	}
+
+	result, err = client.UploadDirectory(&lingstorage.UploadDirectoryRequest{
+		Dir:     dir,
+		Bucket:  lingstoragetest.DefaultBucket,
+		Prefix:  "images",
+		Include: []string{"**/*.jpg", "*.png"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "images/img/logo.png")
+	assert.True(t, ok)
+
+	_, err = client.UploadDirectory(&lingstorage.UploadDirectoryRequest{Dir: dir, Bucket: "b", Include: []string{"[bad"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package lingstorage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// PreUploadHook inspect or adjust an upload before any byte is sent, e.g. to
+// enforce size caps, sniff the MIME type, sanitize the key or run a local
+// virus scan. req may be modified; body reads the upload data without
+// consuming it. A returned error aborts the upload
+type PreUploadHook func(req *UploadRequest, body io.Reader) error
+
+// WithPreUploadHook append a pre-upload hook, hooks run in the order they were added
+func WithPreUploadHook(hook PreUploadHook) ClientOption {
+	return func(c *Client) {
+		c.preUploadHooks = append(c.preUploadHooks, hook)
+	}
+}
+
+// runPreUploadHooks check AllowedTypes, then run the hooks on body, returns
+// the reader to upload
+func (c *Client) runPreUploadHooks(req *UploadRequest, filename string, body io.Reader) (io.Reader, error) {
+	var err error
+	if len(req.AllowedTypes) > 0 {
+		body, err = inspectBody(body, func(r io.Reader) error {
+			return checkAllowedType(filename, r, req.AllowedTypes)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, hook := range c.preUploadHooks {
+		body, err = inspectBody(body, func(r io.Reader) error { return hook(req, r) })
+		if err != nil {
+			return nil, fmt.Errorf("upload rejected by hook: %w", err)
+		}
+	}
+	return body, nil
+}
+
+// inspectBody let fn read body without consuming it, returns the reader to
+// upload. Section readers are handed out as fresh views of the file; data
+// other readers yield to fn is kept in memory and replayed
+func inspectBody(body io.Reader, fn func(io.Reader) error) (io.Reader, error) {
+	if section, ok := body.(*io.SectionReader); ok {
+		return body, fn(io.NewSectionReader(section, 0, section.Size()))
+	}
+	rec := &recordingReader{reader: body}
+	err := fn(rec)
+	return io.MultiReader(bytes.NewReader(rec.buf.Bytes()), body), err
+}
+
+// recordingReader keep the bytes read through it
+type recordingReader struct {
+	reader io.Reader
+	buf    bytes.Buffer
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.buf.Write(p[:n])
+	return n, err
+}
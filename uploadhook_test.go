@@ -0,0 +1,60 @@
+package lingstorage_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreUploadHook(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	errInfected := errors.New("infected")
+	var scanned []string
+	client := server.Client(
+		lingstorage.WithPreUploadHook(func(req *lingstorage.UploadRequest, body io.Reader) error {
+			req.Key = strings.ToLower(req.Key)
+			return nil
+		}),
+		// 模拟本地病毒扫描, 读取全部内容
+		lingstorage.WithPreUploadHook(func(req *lingstorage.UploadRequest, body io.Reader) error {
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			scanned = append(scanned, string(data))
+			if strings.Contains(string(data), "EICAR") {
+				return errInfected
+			}
+			return nil
+		}),
+	)
+
+	_, err := client.UploadBytes(&lingstorage.UploadBytesRequest{Data: []byte("hello"), Filename: "a.txt", Key: "Docs/A.txt"})
+	require.NoError(t, err)
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "docs/a.txt")
+	require.True(t, ok)
+	assert.Equal(t, "hello", string(obj.Data))
+
+	path := filepath.Join(t.TempDir(), "b.txt")
+	require.NoError(t, os.WriteFile(path, []byte("from disk"), 0o644))
+	_, err = client.UploadFile(&lingstorage.UploadRequest{FilePath: path, Key: "B.txt"})
+	require.NoError(t, err)
+	obj, ok = server.Object(lingstoragetest.DefaultBucket, "b.txt")
+	require.True(t, ok)
+	assert.Equal(t, "from disk", string(obj.Data))
+
+	_, err = client.UploadBytes(&lingstorage.UploadBytesRequest{Data: []byte("X5O EICAR"), Filename: "v.exe", Key: "v.exe"})
+	assert.ErrorIs(t, err, errInfected)
+	_, ok = server.Object(lingstoragetest.DefaultBucket, "v.exe")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"hello", "from disk", "X5O EICAR"}, scanned)
+}
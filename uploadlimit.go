@@ -0,0 +1,60 @@
+package lingstorage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUploadTooLarge upload above Config.MaxUploadSize or the server upload limit
+var ErrUploadTooLarge = errors.New("lingstorage: upload too large")
+
+// maxUploadSize bytes a single upload may carry, 0 when unlimited. Without an
+// explicit Config.MaxUploadSize the limit of the cached server info is used;
+// uploads do not fetch it themselves to save the round trip
+func (c *Client) maxUploadSize() int64 {
+	if c.config.MaxUploadSize != 0 {
+		if c.config.MaxUploadSize < 0 {
+			return 0
+		}
+		return c.config.MaxUploadSize
+	}
+	if info := c.capabilities.cached(); info != nil {
+		return info.Limits.MaxUploadSize
+	}
+	return 0
+}
+
+// checkUploadSize reject an upload of size bytes above the limit before it is
+// sent. Bodies of unknown size, size not positive, are wrapped to fail once
+// they yield more than the limit
+func (c *Client) checkUploadSize(filename string, size int64, body io.Reader) (io.Reader, error) {
+	limit := c.maxUploadSize()
+	if limit <= 0 {
+		return body, nil
+	}
+	if size > limit {
+		return nil, fmt.Errorf("%w: %s is %d bytes, limit is %d bytes", ErrUploadTooLarge, filename, size, limit)
+	}
+	if size > 0 {
+		return body, nil
+	}
+	return &limitedBody{reader: body, filename: filename, limit: limit}, nil
+}
+
+// limitedBody fail with ErrUploadTooLarge once more than limit bytes are read
+type limitedBody struct {
+	reader   io.Reader
+	filename string
+	limit    int64
+	read     int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, fmt.Errorf("%w: %s exceeds %d bytes", ErrUploadTooLarge, l.filename, l.limit)
+	}
+	return n, err
+}
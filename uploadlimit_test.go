@@ -0,0 +1,67 @@
+package lingstorage_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxUploadSize(t *testing.T) {
+	var uploads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+				"limits": map[string]interface{}{"maxUploadSize": 8},
+			}})
+		case "/api/public/upload":
+			atomic.AddInt32(&uploads, 1)
+			w.Write([]byte(`{"success":true,"data":{"key":"a.txt"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1, MaxUploadSize: 4})
+	_, err := client.UploadBytes(&lingstorage.UploadBytesRequest{Bucket: "bkt", Data: []byte("12345"), Filename: "a.txt"})
+	assert.ErrorIs(t, err, lingstorage.ErrUploadTooLarge)
+
+	// 大小未知的流读到超出限制时失败
+	_, err = client.UploadFromReader(&lingstorage.UploadFromReaderRequest{Bucket: "bkt", Reader: io.MultiReader(bytes.NewReader([]byte("12345"))), Filename: "a.txt"})
+	assert.ErrorIs(t, err, lingstorage.ErrUploadTooLarge)
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(path, []byte("12345"), 0o644))
+	_, err = client.UploadFile(&lingstorage.UploadRequest{Bucket: "bkt", FilePath: path})
+	assert.ErrorIs(t, err, lingstorage.ErrUploadTooLarge)
+	assert.Zero(t, atomic.LoadInt32(&uploads))
+
+	// 未配置时使用服务端报告的限制
+	client = lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1})
+	_, err = client.UploadFile(&lingstorage.UploadRequest{Bucket: "bkt", FilePath: path})
+	require.NoError(t, err)
+	_, err = client.Capabilities()
+	require.NoError(t, err)
+	_, err = client.UploadBytes(&lingstorage.UploadBytesRequest{Bucket: "bkt", Data: []byte("123456789"), Filename: "a.txt"})
+	assert.ErrorIs(t, err, lingstorage.ErrUploadTooLarge)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&uploads))
+
+	// 负数关闭检查
+	client = lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, RetryCount: -1, MaxUploadSize: -1})
+	_, err = client.Capabilities()
+	require.NoError(t, err)
+	_, err = client.UploadBytes(&lingstorage.UploadBytesRequest{Bucket: "bkt", Data: []byte("123456789"), Filename: "a.txt"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&uploads))
+}
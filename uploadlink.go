@@ -0,0 +1,99 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// UploadLinkConstraints limits enforced by the server on uploads through a link
+type UploadLinkConstraints struct {
+	MaxSize      int64         // max bytes per file, 0 means server default
+	AllowedTypes []string      // MIME types or extensions, e.g. "image/*", ".pdf"
+	Expires      time.Duration // lifetime of the link, 0 means server default
+	MaxUploads   int           // max number of files, 0 means unlimited
+}
+
+// UploadLink link letting anyone holding it upload into a prefix without credentials
+type UploadLink struct {
+	ID           string    `json:"id"`
+	Bucket       string    `json:"bucket"`
+	Prefix       string    `json:"prefix"`
+	URL          string    `json:"url"`
+	Token        string    `json:"token"`
+	MaxSize      int64     `json:"maxSize"`
+	AllowedTypes []string  `json:"allowedTypes"`
+	MaxUploads   int       `json:"maxUploads"`
+	Uploads      int       `json:"uploads"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	Revoked      bool      `json:"revoked"`
+}
+
+// Active check link is neither revoked, expired nor used up
+func (l *UploadLink) Active() bool {
+	if l.Revoked {
+		return false
+	}
+	if !l.ExpiresAt.IsZero() && !time.Now().Before(l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUploads == 0 || l.Uploads < l.MaxUploads
+}
+
+// CreateUploadLink 创建上传链接, 外部用户可凭链接上传到指定前缀
+func (c *Client) CreateUploadLink(bucket, prefix string, constraints *UploadLinkConstraints) (_ *UploadLink, err error) {
+	ctx, op := c.startOperation("CreateUploadLink", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if constraints == nil {
+		constraints = &UploadLinkConstraints{}
+	}
+	if constraints.MaxSize < 0 || constraints.Expires < 0 || constraints.MaxUploads < 0 {
+		return nil, fmt.Errorf("upload link constraints must not be negative")
+	}
+	body := map[string]interface{}{
+		"prefix": prefix,
+	}
+	if constraints.MaxSize > 0 {
+		body["maxSize"] = constraints.MaxSize
+	}
+	if len(constraints.AllowedTypes) > 0 {
+		body["allowedTypes"] = constraints.AllowedTypes
+	}
+	if constraints.Expires > 0 {
+		body["expires"] = constraints.Expires.String()
+	}
+	if constraints.MaxUploads > 0 {
+		body["maxUploads"] = constraints.MaxUploads
+	}
+	var result UploadLink
+	if err := c.call(ctx, "POST", bucketPath(bucket)+"/upload-links", body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListUploadLinks 列举存储桶的上传链接
+func (c *Client) ListUploadLinks(bucket string) (_ []UploadLink, err error) {
+	ctx, op := c.startOperation("ListUploadLinks", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	var data struct {
+		Links []UploadLink `json:"links"`
+	}
+	if err := c.call(ctx, "GET", bucketPath(bucket)+"/upload-links", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Links, nil
+}
+
+// RevokeUploadLink 撤销上传链接, 之后凭该链接的上传会被拒绝
+func (c *Client) RevokeUploadLink(bucket, id string) (err error) {
+	ctx, op := c.startOperation("RevokeUploadLink", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	if id == "" {
+		return fmt.Errorf("upload link id is empty")
+	}
+	return c.call(ctx, "DELETE", bucketPath(bucket)+"/upload-links/"+url.PathEscape(id), nil, nil)
+}
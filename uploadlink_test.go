@@ -0,0 +1,75 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadLinks(t *testing.T) {
+	links := map[string]map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/public/buckets/inbox/upload-links":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			body["id"] = "link-1"
+			body["url"] = "https://storage.example.com/drop/tok"
+			links["link-1"] = body
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": body})
+		case r.Method == "GET" && r.URL.Path == "/api/public/buckets/inbox/upload-links":
+			list := []map[string]interface{}{}
+			for _, link := range links {
+				list = append(list, link)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"links": list}})
+		case r.Method == "DELETE" && r.URL.Path == "/api/public/buckets/inbox/upload-links/link-1":
+			delete(links, "link-1")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	link, err := client.CreateUploadLink("inbox", "vendor-a/", &UploadLinkConstraints{
+		MaxSize:      10 << 20,
+		AllowedTypes: []string{"application/pdf"},
+		Expires:      24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "link-1", link.ID)
+	assert.Equal(t, "vendor-a/", link.Prefix)
+	assert.EqualValues(t, 10<<20, link.MaxSize)
+	assert.Equal(t, []string{"application/pdf"}, link.AllowedTypes)
+	assert.Equal(t, "24h0m0s", links["link-1"]["expires"])
+	assert.NotContains(t, links["link-1"], "maxUploads")
+	assert.True(t, link.Active())
+
+	list, err := client.ListUploadLinks("inbox")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, link.URL, list[0].URL)
+
+	require.NoError(t, client.RevokeUploadLink("inbox", "link-1"))
+	list, err = client.ListUploadLinks("inbox")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = client.CreateUploadLink("inbox", "", &UploadLinkConstraints{MaxSize: -1})
+	assert.Error(t, err)
+	assert.Error(t, client.RevokeUploadLink("inbox", ""))
+}
+
+func TestUploadLinkActive(t *testing.T) {
+	assert.False(t, (&UploadLink{Revoked: true}).Active())
+	assert.False(t, (&UploadLink{ExpiresAt: time.Now().Add(-time.Minute)}).Active())
+	assert.False(t, (&UploadLink{MaxUploads: 2, Uploads: 2}).Active())
+	assert.True(t, (&UploadLink{MaxUploads: 2, Uploads: 1, ExpiresAt: time.Now().Add(time.Hour)}).Active())
+}
@@ -0,0 +1,62 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// methods a file URL may be signed for
+var urlMethods = map[string]bool{"GET": true, "PUT": true, "HEAD": true}
+
+// WithResponseContentDisposition Content-Disposition the server sends when the URL is fetched
+func WithResponseContentDisposition(value string) URLOption {
+	return func(q url.Values) {
+		q.Set("response-content-disposition", value)
+	}
+}
+
+// WithDownloadFilename make browsers save the object as filename instead of
+// displaying it, for "Download as…" links. Non-ASCII names are kept through
+// the RFC 5987 filename* parameter
+func WithDownloadFilename(filename string) URLOption {
+	return WithResponseContentDisposition(attachmentDisposition(filename))
+}
+
+// WithResponseContentType Content-Type the server sends when the URL is fetched
+func WithResponseContentType(contentType string) URLOption {
+	return func(q url.Values) {
+		q.Set("response-content-type", contentType)
+	}
+}
+
+// WithHTTPMethod sign the URL for GET (default), PUT or HEAD
+func WithHTTPMethod(method string) URLOption {
+	return func(q url.Values) {
+		q.Set("method", strings.ToUpper(method))
+	}
+}
+
+// validateURLOptions check options that cannot report errors themselves
+func validateURLOptions(q url.Values) error {
+	if method := q.Get("method"); method != "" && !urlMethods[method] {
+		return fmt.Errorf("unsupported URL method %q, want GET, PUT or HEAD", method)
+	}
+	return nil
+}
+
+// attachmentDisposition attachment Content-Disposition with an ASCII
+// fallback filename and the exact name in filename*
+func attachmentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	disposition := fmt.Sprintf("attachment; filename=\"%s\"", fallback)
+	if fallback != filename {
+		disposition += "; filename*=UTF-8''" + strings.ReplaceAll(url.QueryEscape(filename), "+", "%20")
+	}
+	return disposition
+}
@@ -0,0 +1,54 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileURLOptions(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/files/bkt/report.pdf/url", r.URL.Path)
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{
+			"url": "https://cdn.example.com/report.pdf?" + r.URL.RawQuery,
+		}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	u, err := client.GetFileURL("bkt", "report.pdf", time.Hour,
+		WithDownloadFilename("Q3 report.pdf"),
+		WithResponseContentType("application/octet-stream"),
+		WithHTTPMethod("get"))
+	require.NoError(t, err)
+	assert.Contains(t, u, "response-content-disposition=")
+	assert.Equal(t, `attachment; filename="Q3 report.pdf"`, query.Get("response-content-disposition"))
+	assert.Equal(t, "application/octet-stream", query.Get("response-content-type"))
+	assert.Equal(t, "GET", query.Get("method"))
+	assert.Equal(t, "1h0m0s", query.Get("expires"))
+
+	_, err = client.GetFileURL("bkt", "report.pdf", time.Hour, WithResponseContentDisposition("inline"), WithHTTPMethod("PUT"))
+	require.NoError(t, err)
+	assert.Equal(t, "inline", query.Get("response-content-disposition"))
+	assert.Equal(t, "PUT", query.Get("method"))
+
+	// 不支持的方法不发送请求
+	query = nil
+	_, err = client.GetFileURL("bkt", "report.pdf", time.Hour, WithHTTPMethod("DELETE"))
+	assert.Error(t, err)
+	assert.Nil(t, query)
+}
+
+func TestAttachmentDisposition(t *testing.T) {
+	assert.Equal(t, `attachment; filename="a.txt"`, attachmentDisposition("a.txt"))
+	assert.Equal(t, `attachment; filename="__.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A.pdf`, attachmentDisposition("报告.pdf"))
+	assert.Equal(t, `attachment; filename="a_b_.txt"; filename*=UTF-8''a%22b%5C.txt`, attachmentDisposition(`a"b\.txt`))
+}
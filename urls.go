@@ -0,0 +1,119 @@
+package lingstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxURLKeysPerRequest keys signed by one GetFileURLs request, larger batches are split
+	MaxURLKeysPerRequest = 1000
+	// urlFallbackConcurrency parallel GetFileURL calls against servers without the batch API
+	urlFallbackConcurrency = 8
+)
+
+// GetFileURLs access URLs of many objects keyed by object key, signed in one
+// request per MaxURLKeysPerRequest keys. Against servers without the batch
+// API the URLs are requested key by key. opts apply to every URL
+func (c *Client) GetFileURLs(bucket string, keys []string, expires time.Duration, opts ...URLOption) (_ map[string]string, err error) {
+	ctx, op := c.startOperation("GetFileURLs", bucket, "")
+	defer func() { c.endOperation(op, err) }()
+
+	q := url.Values{}
+	if expires > 0 {
+		q.Set("expires", expires.String())
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if err := validateURLOptions(q); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		if !c.config.SkipNameValidation {
+			if err := ValidateObjectKey(key); err != nil {
+				return nil, err
+			}
+		}
+		seen[key] = true
+		unique = append(unique, key)
+	}
+
+	urls := make(map[string]string, len(unique))
+	path := withQuery(bucketPath(bucket)+"/urls", q)
+	for start := 0; start < len(unique); start += MaxURLKeysPerRequest {
+		end := start + MaxURLKeysPerRequest
+		if end > len(unique) {
+			end = len(unique)
+		}
+		var data struct {
+			URLs map[string]string `json:"urls"`
+		}
+		err := c.call(ctx, "POST", path, map[string]interface{}{"keys": unique[start:end]}, &data)
+		if isMissingEndpoint(err) {
+			return urls, c.fileURLsOneByOne(ctx, bucket, unique[start:], expires, opts, urls)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for key, u := range data.URLs {
+			urls[key] = u
+		}
+	}
+	return urls, nil
+}
+
+// fileURLsOneByOne fill urls with one GetFileURL call per key
+func (c *Client) fileURLsOneByOne(ctx context.Context, bucket string, keys []string, expires time.Duration, opts []URLOption, urls map[string]string) error {
+	client := c.WithContext(ctx)
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, urlFallbackConcurrency)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() { <-sem; wg.Done() }()
+			mu.Lock()
+			failed := firstErr != nil
+			mu.Unlock()
+			if failed {
+				return
+			}
+			u, err := client.GetFileURL(bucket, key, expires, opts...)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get URL of %s: %w", key, err)
+				}
+				return
+			}
+			urls[key] = u
+		}(key)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// isMissingEndpoint err reports an API the server does not have
+func isMissingEndpoint(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotImplemented
+}
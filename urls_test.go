@@ -0,0 +1,77 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileURLs(t *testing.T) {
+	var batches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/public/buckets/bkt/urls", r.URL.Path)
+		assert.Equal(t, "1h0m0s", r.URL.Query().Get("expires"))
+		atomic.AddInt32(&batches, 1)
+		var body struct {
+			Keys []string `json:"keys"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.LessOrEqual(t, len(body.Keys), MaxURLKeysPerRequest)
+		urls := map[string]string{}
+		for _, key := range body.Keys {
+			urls[key] = "https://cdn.example.com/" + key
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"urls": urls}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	keys := make([]string, 1500)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("img/%04d.jpg", i)
+	}
+	keys = append(keys, keys[0])
+	urls, err := client.GetFileURLs("bkt", keys, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, urls, 1500)
+	assert.Equal(t, "https://cdn.example.com/img/0042.jpg", urls["img/0042.jpg"])
+	assert.EqualValues(t, 2, batches)
+
+	_, err = client.GetFileURLs("bkt", []string{"../etc/passwd"}, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGetFileURLsFallback(t *testing.T) {
+	var single int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 旧版服务端没有批量接口
+		if r.URL.Path == "/api/public/buckets/bkt/urls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.True(t, strings.HasSuffix(r.URL.Path, "/url"))
+		atomic.AddInt32(&single, 1)
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/public/files/bkt/"), "/url")
+		assert.Equal(t, "attachment; filename=\"x.jpg\"", r.URL.Query().Get("response-content-disposition"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]string{"url": "https://cdn.example.com/" + key}})
+	}))
+	defer server.Close()
+	client := NewClient(&Config{BaseURL: server.URL, RetryCount: -1})
+
+	urls, err := client.GetFileURLs("bkt", []string{"a.jpg", "b.jpg", "c.jpg"}, time.Hour, WithDownloadFilename("x.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"a.jpg": "https://cdn.example.com/a.jpg",
+		"b.jpg": "https://cdn.example.com/b.jpg",
+		"c.jpg": "https://cdn.example.com/c.jpg",
+	}, urls)
+	assert.EqualValues(t, 3, single)
+}
@@ -0,0 +1,114 @@
+package lingstorage
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// UsageGranularity bucket size of usage data points
+type UsageGranularity string
+
+const (
+	UsageHourly  UsageGranularity = "hour"
+	UsageDaily   UsageGranularity = "day"
+	UsageMonthly UsageGranularity = "month"
+)
+
+// TimeRange half open time range [Start, End)
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LastDays range of the last n days up to now
+func LastDays(n int) TimeRange {
+	now := time.Now()
+	return TimeRange{Start: now.AddDate(0, 0, -n), End: now}
+}
+
+// UsageQuery 用量查询请求
+type UsageQuery struct {
+	Bucket      string           // empty means all buckets of the account
+	Granularity UsageGranularity // default UsageDaily
+	TimeRange   TimeRange
+}
+
+// UsagePoint usage of one granularity interval
+type UsagePoint struct {
+	Time          time.Time `json:"time"` // start of the interval
+	StorageBytes  int64     `json:"storageBytes"`
+	EgressBytes   int64     `json:"egressBytes"`
+	IngressBytes  int64     `json:"ingressBytes"`
+	ReadRequests  int64     `json:"readRequests"`
+	WriteRequests int64     `json:"writeRequests"`
+}
+
+// Requests read and write requests of the interval
+func (p UsagePoint) Requests() int64 {
+	return p.ReadRequests + p.WriteRequests
+}
+
+// Usage usage over time of a bucket or the whole account
+type Usage struct {
+	Bucket      string           `json:"bucket"`
+	Granularity UsageGranularity `json:"granularity"`
+	Points      []UsagePoint     `json:"points"`
+}
+
+// Totals egress, ingress and requests summed over all points, storage is the peak
+func (u *Usage) Totals() UsagePoint {
+	var total UsagePoint
+	for i, p := range u.Points {
+		if i == 0 {
+			total.Time = p.Time
+		}
+		if p.StorageBytes > total.StorageBytes {
+			total.StorageBytes = p.StorageBytes
+		}
+		total.EgressBytes += p.EgressBytes
+		total.IngressBytes += p.IngressBytes
+		total.ReadRequests += p.ReadRequests
+		total.WriteRequests += p.WriteRequests
+	}
+	return total
+}
+
+// GetUsage 查询存储量, 流量与请求数, 可用于内部成本分摊报表
+func (c *Client) GetUsage(query *UsageQuery) (_ *Usage, err error) {
+	ctx, op := c.startOperation("GetUsage", query.Bucket, "")
+	defer func() { c.endOperation(op, err) }()
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = UsageDaily
+	}
+	switch granularity {
+	case UsageHourly, UsageDaily, UsageMonthly:
+	default:
+		return nil, fmt.Errorf("unsupported usage granularity %q", granularity)
+	}
+	if !query.TimeRange.Start.IsZero() && !query.TimeRange.End.IsZero() && !query.TimeRange.Start.Before(query.TimeRange.End) {
+		return nil, fmt.Errorf("usage time range start must be before end")
+	}
+	q := url.Values{}
+	if query.Bucket != "" {
+		q.Set("bucket", query.Bucket)
+	}
+	q.Set("granularity", string(granularity))
+	if !query.TimeRange.Start.IsZero() {
+		q.Set("start", query.TimeRange.Start.UTC().Format(time.RFC3339))
+	}
+	if !query.TimeRange.End.IsZero() {
+		q.Set("end", query.TimeRange.End.UTC().Format(time.RFC3339))
+	}
+
+	var result Usage
+	if err := c.call(ctx, "GET", withQuery("/api/public/usage", q), nil, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Granularity == "" {
+		result.Granularity = granularity
+	}
+	return &result, nil
+}
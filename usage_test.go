@@ -0,0 +1,62 @@
+package lingstorage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsage(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/usage", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "logs", q.Get("bucket"))
+		assert.Equal(t, "day", q.Get("granularity"))
+		assert.Equal(t, "2024-05-01T00:00:00Z", q.Get("start"))
+		assert.Equal(t, "2024-05-03T00:00:00Z", q.Get("end"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{
+			"bucket": "logs",
+			"points": []map[string]interface{}{
+				{"time": start, "storageBytes": 100, "egressBytes": 10, "readRequests": 5, "writeRequests": 1},
+				{"time": start.AddDate(0, 0, 1), "storageBytes": 300, "egressBytes": 20, "readRequests": 7},
+			},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	usage, err := client.GetUsage(&UsageQuery{
+		Bucket:    "logs",
+		TimeRange: TimeRange{Start: start, End: start.AddDate(0, 0, 2)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, UsageDaily, usage.Granularity)
+	require.Len(t, usage.Points, 2)
+	assert.EqualValues(t, 6, usage.Points[0].Requests())
+
+	total := usage.Totals()
+	assert.Equal(t, start, total.Time)
+	assert.EqualValues(t, 300, total.StorageBytes)
+	assert.EqualValues(t, 30, total.EgressBytes)
+	assert.EqualValues(t, 13, total.Requests())
+}
+
+func TestGetUsageValidation(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "http://127.0.0.1:1", RetryCount: -1})
+	_, err := client.GetUsage(&UsageQuery{Granularity: "week"})
+	assert.ErrorContains(t, err, "granularity")
+
+	now := time.Now()
+	_, err = client.GetUsage(&UsageQuery{TimeRange: TimeRange{Start: now, End: now.Add(-time.Hour)}})
+	assert.ErrorContains(t, err, "before end")
+
+	r := LastDays(7)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), r.Start, time.Second)
+	assert.WithinDuration(t, time.Now(), r.End, time.Second)
+}
@@ -0,0 +1,308 @@
+// Package vcr records real LingStorage interactions to fixture files and
+// replays them, so tests of applications built on the SDK run without a server.
+//
+//	rec, err := vcr.New("testdata/upload.json", vcr.ModeAuto, nil)
+//	rec.FilterHeaders = []string{config.TokenHeader} // when a custom token header is set
+//	defer rec.Stop()
+//	client := lingstorage.NewClient(config, lingstorage.WithTransport(rec))
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode recorder mode
+type Mode int
+
+const (
+	// ModeAuto replay if the fixture exists, record otherwise
+	ModeAuto Mode = iota
+	// ModeRecord always forward to the server and overwrite the fixture
+	ModeRecord
+	// ModeReplay only replay, unmatched requests fail
+	ModeReplay
+)
+
+// ErrNoInteraction replayed request matches no recorded interaction
+var ErrNoInteraction = errors.New("vcr: no recorded interaction matches request")
+
+// filteredHeaders headers never written to fixtures: credentials and per-run values
+var filteredHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Api-Secret",
+	"X-Signature",
+	"X-Nonce",
+	"X-Timestamp",
+	"X-Server-Side-Encryption-Customer-Key",
+	"Traceparent",
+	"Tracestate",
+}
+
+// Request recorded request
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"` // path and query, host is not recorded
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"` // base64 for binary bodies
+}
+
+// Response recorded response
+type Response struct {
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// Interaction one recorded request and response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// cassette fixture file content
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Matcher report whether a replayed request matches a recorded one
+type Matcher func(req *http.Request, recorded Request) bool
+
+// DefaultMatcher match method, path and query. Bodies are not compared
+// because multipart boundaries change between runs
+func DefaultMatcher(req *http.Request, recorded Request) bool {
+	return req.Method == recorded.Method && req.URL.RequestURI() == recorded.URL
+}
+
+// Recorder http.RoundTripper recording or replaying interactions
+type Recorder struct {
+	// Matcher request matcher of replay mode, default DefaultMatcher
+	Matcher Matcher
+	// FilterHeaders extra headers never written to fixtures, e.g. a custom
+	// Config.TokenHeader carrying credentials
+	FilterHeaders []string
+
+	path      string
+	recording bool
+	real      http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New create recorder of the fixture at path. real is the transport used
+// when recording, default http.DefaultTransport
+func New(path string, mode Mode, real http.RoundTripper) (*Recorder, error) {
+	if real == nil {
+		real = http.DefaultTransport
+	}
+	r := &Recorder{path: path, real: real, Matcher: DefaultMatcher}
+
+	if mode == ModeRecord {
+		r.recording = true
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeAuto {
+		r.recording = true
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	r.interactions = c.Interactions
+	r.used = make([]bool, len(c.Interactions))
+	return r, nil
+}
+
+// Recording whether the recorder forwards requests to the server
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.recording {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, out, err := requestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	resp, err := r.real.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: r.filterHeader(req.Header),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.filterHeader(resp.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(reqBody)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(respBody)
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || !r.Matcher(req, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		body, err := decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded body: %w", err)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+}
+
+// Stop write the fixture when recording, no-op in replay mode
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// requestBody body of req and the request to send in its place, without
+// modifying req as RoundTrippers must not: the body is read through GetBody
+// when possible, else it is buffered and sent on a clone of req
+func requestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			req.Body.Close()
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			req.Body.Close()
+			return nil, nil, err
+		}
+		return data, req, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(data))
+	return data, out, nil
+}
+
+// readBody read body and replace it with a re-readable copy
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func (r *Recorder) filterHeader(header http.Header) http.Header {
+	filtered := header.Clone()
+	for _, name := range filteredHeaders {
+		filtered.Del(name)
+	}
+	for _, name := range r.FilterHeaders {
+		filtered.Del(name)
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+func encodeBody(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return base64.StdEncoding.EncodeToString(data), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,124 @@
+package vcr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixtures", "download.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Meta-Author", "ling")
+		w.Write([]byte("recorded content"))
+	}))
+
+	// 录制
+	rec, err := New(fixture, ModeAuto, nil)
+	require.NoError(t, err)
+	require.True(t, rec.Recording())
+	client := lingstorage.NewClient(&lingstorage.Config{BaseURL: server.URL, APIKey: "key-123456", APISecret: "secret-abcdef"},
+		lingstorage.WithTransport(rec))
+	result, err := client.Download(&lingstorage.DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(result.Body)
+	result.Body.Close()
+	assert.Equal(t, "recorded content", string(data))
+	require.NoError(t, rec.Stop())
+	server.Close()
+
+	raw, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "key-123456")
+	assert.NotContains(t, string(raw), "secret-abcdef")
+
+	// 回放，无需服务端
+	rec, err = New(fixture, ModeAuto, nil)
+	require.NoError(t, err)
+	require.False(t, rec.Recording())
+	client = lingstorage.NewClient(&lingstorage.Config{BaseURL: "http://replay.invalid", APIKey: "key", RetryCount: -1},
+		lingstorage.WithTransport(rec))
+	result, err = client.Download(&lingstorage.DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	require.NoError(t, err)
+	data, _ = io.ReadAll(result.Body)
+	assert.Equal(t, "recorded content", string(data))
+	assert.Equal(t, "ling", result.Metadata["author"])
+
+	// 每条记录只回放一次
+	_, err = client.Download(&lingstorage.DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+	assert.Error(t, err)
+}
+
+func TestBinaryBodyRoundTrip(t *testing.T) {
+	body, encoding := encodeBody([]byte{0xff, 0x00, 0xfe})
+	assert.Equal(t, "base64", encoding)
+	data, err := decodeBody(body, encoding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0x00, 0xfe}, data)
+}
+
+func TestRecordLeavesRequestUnmodified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Write(data)
+	}))
+	defer server.Close()
+	rec, err := New(filepath.Join(t.TempDir(), "echo.json"), ModeRecord, nil)
+	require.NoError(t, err)
+
+	// 带 GetBody 的请求与只能读一次的请求体, RoundTrip 都不能替换 req.Body
+	replayable, err := http.NewRequest("POST", server.URL+"/a", strings.NewReader("first"))
+	require.NoError(t, err)
+	once, err := http.NewRequest("POST", server.URL+"/b", nil)
+	require.NoError(t, err)
+	once.Body = io.NopCloser(strings.NewReader("second"))
+	for _, req := range []*http.Request{replayable, once} {
+		body := req.Body
+		resp, err := rec.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.True(t, body == req.Body, req.URL.Path)
+	}
+	require.Len(t, rec.interactions, 2)
+	assert.Equal(t, "first", rec.interactions[0].Request.Body)
+	assert.Equal(t, "second", rec.interactions[1].Request.Body)
+	assert.Equal(t, "second", rec.interactions[1].Response.Body)
+}
+
+func TestRecordFiltersTokenHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+	fixture := filepath.Join(t.TempDir(), "token.json")
+	config := &lingstorage.Config{BaseURL: server.URL, TokenSource: lingstorage.StaticTokenSource("token-abcdef"), TokenHeader: "X-Access-Token"}
+
+	// 自定义令牌头不会写入夹具
+	rec, err := New(fixture, ModeRecord, nil)
+	require.NoError(t, err)
+	rec.FilterHeaders = []string{config.TokenHeader}
+	client := lingstorage.NewClient(config, lingstorage.WithTransport(rec))
+	result, err := client.Download(&lingstorage.DownloadRequest{Bucket: "bkt", Key: "a.txt"})
+	require.NoError(t, err)
+	result.Body.Close()
+	require.NoError(t, rec.Stop())
+
+	raw, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "token-abcdef")
+	assert.NotContains(t, string(raw), "X-Access-Token")
+}
@@ -0,0 +1,98 @@
+package lingstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TranscodeSpec video transcode target
+type TranscodeSpec struct {
+	Format     string `json:"format"`               // mp4, webm, hls ...
+	Resolution string `json:"resolution,omitempty"` // e.g. 1280x720 or 720p, default source resolution
+	Bitrate    int    `json:"bitrate,omitempty"`    // video bitrate in kbps, default chosen by the server
+	OutputKey  string `json:"outputKey,omitempty"`  // output key, default chosen by the server
+}
+
+// TranscodeJob asynchronous transcode job
+type TranscodeJob struct {
+	ID        string    `json:"id"`
+	Status    JobStatus `json:"status"`
+	Progress  int       `json:"progress"` // 0-100
+	Bucket    string    `json:"bucket"`
+	SourceKey string    `json:"sourceKey"`
+	OutputKey string    `json:"outputKey"`
+	OutputURL string    `json:"outputUrl"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// VideoSnapshot frame captured from a video
+type VideoSnapshot struct {
+	Key    string  `json:"key"`
+	URL    string  `json:"url"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	At     float64 `json:"at"` // capture position in seconds
+}
+
+// CreateTranscodeJob start a server side transcode of a video, poll it with WaitForTranscodeJob
+func (c *Client) CreateTranscodeJob(bucket, key string, spec *TranscodeSpec) (_ *TranscodeJob, err error) {
+	ctx, op := c.startOperation("CreateTranscodeJob", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if spec == nil {
+		return nil, fmt.Errorf("transcode spec is required")
+	}
+	if spec.Format == "" {
+		return nil, fmt.Errorf("transcode format is required")
+	}
+	if spec.Bitrate < 0 {
+		return nil, fmt.Errorf("invalid bitrate %d", spec.Bitrate)
+	}
+	var result TranscodeJob
+	if err := c.call(ctx, "POST", objectPath(bucket, key)+"/transcode", spec, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTranscodeJob get the current state of a transcode job
+func (c *Client) GetTranscodeJob(jobID string) (_ *TranscodeJob, err error) {
+	ctx, op := c.startOperation("GetTranscodeJob", "", jobID)
+	defer func() { c.endOperation(op, err) }()
+	var result TranscodeJob
+	if err := c.fetchJob(ctx, jobID, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WaitForTranscodeJob poll a transcode job until it is done or ctx ends.
+// A failed or canceled job is returned together with a *JobError
+func (c *Client) WaitForTranscodeJob(ctx context.Context, jobID string, pollInterval time.Duration) (*TranscodeJob, error) {
+	var job *TranscodeJob
+	err := pollJob(ctx, jobID, pollInterval, func() (JobStatus, string, error) {
+		var err error
+		job, err = c.WithContext(ctx).GetTranscodeJob(jobID)
+		if err != nil {
+			return "", "", err
+		}
+		return job.Status, job.Error, nil
+	})
+	return job, err
+}
+
+// GetVideoSnapshot capture the frame at atSeconds as an image object
+func (c *Client) GetVideoSnapshot(bucket, key string, atSeconds float64) (_ *VideoSnapshot, err error) {
+	ctx, op := c.startOperation("GetVideoSnapshot", bucket, key)
+	defer func() { c.endOperation(op, err) }()
+	if atSeconds < 0 {
+		return nil, fmt.Errorf("invalid snapshot position %g", atSeconds)
+	}
+	var result VideoSnapshot
+	if err := c.call(ctx, "POST", objectPath(bucket, key)+"/snapshot", map[string]float64{"at": atSeconds}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
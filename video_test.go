@@ -0,0 +1,91 @@
+package lingstorage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscodeJob(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/files/videos/a.mov/transcode":
+			var spec TranscodeSpec
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&spec))
+			assert.Equal(t, "mp4", spec.Format)
+			assert.Equal(t, "720p", spec.Resolution)
+			assert.Equal(t, 2500, spec.Bitrate)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1", "status": "pending"}})
+		case "/api/public/jobs/job-1":
+			polls++
+			status := "running"
+			if polls == 3 {
+				status = "succeeded"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1", "status": status, "outputKey": "a.mp4"}})
+		case "/api/public/jobs/job-2":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-2", "status": "failed", "error": "unsupported codec"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	job, err := client.CreateTranscodeJob("videos", "a.mov", &TranscodeSpec{Format: "mp4", Resolution: "720p", Bitrate: 2500})
+	require.NoError(t, err)
+	assert.Equal(t, JobPending, job.Status)
+
+	job, err = client.WaitForTranscodeJob(context.Background(), job.ID, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, JobSucceeded, job.Status)
+	assert.Equal(t, "a.mp4", job.OutputKey)
+	assert.Equal(t, 3, polls)
+
+	job, err = client.WaitForTranscodeJob(context.Background(), "job-2", time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported codec")
+	assert.Equal(t, JobFailed, job.Status)
+
+	_, err = client.CreateTranscodeJob("videos", "a.mov", &TranscodeSpec{})
+	assert.Error(t, err)
+	_, err = client.CreateTranscodeJob("videos", "a.mov", nil)
+	assert.ErrorContains(t, err, "transcode spec is required")
+}
+
+func TestWaitForTranscodeJobCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"id": "job-1", "status": "running"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", RetryCount: -1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitForTranscodeJob(ctx, "job-1", 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGetVideoSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/public/files/videos/a.mp4/snapshot", r.URL.Path)
+		var body map[string]float64
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, 12.5, body["at"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"key": "a_12.5.jpg", "width": 1280, "height": 720, "at": 12.5}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key"})
+	snapshot, err := client.GetVideoSnapshot("videos", "a.mp4", 12.5)
+	require.NoError(t, err)
+	assert.Equal(t, "a_12.5.jpg", snapshot.Key)
+	assert.Equal(t, 1280, snapshot.Width)
+}
@@ -0,0 +1,77 @@
+package lingstorage
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// SkipDir returned by a WalkFunc for a directory, its contents are not walked
+var SkipDir = errors.New("lingstorage: skip this directory")
+
+// WalkEntry object or virtual directory met by WalkFiles
+type WalkEntry struct {
+	Key   string    // object key, or the directory prefix ending with "/"
+	IsDir bool      // virtual directory, File is nil
+	File  *FileInfo // object info, nil for directories
+}
+
+// WalkFunc called by WalkFiles per entry. Returning SkipDir for a directory
+// skips it, for an object skips the rest of its directory; any other error
+// stops the walk and is returned by WalkFiles
+type WalkFunc func(entry WalkEntry) error
+
+// WalkFiles 以 "/" 为分隔符深度优先遍历前缀下的虚拟目录树,
+// 每个对象和目录调用一次 fn, 同一层级按键名排序, 目录在其内容之前
+func (c *Client) WalkFiles(bucket, prefix string, fn WalkFunc) error {
+	err := c.walkDir(bucket, prefix, fn)
+	if errors.Is(err, SkipDir) {
+		return nil
+	}
+	return err
+}
+
+// walkDir walk the entries directly under prefix, descending into directories
+func (c *Client) walkDir(bucket, prefix string, fn WalkFunc) error {
+	req := &ListFilesRequest{Bucket: bucket, Prefix: prefix, Delimiter: "/"}
+	for {
+		page, err := c.ListFiles(req)
+		if err != nil {
+			return err
+		}
+		entries := make([]WalkEntry, 0, len(page.Files)+len(page.Directories))
+		for i := range page.Files {
+			entries = append(entries, WalkEntry{Key: page.Files[i].Key, File: &page.Files[i]})
+		}
+		for _, dir := range page.Directories {
+			if !strings.HasSuffix(dir, "/") {
+				dir += "/"
+			}
+			// some servers list the prefix itself as a directory
+			if dir == prefix {
+				continue
+			}
+			entries = append(entries, WalkEntry{Key: dir, IsDir: true})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+		for _, entry := range entries {
+			err := fn(entry)
+			if entry.IsDir {
+				if err == nil {
+					err = c.walkDir(bucket, entry.Key, fn)
+				}
+				if errors.Is(err, SkipDir) {
+					continue
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if !page.IsTruncated || page.NextMarker == "" || page.NextMarker == req.Marker {
+			return nil
+		}
+		req.Marker = page.NextMarker
+	}
+}
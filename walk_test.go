@@ -0,0 +1,65 @@
+package lingstorage_test
+
+import (
+	"errors"
+	"testing"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkFiles(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	bucket := lingstoragetest.DefaultBucket
+	for _, key := range []string{"docs/a.md", "docs/img/1.png", "docs/img/2.png", "docs/tmp/x", "docs/z.md", "other.txt"} {
+		server.PutObject(bucket, key, []byte(key))
+	}
+
+	var visited []string
+	require.NoError(t, client.WalkFiles(bucket, "docs/", func(entry lingstorage.WalkEntry) error {
+		visited = append(visited, entry.Key)
+		if entry.IsDir {
+			assert.Nil(t, entry.File)
+		} else {
+			assert.Equal(t, int64(len(entry.Key)), entry.File.Size)
+		}
+		if entry.Key == "docs/tmp/" {
+			return lingstorage.SkipDir
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"docs/a.md", "docs/img/", "docs/img/1.png", "docs/img/2.png", "docs/tmp/", "docs/z.md"}, visited)
+
+	// 对象返回 SkipDir 跳过所在目录的剩余条目
+	visited = nil
+	require.NoError(t, client.WalkFiles(bucket, "", func(entry lingstorage.WalkEntry) error {
+		visited = append(visited, entry.Key)
+		if entry.Key == "docs/img/1.png" {
+			return lingstorage.SkipDir
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"docs/", "docs/a.md", "docs/img/", "docs/img/1.png", "docs/tmp/", "docs/tmp/x", "docs/z.md", "other.txt"}, visited)
+
+	stop := errors.New("stop")
+	err := client.WalkFiles(bucket, "", func(entry lingstorage.WalkEntry) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestListDirectoriesOnly(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+	for _, key := range []string{"a/1", "b/2", "c.txt"} {
+		server.PutObject(lingstoragetest.DefaultBucket, key, []byte(key))
+	}
+
+	result, err := client.ListFiles(&lingstorage.ListFilesRequest{Bucket: lingstoragetest.DefaultBucket, DirectoriesOnly: true})
+	require.NoError(t, err)
+	assert.Empty(t, result.Files)
+	assert.Equal(t, []string{"a/", "b/"}, result.Directories)
+}
@@ -0,0 +1,375 @@
+// Package watch keeps local directories mirrored to a bucket by uploading
+// files as they are created or modified.
+//
+//	w, err := watch.New(client, watch.Options{Bucket: "logs", PendingFile: "/var/lib/app/pending.json"})
+//	w.Add("/var/log/app")
+//	err = w.Run(ctx)
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// DefaultDebounce quiet period after the last write before a file is uploaded
+	DefaultDebounce = 500 * time.Millisecond
+	// DefaultMaxAttempts upload attempts of a file before it is reported failed
+	DefaultMaxAttempts = 5
+	// DefaultBackoff delay before the first retry, doubled on each retry
+	DefaultBackoff = time.Second
+	// DefaultRetryInterval delay before a file whose attempts all failed is tried again
+	DefaultRetryInterval = time.Minute
+)
+
+// Options watcher options
+type Options struct {
+	Bucket      string
+	Prefix      string        // remote key prefix, keys are Prefix + path relative to the watched root
+	Debounce    time.Duration // default 500ms
+	MaxAttempts int           // default 5
+	Backoff     time.Duration // default 1s
+	// RetryInterval delay before a file whose attempts all failed is queued
+	// again, default 1m. It stays pending until it is uploaded
+	RetryInterval time.Duration
+	// PendingFile persists files waiting for upload, so they are uploaded
+	// after a restart. Empty keeps the queue in memory only
+	PendingFile string
+	// OnUpload called after each file finished, Err is set if all attempts failed
+	OnUpload func(result Result)
+}
+
+// Result outcome of one file upload
+type Result struct {
+	Path     string
+	Key      string
+	Attempts int
+	Err      error
+}
+
+// Watcher uploads files of watched directories on create and modify
+type Watcher struct {
+	client  *lingstorage.Client
+	opts    Options
+	fsw     *fsnotify.Watcher
+	mu      sync.Mutex
+	roots   map[string]string      // watched directory -> root it was added with
+	timers  map[string]*time.Timer // debounce timers by path
+	pending map[string]string      // path -> key, waiting for upload
+	gens    map[string]uint64      // path -> count of changes queued, tells stale uploads apart
+	ready   chan string
+	done    chan struct{} // closed once Run returned or the watcher is closed
+	stop    sync.Once
+}
+
+// New create a watcher, pending uploads of PendingFile are loaded and uploaded once Run starts
+func New(client *lingstorage.Client, opts Options) (*Watcher, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = DefaultRetryInterval
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	w := &Watcher{
+		client:  client,
+		opts:    opts,
+		fsw:     fsw,
+		roots:   make(map[string]string),
+		timers:  make(map[string]*time.Timer),
+		pending: make(map[string]string),
+		gens:    make(map[string]uint64),
+		ready:   make(chan string, 1024),
+		done:    make(chan struct{}),
+	}
+	if err := w.loadPending(); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Add watch a directory and its subdirectories, directories created later are watched too
+func (w *Watcher) Add(root string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	return w.addTree(root, root)
+}
+
+func (w *Watcher) addTree(root, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := w.fsw.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+		w.mu.Lock()
+		w.roots[p] = root
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+// Run process filesystem events and uploads until ctx ends or the watcher is closed
+func (w *Watcher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	defer w.shutdown()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.uploadLoop(ctx)
+	}()
+
+	// uploads left over from a previous run
+	for p := range w.Pending() {
+		select {
+		case w.ready <- p:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			w.handle(event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			if w.opts.OnUpload != nil {
+				w.opts.OnUpload(Result{Err: fmt.Errorf("watch error: %w", err)})
+			}
+		}
+	}
+}
+
+// Close stop watching, Run returns
+func (w *Watcher) Close() error {
+	w.shutdown()
+	w.mu.Lock()
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+	w.mu.Unlock()
+	return w.fsw.Close()
+}
+
+// shutdown release timers waiting to queue a file, nothing reads ready anymore
+func (w *Watcher) shutdown() {
+	w.stop.Do(func() { close(w.done) })
+}
+
+// enqueue hand p to the upload loop, dropped once the watcher stopped
+func (w *Watcher) enqueue(p string) {
+	select {
+	case w.ready <- p:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) handle(event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+	info, err := os.Lstat(event.Name)
+	if err != nil {
+		return
+	}
+	if info.IsDir() {
+		if event.Has(fsnotify.Create) {
+			w.mu.Lock()
+			root := w.roots[filepath.Dir(event.Name)]
+			w.mu.Unlock()
+			if root != "" {
+				w.addTree(root, event.Name)
+				// files created before the watch was in place
+				filepath.WalkDir(event.Name, func(p string, d fs.DirEntry, err error) error {
+					if err == nil && d.Type().IsRegular() {
+						w.schedule(p)
+					}
+					return nil
+				})
+			}
+		}
+		return
+	}
+	if info.Mode().IsRegular() {
+		w.schedule(event.Name)
+	}
+}
+
+// schedule upload p once no event arrived for the debounce period
+func (w *Watcher) schedule(p string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if timer, ok := w.timers[p]; ok {
+		timer.Reset(w.opts.Debounce)
+		return
+	}
+	w.timers[p] = time.AfterFunc(w.opts.Debounce, func() {
+		w.mu.Lock()
+		delete(w.timers, p)
+		key, ok := w.keyOf(p)
+		if ok {
+			w.pending[p] = key
+			w.gens[p]++
+			w.savePending()
+		}
+		w.mu.Unlock()
+		if ok {
+			w.enqueue(p)
+		}
+	})
+}
+
+// keyOf remote key of p, caller holds mu
+func (w *Watcher) keyOf(p string) (string, bool) {
+	root, ok := w.roots[filepath.Dir(p)]
+	if !ok {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", false
+	}
+	prefix := strings.Trim(w.opts.Prefix, "/")
+	return strings.TrimPrefix(path.Join(prefix, filepath.ToSlash(rel)), "/"), true
+}
+
+func (w *Watcher) uploadLoop(ctx context.Context) {
+	client := w.client.WithContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-w.ready:
+			w.mu.Lock()
+			key, ok := w.pending[p]
+			gen := w.gens[p]
+			w.mu.Unlock()
+			if !ok {
+				continue
+			}
+			result := w.upload(ctx, client, p, key)
+			if errors.Is(result.Err, context.Canceled) {
+				return
+			}
+			w.mu.Lock()
+			switch {
+			case w.gens[p] != gen:
+				// changed during the upload, the newer change is queued already
+			case result.Err == nil || errors.Is(result.Err, os.ErrNotExist):
+				delete(w.pending, p)
+				delete(w.gens, p)
+				w.savePending()
+			default:
+				// keep failed uploads pending and try again later
+				time.AfterFunc(w.opts.RetryInterval, func() { w.enqueue(p) })
+			}
+			w.mu.Unlock()
+			if w.opts.OnUpload != nil {
+				w.opts.OnUpload(result)
+			}
+		}
+	}
+}
+
+func (w *Watcher) upload(ctx context.Context, client *lingstorage.Client, p, key string) Result {
+	result := Result{Path: p, Key: key}
+	backoff := w.opts.Backoff
+	for result.Attempts < w.opts.MaxAttempts {
+		result.Attempts++
+		_, result.Err = client.UploadFile(&lingstorage.UploadRequest{FilePath: p, Bucket: w.opts.Bucket, Key: key})
+		if result.Err == nil || errors.Is(result.Err, os.ErrNotExist) || result.Attempts == w.opts.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			return result
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return result
+}
+
+// Pending files waiting for upload, by path
+func (w *Watcher) Pending() map[string]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make(map[string]string, len(w.pending))
+	for p, key := range w.pending {
+		out[p] = key
+	}
+	return out
+}
+
+func (w *Watcher) loadPending() error {
+	if w.opts.PendingFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(w.opts.PendingFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read pending file: %w", err)
+	}
+	if err := json.Unmarshal(data, &w.pending); err != nil {
+		return fmt.Errorf("failed to parse pending file: %w", err)
+	}
+	return nil
+}
+
+// savePending persist the pending uploads, best effort, caller holds mu
+func (w *Watcher) savePending() {
+	if w.opts.PendingFile == "" {
+		return
+	}
+	data, err := json.Marshal(w.pending)
+	if err != nil {
+		return
+	}
+	tmp := w.opts.PendingFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, w.opts.PendingFile)
+}
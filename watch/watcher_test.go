@@ -0,0 +1,239 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	lingstorage "github.com/LingByte/lingstorage-sdk-go"
+	"github.com/LingByte/lingstorage-sdk-go/lingstoragetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherUploadsChanges(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	results := make(chan Result, 10)
+	w, err := New(server.Client(), Options{
+		Bucket:      lingstoragetest.DefaultBucket,
+		Prefix:      "logs",
+		Debounce:    20 * time.Millisecond,
+		PendingFile: filepath.Join(t.TempDir(), "pending.json"),
+		OnUpload:    func(r Result) { results <- r },
+	})
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Add(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// 多次写入只上传一次
+	p := filepath.Join(dir, "app.log")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(p, []byte("line"), 0644))
+	}
+	result := waitResult(t, results)
+	require.NoError(t, result.Err)
+	assert.Equal(t, "logs/app.log", result.Key)
+
+	// 新建子目录中的文件
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "2024"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2024", "old.log"), []byte("old"), 0644))
+	result = waitResult(t, results)
+	require.NoError(t, result.Err)
+	assert.Equal(t, "logs/2024/old.log", result.Key)
+
+	_, ok := server.Object(lingstoragetest.DefaultBucket, "logs/2024/old.log")
+	assert.True(t, ok)
+	assert.Empty(t, w.Pending())
+}
+
+func TestWatcherResumesPending(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "left.txt")
+	require.NoError(t, os.WriteFile(p, []byte("left over"), 0644))
+	pendingFile := filepath.Join(t.TempDir(), "pending.json")
+	data, _ := json.Marshal(map[string]string{p: "left.txt"})
+	require.NoError(t, os.WriteFile(pendingFile, data, 0644))
+
+	results := make(chan Result, 10)
+	w, err := New(server.Client(), Options{Bucket: lingstoragetest.DefaultBucket, PendingFile: pendingFile, OnUpload: func(r Result) { results <- r }})
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	result := waitResult(t, results)
+	require.NoError(t, result.Err)
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "left.txt")
+	require.True(t, ok)
+	assert.Equal(t, "left over", string(obj.Data))
+
+	data, err = os.ReadFile(pendingFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, "{}", string(data))
+}
+
+func TestWatcherReportsFailures(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	results := make(chan Result, 10)
+	w, err := New(server.Client(), Options{
+		Bucket:      "missing-bucket",
+		Debounce:    10 * time.Millisecond,
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+		// 保证断言时还未重试
+		RetryInterval: time.Hour,
+		OnUpload:      func(r Result) { results <- r },
+	})
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Add(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	result := waitResult(t, results)
+	assert.Error(t, result.Err)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Len(t, w.Pending(), 1, "failed uploads stay pending")
+}
+
+func TestWatcherRetriesFailures(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	results := make(chan Result, 10)
+	w, err := New(server.Client(), Options{
+		Bucket:        "later-bucket",
+		Debounce:      10 * time.Millisecond,
+		MaxAttempts:   1,
+		RetryInterval: 50 * time.Millisecond,
+		OnUpload:      func(r Result) { results <- r },
+	})
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Add(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	result := waitResult(t, results)
+	require.Error(t, result.Err)
+
+	// 存储桶创建后无需重启即可重试成功
+	server.CreateBucket("later-bucket")
+	result = waitResult(t, results)
+	require.NoError(t, result.Err)
+	_, ok := server.Object("later-bucket", "a.txt")
+	assert.True(t, ok)
+	assert.Eventually(t, func() bool { return len(w.Pending()) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestWatcherKeepsChangesDuringUpload(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client := server.Client(lingstorage.WithPreUploadHook(func(*lingstorage.UploadRequest, io.Reader) error {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+		return nil
+	}))
+
+	dir := t.TempDir()
+	results := make(chan Result, 10)
+	w, err := New(client, Options{
+		Bucket:   lingstoragetest.DefaultBucket,
+		Debounce: 10 * time.Millisecond,
+		OnUpload: func(r Result) { results <- r },
+	})
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Add(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	p := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(p, []byte("v1"), 0644))
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for upload")
+	}
+
+	// 上传过程中的修改不会丢失
+	require.NoError(t, os.WriteFile(p, []byte("v2"), 0644))
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	require.NoError(t, waitResult(t, results).Err)
+	require.NoError(t, waitResult(t, results).Err)
+	obj, ok := server.Object(lingstoragetest.DefaultBucket, "a.txt")
+	require.True(t, ok)
+	assert.Equal(t, "v2", string(obj.Data))
+	assert.Eventually(t, func() bool { return len(w.Pending()) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestWatcherEnqueueAfterClose(t *testing.T) {
+	server := lingstoragetest.NewFakeServer()
+	defer server.Close()
+
+	w, err := New(server.Client(), Options{Bucket: lingstoragetest.DefaultBucket})
+	require.NoError(t, err)
+	for i := 0; i < cap(w.ready); i++ {
+		w.ready <- "full"
+	}
+	require.NoError(t, w.Close())
+
+	// 队列已满且无人消费时不会阻塞
+	done := make(chan struct{})
+	go func() {
+		w.enqueue("late")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueue blocked after Close")
+	}
+}
+
+func waitResult(t *testing.T, results chan Result) Result {
+	t.Helper()
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for upload")
+		return Result{}
+	}
+}